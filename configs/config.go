@@ -1,14 +1,23 @@
 package configs
 
+import "time"
+
 type AppConfiguration struct {
 	Mode       string `env:"GIN_MODE"`
 	Port       int    `env:"PORT"`
 	AppEnv     string `env:"APP_ENV"`
 	Version    string `env:"VERSION"`
+	Identity   IdentityConfig
 	Database   DBConfig
 	AwsConf    AwsConfiguration
 	GoogleAuth GoogleAuthConfig
 	Cache      CacheConfig
+	Throttle   ThrottleConfig
+	RateLimit  RateLimitConfig
+	Storage    StorageConfig
+	Order      OrderConfig
+	Import     ImportConfig
+	Trash      TrashConfig
 	Swag       SwagConf
 }
 
@@ -23,6 +32,16 @@ type DBConfig struct {
 	MigrationsPath string `env:"DB_MIGRATIONS_PATH,default=db/migrations"`
 }
 
+// IdentityConfig selects the identity provider, "cognito" or "memory" for
+// running the API locally without AWS.
+type IdentityConfig struct {
+	Provider string        `env:"IDENTITY_PROVIDER,default=cognito"`
+	TokenTTL time.Duration `env:"IDENTITY_TOKEN_TTL,default=1h"`
+	// Admins are email:password pairs the memory provider starts with in
+	// the admin group
+	Admins []string `env:"IDENTITY_ADMINS"`
+}
+
 type SwagConf struct {
 	Host string `env:"SWAG_HOST,default=localhost"`
 }
@@ -31,6 +50,77 @@ type CacheConfig struct {
 	EndPoint string `env:"CACHE_ENDPOINT"`
 	Port     string `env:"CACHE_PORT"`
 	Password string `env:"CACHE_PASS"`
+	// SessionTTL should match the refresh token validity of the app client
+	SessionTTL time.Duration `env:"SESSION_TTL,default=720h"`
+}
+
+// ThrottleConfig limits failed logins and code requests per email and per
+// client IP. Each failure doubles the wait before the next attempt, starting
+// at BackoffBase, and MaxFailures within FailureWindow lock the email.
+type ThrottleConfig struct {
+	MaxFailures   int64         `env:"THROTTLE_MAX_FAILURES,default=5"`
+	IPMaxFailures int64         `env:"THROTTLE_IP_MAX_FAILURES,default=20"`
+	FailureWindow time.Duration `env:"THROTTLE_FAILURE_WINDOW,default=15m"`
+	BackoffBase   time.Duration `env:"THROTTLE_BACKOFF_BASE,default=1s"`
+	BackoffMax    time.Duration `env:"THROTTLE_BACKOFF_MAX,default=1m"`
+	Lockout       time.Duration `env:"THROTTLE_LOCKOUT,default=15m"`
+}
+
+// RateLimitConfig holds the requests allowed per Window for each route group,
+// keyed by role. Users without a known role get the "default" quota.
+type RateLimitConfig struct {
+	Window       time.Duration  `env:"RATE_LIMIT_WINDOW,default=1m"`
+	Auth         map[string]int `env:"RATE_LIMIT_AUTH,default=default:30"`
+	Products     map[string]int `env:"RATE_LIMIT_PRODUCTS,default=default:60,retail:120,grosir:300,admin:600"`
+	Profile      map[string]int `env:"RATE_LIMIT_PROFILE,default=default:30"`
+	Groceries    map[string]int `env:"RATE_LIMIT_GROCERIES,default=default:30,retail:60,grosir:300,admin:600"`
+	Transactions map[string]int `env:"RATE_LIMIT_TRANSACTIONS,default=default:30,retail:120,grosir:120,admin:600"`
+}
+
+// StorageConfig selects where uploaded files go, "s3" or "local" for
+// development. PublicURL is the base URL objects are served from, the bucket
+// URL or a CDN for s3 and the /media route of this API for local.
+type StorageConfig struct {
+	Driver         string `env:"STORAGE_DRIVER,default=s3"`
+	Bucket         string `env:"STORAGE_BUCKET,default=payuoge-media"`
+	PublicURL      string `env:"STORAGE_PUBLIC_URL"`
+	LocalDir       string `env:"STORAGE_LOCAL_DIR,default=uploads"`
+	MaxImageSize   int64  `env:"STORAGE_MAX_IMAGE_SIZE,default=5242880"`
+	MaxImages      int    `env:"STORAGE_MAX_IMAGES,default=10"`
+	ThumbnailWidth int    `env:"STORAGE_THUMBNAIL_WIDTH,default=320"`
+	// UploadTTL is how long a presigned upload URL stays valid
+	UploadTTL time.Duration `env:"STORAGE_UPLOAD_TTL,default=15m"`
+	// DownloadTTL is how long a presigned URL of a private object, such as
+	// a payment proof, stays valid
+	DownloadTTL time.Duration `env:"STORAGE_DOWNLOAD_TTL,default=5m"`
+	// LocalUploadURL receives the uploads signed by the local driver with
+	// SigningKey, a random key is used when empty
+	LocalUploadURL string `env:"STORAGE_LOCAL_UPLOAD_URL,default=/v1/uploads/local"`
+	SigningKey     string `env:"STORAGE_SIGNING_KEY"`
+}
+
+// OrderConfig sets how long a placed order holds its stock before the sweeper
+// releases it, the sweeper looks for expired orders every SweepInterval and
+// handles up to SweepBatch of them at a time.
+type OrderConfig struct {
+	ReservationTTL time.Duration `env:"ORDER_RESERVATION_TTL,default=24h"`
+	SweepInterval  time.Duration `env:"ORDER_SWEEP_INTERVAL,default=1m"`
+	SweepBatch     int           `env:"ORDER_SWEEP_BATCH,default=100"`
+}
+
+// ImportConfig limits the files of a product import.
+type ImportConfig struct {
+	MaxFileSize int64 `env:"IMPORT_MAX_FILE_SIZE,default=10485760"`
+	MaxRows     int   `env:"IMPORT_MAX_ROWS,default=5000"`
+}
+
+// TrashConfig sets how long deleted products, categories and size types stay
+// restorable, the purge looks for older ones every PurgeInterval and removes
+// up to PurgeBatch of each kind at a time.
+type TrashConfig struct {
+	Retention     time.Duration `env:"TRASH_RETENTION,default=720h"`
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL,default=1h"`
+	PurgeBatch    int           `env:"TRASH_PURGE_BATCH,default=100"`
 }
 
 type AwsConfiguration struct {
@@ -39,10 +129,19 @@ type AwsConfiguration struct {
 	ClientId     string `env:"CLIENT_ID"`
 	ClientSecret string `env:"CLIENT_SECRET"`
 	UserPoolId   string `env:"USER_POOL_ID"`
+	// JwksPath points to a local JWKS file used instead of the user pool endpoint
+	JwksPath    string        `env:"COGNITO_JWKS_PATH"`
+	JwksRefresh time.Duration `env:"COGNITO_JWKS_REFRESH,default=1h"`
 }
 
+// GoogleAuthConfig configures the hosted UI login, Domain may point to a
+// local fake OAuth server.
 type GoogleAuthConfig struct {
-	ClientID     string `env:"CLIENT_ID_GOOGLE"`
-	ClientSecret string `env:"CLIENT_SECRET_GOOGLE"`
-	RedirectURL  string `env:"REDIRECT_URL_GOOGLE"`
+	Domain           string   `env:"OAUTH_DOMAIN,default=https://auth.payuoge.com"`
+	IdentityProvider string   `env:"OAUTH_IDENTITY_PROVIDER,default=Google"`
+	Scopes           []string `env:"OAUTH_SCOPES,default=openid,email,aws.cognito.signin.user.admin"`
+	RedirectURL      string   `env:"REDIRECT_URL_GOOGLE,default=http://localhost:4001/v1/auth/callback"`
+	PostLoginURL     string   `env:"POST_LOGIN_URL,default=https://payuoge.com"`
+	// StateTTL is how long a started login may take to come back
+	StateTTL time.Duration `env:"OAUTH_STATE_TTL,default=10m"`
 }
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS profiles (
+	id bigserial primary key,
+	user_id varchar(255) unique not null,
+	email varchar(255) not null default '',
+	display_name varchar(255) not null default '',
+	phone varchar(50) not null default '',
+	business_name varchar(255) not null default '',
+	store_name varchar(255) not null default '',
+	avatar text not null default '',
+	default_address text not null default '',
+	created_at bigint not null,
+	updated_at bigint not null
+);
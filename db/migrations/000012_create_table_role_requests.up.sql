@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS role_requests (
+	id bigserial primary key,
+	user_id varchar(255) not null,
+	role varchar(50) not null,
+	reason text not null default '',
+	status varchar(20) not null default 'pending',
+	reviewer varchar(255) not null default '',
+	review_note text not null default '',
+	created_at bigint not null,
+	reviewed_at bigint
+);
+
+-- one open request per user and role
+CREATE UNIQUE INDEX IF NOT EXISTS role_requests_pending_idx
+ON role_requests(user_id, role) WHERE status = 'pending';
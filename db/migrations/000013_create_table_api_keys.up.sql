@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id bigserial primary key,
+	owner_id varchar(255) not null,
+	name varchar(255) not null,
+	prefix varchar(32) not null,
+	key_hash char(64) unique not null,
+	scopes text[] not null,
+	expires_at bigint,
+	last_used_at bigint,
+	revoked_at bigint,
+	created_at bigint not null
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_owner_idx ON api_keys(owner_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_category_name_trgm;
+DROP INDEX IF EXISTS idx_product_code_trgm;
+DROP INDEX IF EXISTS idx_product_name_compact_trgm;
+DROP INDEX IF EXISTS idx_product_name_trgm;
+DROP INDEX IF EXISTS idx_product_search_vector;
+
+DROP TRIGGER IF EXISTS category_products_search_vector_trigger ON category_products;
+DROP FUNCTION IF EXISTS category_products_search_vector();
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+DROP FUNCTION IF EXISTS products_search_vector();
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,42 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- name weighs more than code, code more than category
+CREATE OR REPLACE FUNCTION products_search_vector() RETURNS trigger AS $$
+BEGIN
+	NEW.search_vector :=
+		setweight(to_tsvector('simple', coalesce(NEW.product_name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(NEW.product_code, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(
+			(SELECT name FROM category_products WHERE id = NEW.category_id), '')), 'C');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_trigger
+BEFORE INSERT OR UPDATE OF product_name, product_code, category_id ON products
+FOR EACH ROW EXECUTE FUNCTION products_search_vector();
+
+-- a renamed category is searched under its new name
+CREATE OR REPLACE FUNCTION category_products_search_vector() RETURNS trigger AS $$
+BEGIN
+	UPDATE products SET category_id = category_id WHERE category_id = NEW.id;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER category_products_search_vector_trigger
+AFTER UPDATE OF name ON category_products
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION category_products_search_vector();
+
+UPDATE products SET category_id = category_id;
+
+CREATE INDEX IF NOT EXISTS idx_product_search_vector ON products USING gin(search_vector);
+CREATE INDEX IF NOT EXISTS idx_product_name_trgm ON products USING gin(product_name gin_trgm_ops);
+-- "kopisusu" finds "kopi susu"
+CREATE INDEX IF NOT EXISTS idx_product_name_compact_trgm
+ON products USING gin((replace(lower(product_name), ' ', '')) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_product_code_trgm ON products USING gin(product_code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_category_name_trgm ON category_products USING gin(name gin_trgm_ops);
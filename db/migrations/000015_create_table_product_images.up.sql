@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS product_images (
+	id bigserial primary key,
+	product_id bigint not null,
+	position integer not null,
+	object_key varchar(255) not null,
+	thumbnail_key varchar(255) not null,
+	url text not null,
+	thumbnail_url text not null,
+	content_type varchar(50) not null,
+	size bigint not null,
+	width integer not null,
+	height integer not null,
+	created_at bigint not null,
+	foreign key (product_id) references products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_images_product_idx ON product_images(product_id, position);
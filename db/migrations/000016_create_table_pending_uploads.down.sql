@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS order_payment_proofs;
+DROP TABLE IF EXISTS pending_uploads;
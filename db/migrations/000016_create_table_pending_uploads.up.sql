@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS pending_uploads (
+	id char(32) primary key,
+	owner_id varchar(255) not null,
+	purpose varchar(50) not null,
+	target_id bigint not null,
+	object_key varchar(255) unique not null,
+	content_type varchar(50) not null,
+	size bigint not null,
+	status varchar(20) not null default 'pending',
+	expires_at bigint not null,
+	created_at bigint not null,
+	completed_at bigint
+);
+
+CREATE INDEX IF NOT EXISTS pending_uploads_owner_idx ON pending_uploads(owner_id, status);
+
+CREATE TABLE IF NOT EXISTS order_payment_proofs (
+	id bigserial primary key,
+	order_id bigint not null,
+	customer_id varchar(255) not null,
+	object_key varchar(255) not null,
+	url text not null,
+	content_type varchar(50) not null,
+	size bigint not null,
+	created_at bigint not null,
+	foreign key (order_id) references orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS order_payment_proofs_order_idx ON order_payment_proofs(order_id);
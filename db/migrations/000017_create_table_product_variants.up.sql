@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+	id bigserial primary key,
+	product_id bigint not null,
+	size_type_id integer not null,
+	sku varchar(255) not null default '',
+	barcode varchar(255) not null default '',
+	buy_price integer not null default 0,
+	mrp integer not null,
+	stock integer not null default 0,
+	conversion integer not null default 1 check (conversion > 0),
+	active bool not null default true,
+	created bigint not null,
+	updated bigint not null,
+	unique (product_id, size_type_id),
+	foreign key (product_id) references products(id) ON DELETE CASCADE,
+	foreign key (size_type_id) references size_type(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+-- every product is sold at least in its own size type, carts may hold
+-- other size types of a product which are priced like the product
+INSERT INTO product_variants (product_id, size_type_id, sku, buy_price, mrp, stock, active, created, updated)
+SELECT id, size_type_id, product_code, COALESCE(buy_price, 0), COALESCE(mrp, 0), COALESCE(quantity, 0), active,
+	COALESCE(created, 0), COALESCE(updated, 0)
+FROM products
+WHERE size_type_id IS NOT NULL
+ON CONFLICT (product_id, size_type_id) DO NOTHING;
+
+INSERT INTO product_variants (product_id, size_type_id, buy_price, mrp, active, created, updated)
+SELECT DISTINCT c.product_id, c.size_type_id, COALESCE(p.buy_price, 0), COALESCE(p.mrp, 0), p.active,
+	COALESCE(p.created, 0), COALESCE(p.updated, 0)
+FROM carts c
+INNER JOIN products p ON c.product_id = p.id
+ON CONFLICT (product_id, size_type_id) DO NOTHING;
+
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS variant_id bigint;
+
+UPDATE carts c
+SET variant_id = v.id
+FROM product_variants v
+WHERE v.product_id = c.product_id AND v.size_type_id = c.size_type_id;
+
+ALTER TABLE carts
+	ALTER COLUMN variant_id SET NOT NULL,
+	ADD CONSTRAINT carts_variant_id_fkey foreign key (variant_id) references product_variants(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS product_variants_barcode_idx ON product_variants(barcode) WHERE barcode <> '';
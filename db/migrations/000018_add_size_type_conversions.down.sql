@@ -0,0 +1,14 @@
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS stock integer not null default 0;
+
+UPDATE product_variants v
+SET stock = p.base_stock / v.conversion
+FROM products p
+WHERE p.id = v.product_id AND v.size_type_id = p.size_type_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS base_stock;
+
+ALTER TABLE size_type
+	DROP CONSTRAINT IF EXISTS size_type_base_check,
+	DROP CONSTRAINT IF EXISTS size_type_factor_check,
+	DROP COLUMN IF EXISTS factor,
+	DROP COLUMN IF EXISTS base_unit_id;
@@ -0,0 +1,22 @@
+-- a size type is a base unit, or holds factor of its base unit
+ALTER TABLE size_type
+	ADD COLUMN IF NOT EXISTS base_unit_id integer references size_type(id),
+	ADD COLUMN IF NOT EXISTS factor integer not null default 1,
+	ADD CONSTRAINT size_type_factor_check check (factor > 0),
+	ADD CONSTRAINT size_type_base_check check (base_unit_id IS NULL AND factor = 1 OR base_unit_id <> id);
+
+-- stock is kept per product in base units, a variant holds conversion of them
+ALTER TABLE products ADD COLUMN IF NOT EXISTS base_stock bigint not null default 0;
+
+UPDATE products p
+SET base_stock = v.stock
+FROM (
+	SELECT product_id, SUM(stock::bigint * conversion) AS stock
+	FROM product_variants
+	GROUP BY product_id
+) v
+WHERE v.product_id = p.id;
+
+ALTER TABLE products ADD CONSTRAINT products_base_stock_check check (base_stock >= 0);
+
+ALTER TABLE product_variants DROP COLUMN IF EXISTS stock;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS price_tiers;
+DROP TABLE IF EXISTS customer_group_members;
+DROP TABLE IF EXISTS customer_groups;
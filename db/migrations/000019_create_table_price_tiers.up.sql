@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS customer_groups (
+	id bigserial primary key,
+	grocery_id varchar(255) not null,
+	name varchar(255) not null,
+	created_at bigint not null,
+	unique (grocery_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS customer_group_members (
+	group_id bigint not null,
+	customer_id varchar(255) not null,
+	created_at bigint not null,
+	primary key (group_id, customer_id),
+	foreign key (group_id) references customer_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS customer_group_members_customer_idx ON customer_group_members(customer_id);
+
+-- a tier prices a variant at unit_price from min_quantity units, for every
+-- customer or, with group_id, for the members of a customer group
+CREATE TABLE IF NOT EXISTS price_tiers (
+	id bigserial primary key,
+	variant_id bigint not null,
+	group_id bigint,
+	min_quantity integer not null check (min_quantity > 0),
+	unit_price integer not null check (unit_price >= 0),
+	created_at bigint not null,
+	foreign key (variant_id) references product_variants(id) ON DELETE CASCADE,
+	foreign key (group_id) references customer_groups(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS price_tiers_variant_group_quantity_idx
+	ON price_tiers(variant_id, COALESCE(group_id, 0), min_quantity);
@@ -0,0 +1,26 @@
+-- every change of products.base_stock is a movement, quantity is signed and
+-- in base units, balance is the base stock after the movement
+CREATE TABLE IF NOT EXISTS stock_movements (
+	id bigserial primary key,
+	product_id bigint not null,
+	variant_id bigint,
+	kind varchar(20) not null check (kind IN ('receipt', 'sale', 'return', 'adjustment', 'defective', 'transfer')),
+	quantity bigint not null check (quantity <> 0),
+	variant_quantity bigint,
+	balance bigint not null check (balance >= 0),
+	reason text not null default '',
+	actor varchar(255) not null,
+	reference varchar(255) not null default '',
+	created_at bigint not null,
+	foreign key (product_id) references products(id) ON DELETE CASCADE,
+	foreign key (variant_id) references product_variants(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_idx ON stock_movements(product_id, created_at, id);
+CREATE INDEX IF NOT EXISTS stock_movements_reference_idx ON stock_movements(reference) WHERE reference <> '';
+
+-- the current stock opens the ledger
+INSERT INTO stock_movements (product_id, kind, quantity, balance, reason, actor, created_at)
+SELECT id, 'adjustment', base_stock, base_stock, 'saldo awal', 'system', EXTRACT(EPOCH FROM now())::bigint
+FROM products
+WHERE base_stock > 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_reservations;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reserved_stock_check;
+ALTER TABLE products DROP COLUMN IF EXISTS reserved_stock;
@@ -0,0 +1,30 @@
+-- stock held by placed orders, available stock is base_stock - reserved_stock
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved_stock bigint not null default 0;
+ALTER TABLE products ADD CONSTRAINT products_reserved_stock_check CHECK (reserved_stock >= 0 AND reserved_stock <= base_stock);
+
+-- orders placed before reservations already left the stock
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status varchar(20) not null default 'placed'
+	check (status IN ('placed', 'confirmed', 'cancelled', 'expired'));
+UPDATE orders SET status = 'confirmed';
+
+-- one reservation per order line, quantity is in base units and variant_quantity
+-- in the unit of the variant ordered
+CREATE TABLE IF NOT EXISTS stock_reservations (
+	id bigserial primary key,
+	order_id bigint not null,
+	product_id bigint not null,
+	variant_id bigint,
+	grocery_id varchar(255) not null,
+	quantity bigint not null check (quantity > 0),
+	variant_quantity bigint not null check (variant_quantity > 0),
+	status varchar(20) not null default 'reserved' check (status IN ('reserved', 'committed', 'released')),
+	expires_at bigint not null,
+	created_at bigint not null,
+	resolved_at bigint,
+	foreign key (order_id) references orders(id) ON DELETE CASCADE,
+	foreign key (product_id) references products(id) ON DELETE CASCADE,
+	foreign key (variant_id) references product_variants(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS stock_reservations_order_idx ON stock_reservations(order_id);
+CREATE INDEX IF NOT EXISTS stock_reservations_expires_idx ON stock_reservations(expires_at) WHERE status = 'reserved';
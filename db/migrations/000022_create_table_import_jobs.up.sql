@@ -0,0 +1,18 @@
+-- a product import of a grocery, errors holds the rows that failed validation
+CREATE TABLE IF NOT EXISTS import_jobs (
+	id bigserial primary key,
+	grocery_id varchar(255) not null,
+	filename varchar(255) not null default '',
+	format varchar(10) not null check (format IN ('csv', 'xlsx')),
+	dry_run boolean not null default false,
+	status varchar(20) not null default 'pending' check (status IN ('pending', 'running', 'done', 'failed')),
+	total_rows integer not null default 0,
+	processed_rows integer not null default 0,
+	created_rows integer not null default 0,
+	updated_rows integer not null default 0,
+	errors jsonb not null default '[]',
+	created_at bigint not null,
+	finished_at bigint
+);
+
+CREATE INDEX IF NOT EXISTS import_jobs_grocery_idx ON import_jobs(grocery_id, id);
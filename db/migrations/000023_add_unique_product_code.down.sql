@@ -0,0 +1 @@
+DROP INDEX IF EXISTS products_user_code_idx;
@@ -0,0 +1,10 @@
+-- product codes are unique per grocery, duplicates left by free text codes
+-- keep the oldest product and the others get their id appended
+UPDATE products p
+SET product_code = p.product_code || '-' || p.id
+WHERE p.product_code <> '' AND EXISTS (
+	SELECT 1 FROM products o
+	WHERE o.user_id = p.user_id AND o.product_code = p.product_code AND o.id < p.id
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_user_code_idx ON products(user_id, product_code) WHERE product_code <> '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS products_category_idx;
+DROP INDEX IF EXISTS category_products_user_idx;
+DROP INDEX IF EXISTS category_products_parent_idx;
+ALTER TABLE category_products
+	DROP CONSTRAINT IF EXISTS category_products_parent_check,
+	DROP COLUMN IF EXISTS global,
+	DROP COLUMN IF EXISTS parent_id;
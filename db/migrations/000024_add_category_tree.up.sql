@@ -0,0 +1,10 @@
+-- categories form a tree, global ones are the taxonomy managed by admins and
+-- shared by every grocery, the others are private to the grocery in user_id
+ALTER TABLE category_products
+	ADD COLUMN IF NOT EXISTS parent_id integer references category_products(id) ON DELETE RESTRICT,
+	ADD COLUMN IF NOT EXISTS global boolean not null default false,
+	ADD CONSTRAINT category_products_parent_check check (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS category_products_parent_idx ON category_products(parent_id);
+CREATE INDEX IF NOT EXISTS category_products_user_idx ON category_products(user_id) WHERE NOT global;
+CREATE INDEX IF NOT EXISTS products_category_idx ON products(category_id);
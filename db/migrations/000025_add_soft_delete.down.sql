@@ -0,0 +1,26 @@
+DROP INDEX IF EXISTS size_type_deleted_idx;
+DROP INDEX IF EXISTS category_products_deleted_idx;
+DROP INDEX IF EXISTS products_deleted_idx;
+
+-- rows in the trash are removed for good, products first
+DELETE FROM carts WHERE product_id IN (SELECT id FROM products WHERE deleted_at IS NOT NULL);
+DELETE FROM products WHERE deleted_at IS NOT NULL;
+DELETE FROM category_products WHERE deleted_at IS NOT NULL;
+DELETE FROM size_type WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS products_user_code_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS products_user_code_idx ON products(user_id, product_code) WHERE product_code <> '';
+
+ALTER TABLE product_variants
+	DROP CONSTRAINT IF EXISTS product_variants_size_type_id_fkey,
+	ADD CONSTRAINT product_variants_size_type_id_fkey foreign key (size_type_id) references size_type(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE products
+	DROP CONSTRAINT IF EXISTS products_category_id_fkey,
+	DROP CONSTRAINT IF EXISTS products_size_type_id_fkey,
+	ADD CONSTRAINT products_category_id_fkey foreign key (category_id) references category_products(id) ON UPDATE CASCADE ON DELETE CASCADE,
+	ADD CONSTRAINT products_size_type_id_fkey foreign key (size_type_id) references size_type(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE size_type DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE category_products DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,24 @@
+-- catalog rows are soft deleted, deleted_at holds the unix seconds they went
+-- to the trash, the purge removes them for good once they are old enough
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at bigint;
+ALTER TABLE category_products ADD COLUMN IF NOT EXISTS deleted_at bigint;
+ALTER TABLE size_type ADD COLUMN IF NOT EXISTS deleted_at bigint;
+
+-- removing a category or size type must not take its products along
+ALTER TABLE products
+	DROP CONSTRAINT IF EXISTS products_category_id_fkey,
+	DROP CONSTRAINT IF EXISTS products_size_type_id_fkey,
+	ADD CONSTRAINT products_category_id_fkey foreign key (category_id) references category_products(id) ON UPDATE CASCADE ON DELETE RESTRICT,
+	ADD CONSTRAINT products_size_type_id_fkey foreign key (size_type_id) references size_type(id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+ALTER TABLE product_variants
+	DROP CONSTRAINT IF EXISTS product_variants_size_type_id_fkey,
+	ADD CONSTRAINT product_variants_size_type_id_fkey foreign key (size_type_id) references size_type(id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- a product in the trash frees its code
+DROP INDEX IF EXISTS products_user_code_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS products_user_code_idx ON products(user_id, product_code) WHERE product_code <> '' AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS products_deleted_idx ON products(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS category_products_deleted_idx ON category_products(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS size_type_deleted_idx ON size_type(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS order_items_product_idx;
+DROP INDEX IF EXISTS order_items_order_idx;
+
+ALTER TABLE order_items
+	DROP COLUMN IF EXISTS unit_price,
+	DROP COLUMN IF EXISTS base_price,
+	DROP COLUMN IF EXISTS size_type_name,
+	DROP COLUMN IF EXISTS product_name,
+	DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_revisions;
@@ -0,0 +1,23 @@
+-- every change of a product, changes maps each changed field to its old and
+-- new value, such as {"mrp": {"old": 5000, "new": 5500}}
+CREATE TABLE IF NOT EXISTS product_revisions (
+	id bigserial primary key,
+	product_id bigint not null,
+	actor varchar(255) not null,
+	changes jsonb not null,
+	created_at bigint not null,
+	foreign key (product_id) references products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS product_revisions_product_idx ON product_revisions(product_id, created_at, id);
+
+-- order items keep the product as it was ordered, price is the subtotal
+ALTER TABLE order_items
+	ADD COLUMN IF NOT EXISTS variant_id bigint,
+	ADD COLUMN IF NOT EXISTS product_name varchar(255) not null default '',
+	ADD COLUMN IF NOT EXISTS size_type_name varchar(255) not null default '',
+	ADD COLUMN IF NOT EXISTS base_price integer not null default 0,
+	ADD COLUMN IF NOT EXISTS unit_price integer not null default 0;
+
+CREATE INDEX IF NOT EXISTS order_items_order_idx ON order_items(order_id);
+CREATE INDEX IF NOT EXISTS order_items_product_idx ON order_items(product_id);
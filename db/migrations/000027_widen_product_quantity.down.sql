@@ -0,0 +1 @@
+ALTER TABLE products ALTER COLUMN quantity TYPE smallint USING LEAST(quantity, 32767);
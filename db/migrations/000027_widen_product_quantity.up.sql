@@ -0,0 +1,2 @@
+-- the opening quantity of a product is no longer capped at smallint
+ALTER TABLE products ALTER COLUMN quantity TYPE bigint;
@@ -0,0 +1,3 @@
+-- orders are soft deleted so their items still tell what was ordered,
+-- deleted_at holds the unix seconds the customer removed the order
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS deleted_at bigint;
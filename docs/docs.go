@@ -22,54 +22,50 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/add-user-groups": {
-            "post": {
+        "/admin/role-requests": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do add user to group",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do list role requests, pending ones by default",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Add user to group process",
+                "summary": "List role requests process",
                 "parameters": [
                     {
-                        "description": "user add to group",
-                        "name": "addUserToGroup",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.UpdateGroup"
-                        }
+                        "type": "string",
+                        "description": "pending, approved or rejected",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "user success to add group",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
-                        }
-                    },
-                    "400": {
-                        "description": "Error Bad request",
+                        "description": "role requests",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RoleRequests"
+                            }
                         }
                     }
                 }
             }
         },
-        "/auth/confirm": {
+        "/admin/role-requests/{id}/approve": {
             "post": {
-                "description": "do confirmation account",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do approve a role request and add the user to the group",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,29 +73,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Confirmation SignUp access process",
+                "summary": "Approve role request process",
                 "parameters": [
                     {
-                        "description": "used for confirmation an account",
-                        "name": "confirmData",
+                        "type": "integer",
+                        "description": "role request id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "review note",
+                        "name": "review",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.AuthCodeData"
+                            "$ref": "#/definitions/dtos.ReviewRoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "redirect to home",
+                        "description": "approved request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.RoleRequests"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "409": {
+                        "description": "request already reviewed",
                         "schema": {
                             "type": "string"
                         }
@@ -107,9 +109,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/forgot": {
+        "/admin/role-requests/{id}/reject": {
             "post": {
-                "description": "do forgot password to an account",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do reject a role request",
                 "consumes": [
                     "application/json"
                 ],
@@ -117,29 +124,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "ForgotPassword process",
+                "summary": "Reject role request process",
                 "parameters": [
                     {
-                        "description": "used for forgot password",
-                        "name": "forgotData",
+                        "type": "integer",
+                        "description": "role request id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "review note",
+                        "name": "review",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.Users"
+                            "$ref": "#/definitions/dtos.ReviewRoleRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "send code confirmation for forgot password",
+                        "description": "rejected request",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/models.RoleRequests"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "409": {
+                        "description": "request already reviewed",
                         "schema": {
                             "type": "string"
                         }
@@ -147,28 +160,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/google": {
+        "/admin/users": {
             "get": {
-                "description": "do logout account",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "do search users by email prefix",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Search users process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "email prefix",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max users, up to 60",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Login google access process",
                 "responses": {
                     "200": {
-                        "description": "logged in via google successfully",
+                        "description": "users",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/cognito.User"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "403": {
+                        "description": "permission denied",
                         "schema": {
                             "type": "string"
                         }
@@ -176,33 +208,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/list-groups": {
+        "/admin/users/{username}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do list groups",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do get a user with its groups",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Get user process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "list group process",
                 "responses": {
                     "200": {
-                        "description": "list group",
+                        "description": "user",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/cognito.User"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
@@ -210,39 +248,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
+        "/admin/users/{username}/disable": {
             "post": {
-                "description": "do login account",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "do disable an account, end all of its sessions and revoke its API keys",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login access process",
+                "summary": "Disable user process",
                 "parameters": [
                     {
-                        "description": "login data",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.AuthData"
-                        }
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "the message success responses",
+                        "description": "user disabled",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
@@ -250,33 +288,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/logout": {
-            "get": {
+        "/admin/users/{username}/enable": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do logout account",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do enable a disabled account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "Enable user process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Logout access process",
                 "responses": {
                     "200": {
-                        "description": "logged out successfully",
+                        "description": "user enabled",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "404": {
+                        "description": "user not found",
                         "schema": {
                             "type": "string"
                         }
@@ -284,39 +328,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "do register account",
-                "consumes": [
-                    "application/json"
+        "/admin/users/{username}/groups/{group}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "do remove a user from a group, leaving grosir also revokes the API keys of the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register access process",
+                "summary": "Remove user group process",
                 "parameters": [
                     {
-                        "description": "used for register",
-                        "name": "registerData",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.AuthData"
-                        }
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "group name",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "send code confirmation",
+                        "description": "user removed from group",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "404": {
+                        "description": "user or group not found",
                         "schema": {
                             "type": "string"
                         }
@@ -324,49 +375,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/resend": {
-            "post": {
-                "description": "do resend code to an account",
-                "consumes": [
-                    "application/json"
+        "/auth/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
                 ],
+                "description": "do list the API keys of the current user, revoked keys included",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "ResendCode process",
-                "parameters": [
-                    {
-                        "description": "used for resend code",
-                        "name": "resendCode",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.Users"
-                        }
-                    }
-                ],
+                "summary": "List API keys process",
                 "responses": {
                     "200": {
-                        "description": "check inbox email address",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
-                        }
-                    },
-                    "400": {
-                        "description": "Error Bad request",
+                        "description": "api keys",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKeys"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/auth/reset": {
+            },
             "post": {
-                "description": "do reset password to an account",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do mint an API key for integrations, the key is only shown in this response",
                 "consumes": [
                     "application/json"
                 ],
@@ -376,23 +418,23 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "ResetPassword process",
+                "summary": "Create API key process",
                 "parameters": [
                     {
-                        "description": "used for reset password",
-                        "name": "resetPassword",
+                        "description": "name, scopes and lifetime of the key",
+                        "name": "apiKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.AuthResetData"
+                            "$ref": "#/definitions/dtos.APIKeyData"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "send for reset password",
+                    "201": {
+                        "description": "the new key",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/dtos.APIKeyResponse"
                         }
                     },
                     "400": {
@@ -400,80 +442,88 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/groceries/operational": {
-            "get": {
+        "/auth/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do Get all operational",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do revoke an API key of the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
+                    "auth"
+                ],
+                "summary": "Revoke API key process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "api key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "GetAllOperational access process",
                 "responses": {
                     "200": {
-                        "description": "the message successfully create",
+                        "description": "key revoked",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad Request",
+                    "404": {
+                        "description": "key not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do create a operational",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            }
+        },
+        "/auth/callback": {
+            "get": {
+                "description": "exchange the authorization code and start a session",
                 "tags": [
-                    "groceries"
+                    "auth"
                 ],
-                "summary": "CreateOperational access process",
+                "summary": "Hosted UI callback",
                 "parameters": [
                     {
-                        "description": "create a operational",
-                        "name": "operational",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.Operate"
-                        }
+                        "type": "string",
+                        "description": "authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "state sent to the hosted UI",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "the message successfully create",
+                    "302": {
+                        "description": "redirect to the post login url",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Error Bad Request",
+                        "description": "invalid or expired login",
                         "schema": {
                             "type": "string"
                         }
@@ -481,86 +531,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/groceries/operational/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do Get ID operational",
+        "/auth/confirm": {
+            "post": {
+                "description": "do confirmation account",
                 "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "groceries"
-                ],
-                "summary": "GetIDOperational access process",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "id operational",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {}
-            },
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do Update a operational",
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
+                    "auth"
                 ],
-                "summary": "UpdateOperational access process",
+                "summary": "Confirmation SignUp access process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "id operational",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "update a operational",
-                        "name": "operational",
+                        "description": "used for confirmation an account",
+                        "name": "confirmData",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.Operate"
+                            "$ref": "#/definitions/dtos.AuthCodeData"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "the message successfully create",
+                        "description": "redirect to home",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "Error Bad Request",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do delete operational",
+            }
+        },
+        "/auth/forgot": {
+            "post": {
+                "description": "do forgot password to an account",
                 "consumes": [
                     "application/json"
                 ],
@@ -568,21 +581,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
+                    "auth"
                 ],
-                "summary": "delete operational process",
+                "summary": "ForgotPassword process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "get id operational",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "used for forgot password",
+                        "name": "forgotData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Users"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "delete operational successfully",
+                        "description": "send code confirmation for forgot password",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
@@ -592,37 +607,47 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "429": {
+                        "description": "too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/groceries/products": {
+        "/auth/google": {
             "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do get all product",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "redirect to the hosted UI to login with google",
                 "tags": [
-                    "groceries"
+                    "auth"
                 ],
-                "summary": "GetAll product groceries process",
-                "responses": {}
-            },
-            "post": {
+                "summary": "Login google access process",
+                "responses": {
+                    "302": {
+                        "description": "redirect to the hosted UI",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "login could not be started",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/list-groups": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do create a product",
+                "description": "do list groups",
                 "consumes": [
                     "application/json"
                 ],
@@ -630,29 +655,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
-                ],
-                "summary": "CreateProduct access process",
-                "parameters": [
-                    {
-                        "description": "create a product",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.Product"
-                        }
-                    }
+                    "auth"
                 ],
+                "summary": "list group process",
                 "responses": {
                     "200": {
-                        "description": "the message successfully create",
+                        "description": "list group",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "Error Bad Request",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
@@ -660,14 +674,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/groceries/products/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do update a product",
+        "/auth/login": {
+            "post": {
+                "description": "do login account",
                 "consumes": [
                     "application/json"
                 ],
@@ -675,55 +684,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
+                    "auth"
                 ],
-                "summary": "UpdateProduct access process",
+                "summary": "Login access process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "id a product",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "update a product",
-                        "name": "product",
+                        "description": "login data",
+                        "name": "login",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.Product"
+                            "$ref": "#/definitions/dtos.AuthData"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the device shown in the session list",
+                        "name": "X-Device-Name",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "the message successfully create",
+                        "description": "the tokens of the new session",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/dtos.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Error Bad Request",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "429": {
+                        "description": "too many failed attempts",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/logout": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete a product",
+                "description": "do logout account",
                 "consumes": [
                     "application/json"
                 ],
@@ -731,21 +741,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "groceries"
-                ],
-                "summary": "DeleteID product process",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "delete a product",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Logout access process",
                 "responses": {
                     "200": {
-                        "description": "delete a product",
+                        "description": "logged out successfully",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
@@ -759,42 +760,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/{id}": {
-            "get": {
+        "/auth/logout-all": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get id product",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "global sign out, every session and token of the user is revoked",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
-                ],
-                "summary": "GetID product process",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "get id product",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Logout from every device",
                 "responses": {
                     "200": {
-                        "description": "get id category",
+                        "description": "logged out from every device",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "cookies not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
@@ -802,14 +791,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do get all product",
+        "/auth/refresh": {
+            "post": {
+                "description": "issue a new access token for the session of a refresh token",
                 "consumes": [
                     "application/json"
                 ],
@@ -817,18 +801,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "refresh token from login",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshData"
+                        }
+                    }
                 ],
-                "summary": "GetAll product process",
                 "responses": {
                     "200": {
-                        "description": "get all category",
+                        "description": "new access token",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/dtos.LoginResponse"
                         }
                     },
-                    "400": {
-                        "description": "cookies not found",
+                    "401": {
+                        "description": "invalid refresh token",
                         "schema": {
                             "type": "string"
                         }
@@ -836,14 +831,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/category": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do get all category",
+        "/auth/register": {
+            "post": {
+                "description": "do register account",
                 "consumes": [
                     "application/json"
                 ],
@@ -851,31 +841,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
+                ],
+                "summary": "Register access process",
+                "parameters": [
+                    {
+                        "description": "used for register",
+                        "name": "registerData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AuthData"
+                        }
+                    }
                 ],
-                "summary": "GetAll category process",
                 "responses": {
                     "200": {
-                        "description": "get all category",
+                        "description": "send code confirmation",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "cookies not found",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/resend": {
             "post": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do create category",
+                "description": "do resend code to an account",
                 "consumes": [
                     "application/json"
                 ],
@@ -883,23 +881,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "create category process",
+                "summary": "ResendCode process",
                 "parameters": [
                     {
-                        "description": "create category",
-                        "name": "category",
+                        "description": "used for resend code",
+                        "name": "resendCode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.CategoryProducts"
+                            "$ref": "#/definitions/dtos.Users"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "create category successfully",
+                        "description": "check inbox email address",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
@@ -909,18 +907,19 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "429": {
+                        "description": "too many requests",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/products/category/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "Bearer": []
-                    }
-                ],
-                "description": "do get id category",
+        "/auth/reset": {
+            "post": {
+                "description": "do reset password to an account",
                 "consumes": [
                     "application/json"
                 ],
@@ -928,90 +927,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "GetID category process",
+                "summary": "ResetPassword process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "get id category",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "used for reset password",
+                        "name": "resetPassword",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AuthResetData"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "get category",
+                        "description": "send for reset password",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "cookie not found",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/role-requests": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do update category",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do list the role requests of the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
-                ],
-                "summary": "update category process",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "access id category",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "update category",
-                        "name": "category",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.CategoryProducts"
-                        }
-                    }
+                    "auth"
                 ],
+                "summary": "List own role requests process",
                 "responses": {
                     "200": {
-                        "description": "update category successfully",
-                        "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
-                        }
-                    },
-                    "400": {
-                        "description": "Error Bad request",
+                        "description": "role requests",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RoleRequests"
+                            }
                         }
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete a category",
+                "description": "do request a role, retail is granted right away while other roles wait for an admin",
                 "consumes": [
                     "application/json"
                 ],
@@ -1019,27 +998,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "DeleteID category process",
+                "summary": "Request role process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "delete size with category",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "role to request",
+                        "name": "roleRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RoleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "get all size",
+                    "201": {
+                        "description": "the role request",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/models.RoleRequests"
                         }
                     },
                     "400": {
-                        "description": "cookie not found",
+                        "description": "Error Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "request already pending",
                         "schema": {
                             "type": "string"
                         }
@@ -1047,76 +1034,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/size": {
+        "/auth/sessions": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get all size",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "list the signed in devices of the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "GetAll Size process",
+                "summary": "List sessions",
                 "responses": {
                     "200": {
-                        "description": "get all size",
+                        "description": "sessions of the user",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.SessionResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do create size type",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "sign out one device of the current user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "create sizeType process",
+                "summary": "Revoke session",
                 "parameters": [
                     {
-                        "description": "create sizetype",
-                        "name": "size",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.SizeType"
-                        }
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "create size type successfully",
+                        "description": "session revoked",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "404": {
+                        "description": "session not found",
                         "schema": {
                             "type": "string"
                         }
@@ -1124,133 +1108,120 @@ const docTemplate = `{
                 }
             }
         },
-        "/products/size/{id}": {
+        "/auth/users/{username}/lockout": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get all size",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "show the failed attempts and lockout of an account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "GetID Size process",
+                "summary": "Lockout status",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "get id size",
-                        "name": "id",
+                        "type": "string",
+                        "description": "email of the account",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "get all size",
+                        "description": "lockout per action",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dtos.LockoutResponse"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "403": {
+                        "description": "permission denied",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get all size",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "clear the failed attempts and lockout of an account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "Update Size process",
+                "summary": "Unlock account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "access id size type",
-                        "name": "id",
+                        "type": "string",
+                        "description": "email of the account",
+                        "name": "username",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "update size type",
-                        "name": "name",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.SizeType"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "get all size",
+                        "description": "account unlocked",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "403": {
+                        "description": "permission denied",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/users/{username}/logout": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete a size",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "revoke every session and token of a compromised account",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "auth"
                 ],
-                "summary": "DeleteID Size process",
+                "summary": "Force logout a user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "delete size with id",
-                        "name": "id",
+                        "type": "string",
+                        "description": "username of the account",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "get all size",
+                        "description": "user logged out",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "cookie not found",
+                    "403": {
+                        "description": "permission denied",
                         "schema": {
                             "type": "string"
                         }
@@ -1258,35 +1229,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/carts": {
+        "/groceries/customer-groups": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get all carts",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do list the customer groups of the grocery",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "GetAll carts process",
+                "summary": "List customer groups process",
                 "responses": {
                     "200": {
-                        "description": "get all transactions",
+                        "description": "groups of the grocery",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
-                        }
-                    },
-                    "400": {
-                        "description": "not authorized",
-                        "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.CustomerGroups"
+                            }
                         }
                     }
                 }
@@ -1297,7 +1262,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "do create a cart",
+                "description": "do create a customer group, a price list for some retailers of the grocery",
                 "consumes": [
                     "application/json"
                 ],
@@ -1305,29 +1270,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "CreateCart access process",
+                "summary": "Create customer group process",
                 "parameters": [
                     {
-                        "description": "create a cart",
-                        "name": "create",
+                        "description": "name of the group",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.Carts"
+                            "$ref": "#/definitions/dtos.CustomerGroup"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "the message successfully create",
+                    "201": {
+                        "description": "created group",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/products.CustomerGroups"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad Request",
+                    "409": {
+                        "description": "group already exists",
                         "schema": {
                             "type": "string"
                         }
@@ -1335,55 +1300,89 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/carts/{id}": {
-            "get": {
+        "/groceries/customer-groups/{groupId}": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get id carts",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do delete a customer group with its members and price tiers",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "GetID carts process",
+                "summary": "Delete customer group process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "get id transaction",
-                        "name": "id",
+                        "description": "group id",
+                        "name": "groupId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "get id transaction",
+                        "description": "group deleted",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "unauthorized",
+                    "404": {
+                        "description": "group not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
+            }
+        },
+        "/groceries/customer-groups/{groupId}/members": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do list the customers of a customer group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "List customer group members process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "group id",
+                        "name": "groupId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "members of the group",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.CustomerGroupMembers"
+                            }
+                        }
+                    }
+                }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do update cart",
+                "description": "do add a customer to a customer group",
                 "consumes": [
                     "application/json"
                 ],
@@ -1391,77 +1390,83 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "update cart process",
+                "summary": "Add customer group member process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "access id transactions",
-                        "name": "id",
+                        "description": "group id",
+                        "name": "groupId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "update carts",
-                        "name": "transaction",
+                        "description": "customer to add",
+                        "name": "member",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.Carts"
+                            "$ref": "#/definitions/dtos.GroupMember"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "update transaction successfully",
+                        "description": "member added",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "Error Bad request",
+                    "404": {
+                        "description": "group not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/groceries/customer-groups/{groupId}/members/{customerId}": {
             "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete a transaction",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do remove a customer from a customer group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "delete id transaction process",
+                "summary": "Remove customer group member process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "delete charts",
-                        "name": "id",
+                        "description": "group id",
+                        "name": "groupId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "customerId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "delete successfully",
+                        "description": "member removed",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
-                    "400": {
-                        "description": "unauthorized",
+                    "404": {
+                        "description": "member not found",
                         "schema": {
                             "type": "string"
                         }
@@ -1469,14 +1474,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/checkout": {
+        "/groceries/operational": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do Get All checkout",
+                "description": "do Get all operational",
                 "consumes": [
                     "application/json"
                 ],
@@ -1484,18 +1489,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "Get All Checkout access process",
+                "summary": "GetAllOperational access process",
                 "responses": {
                     "200": {
-                        "description": "message responses",
+                        "description": "the message successfully create",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "not authorized",
+                        "description": "Error Bad Request",
                         "schema": {
                             "type": "string"
                         }
@@ -1508,7 +1513,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "do create a create checkout",
+                "description": "do create a operational",
                 "consumes": [
                     "application/json"
                 ],
@@ -1516,18 +1521,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
+                ],
+                "summary": "CreateOperational access process",
+                "parameters": [
+                    {
+                        "description": "create a operational",
+                        "name": "operational",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Operate"
+                        }
+                    }
                 ],
-                "summary": "CreateCheckout access process",
                 "responses": {
                     "200": {
-                        "description": "message responses",
+                        "description": "the message successfully create",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "not authorized",
+                        "description": "Error Bad Request",
                         "schema": {
                             "type": "string"
                         }
@@ -1535,46 +1551,86 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/checkout/{id}": {
+        "/groceries/operational/{id}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do Get id checkout",
+                "description": "do Get ID operational",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "GetIDOperational access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id operational",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do Update a operational",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
+                ],
+                "summary": "UpdateOperational access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id operational",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update a operational",
+                        "name": "operational",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Operate"
+                        }
+                    }
                 ],
-                "summary": "Get id Checkout access process",
                 "responses": {
                     "200": {
-                        "description": "message responses",
+                        "description": "the message successfully create",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "not authorized",
+                        "description": "Error Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do update id checkout",
+                "description": "do delete operational",
                 "consumes": [
                     "application/json"
                 ],
@@ -1582,59 +1638,73 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
+                ],
+                "summary": "delete operational process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id operational",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "update id Checkout access process",
                 "responses": {
                     "200": {
-                        "description": "message responses",
+                        "description": "delete operational successfully",
                         "schema": {
                             "$ref": "#/definitions/dtos.MessagesResponses"
                         }
                     },
                     "400": {
-                        "description": "not authorized",
+                        "description": "Error Bad request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/groceries/orders/{id}/confirm": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete id checkout",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do confirm the lines of an order at the grocery, turning the stock they reserved into sales",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "delete id Checkout access process",
+                "summary": "Confirm order process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "delete by id",
+                        "description": "order id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "message responses",
+                    "201": {
+                        "description": "confirmation of the grocery",
                         "schema": {
-                            "$ref": "#/definitions/dtos.MessagesResponses"
+                            "$ref": "#/definitions/transactions.OrderConfirmations"
                         }
                     },
-                    "400": {
-                        "description": "not authorized",
+                    "404": {
+                        "description": "no lines of the order to confirm",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order is no longer placed",
                         "schema": {
                             "type": "string"
                         }
@@ -1642,24 +1712,129 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/orders": {
+        "/groceries/orders/{id}/payment-proofs": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get all order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do get the payment proofs uploaded for an order, to its customer or a grocery holding lines of it.\nProofs are private, each url is presigned and valid for a short time only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "transactions"
                 ],
-                "summary": "Get All Order access process",
+                "summary": "Get payment proofs of order process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "payment proofs of the order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/transactions.PaymentProofs"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of the products of the current grocery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "GetAll product groceries process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id, subcategories included",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "size type id",
+                        "name": "size_type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "active products only, or inactive only",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lowest retail price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "highest retail price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "products with quantity left",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name, price, created or updated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {}
             },
             "post": {
@@ -1668,7 +1843,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "do create a order",
+                "description": "do create a product",
                 "consumes": [
                     "application/json"
                 ],
@@ -1676,76 +1851,230 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "Create Orders access process",
-                "responses": {}
+                "summary": "CreateProduct access process",
+                "parameters": [
+                    {
+                        "description": "create a product",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the message successfully create",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "product code already used by the grocery",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
             }
         },
-        "/transactions/orders/{id}": {
+        "/groceries/products/by-code/{code}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do get id order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "do resolve a scanned product code or variant barcode of the current grocery to its product",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "Get id order access process",
+                "summary": "Get product by code process",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "get id order",
-                        "name": "id",
+                        "type": "string",
+                        "description": "product code or variant barcode",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {}
-            },
-            "put": {
+                "responses": {
+                    "200": {
+                        "description": "product, with the variant when the code is a variant barcode",
+                        "schema": {
+                            "$ref": "#/definitions/products.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "no product with the code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/export": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do download the products of the grocery in the format of an import",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Export products process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv or xlsx, csv by default",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "products",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/import": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do update id order",
+                "description": "do import products from a csv or xlsx file in the background, upserting them by product_code.\nThe header names the columns product_code, product_name, category, size_type, buy_price,\nmin_retail_price, quantity, position and active, mapping renames them. A dry run only validates.",
                 "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Import products process",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv or xlsx file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json object of column to header, such as {\\",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate the rows",
+                        "name": "dry_run",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "started import",
+                        "schema": {
+                            "$ref": "#/definitions/products.ImportJobs"
+                        }
+                    },
+                    "400": {
+                        "description": "unreadable file or header",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/import/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get the progress and the row errors of a product import",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "update id order access process",
+                "summary": "Get product import process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "update id order",
-                        "name": "id",
+                        "description": "import job id",
+                        "name": "jobId",
                         "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {}
-            },
-            "delete": {
+                "responses": {
+                    "200": {
+                        "description": "import job",
+                        "schema": {
+                            "$ref": "#/definitions/products.ImportJobs"
+                        }
+                    },
+                    "404": {
+                        "description": "import job not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}": {
+            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "do delete id order",
+                "description": "do update a product",
                 "consumes": [
                     "application/json"
                 ],
@@ -1753,63 +2082,3946 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "groceries"
                 ],
-                "summary": "delete id order access process",
+                "summary": "UpdateProduct access process",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "delete by id",
+                        "description": "id a product",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "update a product",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Product"
+                        }
                     }
                 ],
-                "responses": {}
+                "responses": {
+                    "200": {
+                        "description": "the message successfully create",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad Request or size type of another base unit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "product code already used by the grocery",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do move a product to the trash, it can be restored until the purge removes it with its images",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "DeleteID product process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete a product",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "delete a product",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of the changes of a product, who made each and the old and new value of every changed field.\nPrices of its variants and their price tiers are recorded too.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Product history process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only changes of the field, such as min_retail_price, variants.12.buy_price or variants.12.tiers.3",
+                        "name": "field",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "changes at or after the unix second",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "changes before the unix second",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "product revisions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductRevisions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/images": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do upload jpeg, png or gif images of a product, appended after its current images",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Upload product images process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "one or more images",
+                        "name": "images",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "uploaded images",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductImages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "product not found or in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "image too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/images/order": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do set the display order of the images of a product, the first one is the cover",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Reorder product images process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "every image id of the product in the new order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ImageOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "images in the new order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductImages"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid order",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/images/{imageId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do delete an image of a product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Delete product image process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "image id",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "image deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "image not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/stock": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do record a receipt, sale, return, adjustment, defective write-off or transfer of a product. The quantity is signed and in units of variant_id, or in base units without it. A transfer moves a positive quantity to to_product_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Record stock movement process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "stock movement",
+                        "name": "movement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StockMovement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "recorded movements",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.StockMovements"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "insufficient stock",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/stock-history": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of the stock ledger of a product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Stock history process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "receipt, sale, return, adjustment, defective or transfer",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stock movements",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.StockMovements"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/variants": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do list the variants of a product of the grocery, inactive ones included",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "List own product variants process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "variants of the product",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductVariants"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do add a unit the product is sold in, with its own price and stock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Create product variant process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "variant of the product",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProductVariant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created variant",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductVariants"
+                        }
+                    },
+                    "400": {
+                        "description": "size type of another base unit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "size type already used",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/variants/{variantId}": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do update the price, stock or unit of a product variant. The variant of the size type of the product\nkeeps its size type, its prices are the prices of the product and are changed with it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Update product variant process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "variant id",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "variant of the product",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ProductVariant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "updated variant",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductVariants"
+                        }
+                    },
+                    "400": {
+                        "description": "size type of another base unit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "variant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "size type already used or of the default variant",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do delete a product variant, carts holding it are removed. The variant of the size type of the product\nis deleted only with the product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Delete product variant process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "variant id",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "variant deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "variant not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "default variant of the product",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/variants/{variantId}/tiers": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do list the price tiers of a variant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "List price tiers process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "variant id",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tiers of the variant",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.PriceTiers"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do add a unit price of a variant from a minimum quantity, for every customer or a customer group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Create price tier process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "variant id",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "price tier",
+                        "name": "tier",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PriceTier"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "created tier",
+                        "schema": {
+                            "$ref": "#/definitions/products.PriceTiers"
+                        }
+                    },
+                    "409": {
+                        "description": "tier already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/products/{id}/variants/{variantId}/tiers/{tierId}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do delete a price tier of a variant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Delete price tier process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "variant id",
+                        "name": "variantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tier id",
+                        "name": "tierId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tier deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "tier not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/trash": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of the products, categories or sizes the grocery deleted, taxonomy managers also see\nthe deleted global categories. The purge removes them for good after a while.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Trash process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product, category or size_type, product by default",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "deleted or name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deleted rows",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.TrashItems"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/groceries/trash/{type}/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do take a product, category or size out of the trash. A product needs its category and size,\na category its parent and a size its base unit out of the trash first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groceries"
+                ],
+                "summary": "Restore process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product, category or size_type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id of the deleted row",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "restored",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "not in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "a required row is in the trash or the product code is used again",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get id product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetID product process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id product",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get id category",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookies not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/images": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do list the images of a product in display order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List product images process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "images of the product",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductImages"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/variants": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do list the active units a product is sold in, smallest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List product variants process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "variants of the product",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.ProductVariants"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of products, sort by name, price, created or updated",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetAll product process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id, subcategories included",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "size type id",
+                        "name": "size_type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery",
+                        "name": "grocery_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "active products only, or inactive only",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lowest retail price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "highest retail price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "products with quantity left",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name, price, created or updated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all category",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/category": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of the global categories and the private ones of the grocery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetAll category process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery, the caller by default",
+                        "name": "grocery_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only the subcategories of the category",
+                        "name": "parent_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name or id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all category",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create category under parent_id, up to 5 levels deep. A global category is part of the\ntaxonomy shared by every grocery and needs the taxonomy:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "create category process",
+                "parameters": [
+                    {
+                        "description": "create category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CategoryProducts"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "create category successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "global category without taxonomy:manage",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/category/tree": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get the global categories and the private ones of the grocery as trees",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Tree category process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery, the caller by default",
+                        "name": "grocery_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "category tree",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.CategoryProducts"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/products/category/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get id category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetID category process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id category",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get category",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do update category, a new parent_id must not be the category or one of its subcategories.\nA global category needs the taxonomy:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "update category process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "access id category",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CategoryProducts"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "update category successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "global category without taxonomy:manage",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do move a category without subcategories or products to the trash, a global category needs\nthe taxonomy:manage permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "DeleteID category process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete size with category",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all size",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "global category without taxonomy:manage",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "category has subcategories or products",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/category/{id}/breadcrumb": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get the path from the root category to the category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Breadcrumb category process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery, the caller by default",
+                        "name": "grocery_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "root first, the category last",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.CategoryProducts"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do search products by name, code and category, best match first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Search product process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search text, words in any order",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "category id, subcategories included",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "size type id",
+                        "name": "size_type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery",
+                        "name": "grocery_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "active products only, or inactive only",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lowest retail price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "highest retail price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "products with quantity left",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "matching products",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/size": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of sizes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetAll Size process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id of the grocery",
+                        "name": "grocery_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name or id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all size",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create size type, a base unit or factor of a base unit of the grocery",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "create sizeType process",
+                "parameters": [
+                    {
+                        "description": "create sizetype",
+                        "name": "size",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SizeType"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "create size type successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/size/convert": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do convert a quantity between two size types sharing a base unit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Convert Size process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "size type id to convert from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "size type id to convert to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "quantity in the from size type",
+                        "name": "quantity",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "converted quantity",
+                        "schema": {
+                            "$ref": "#/definitions/products.Conversion"
+                        }
+                    },
+                    "400": {
+                        "description": "size types without a shared base unit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/size/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get all size",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "GetID Size process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id size",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all size",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get all size",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update Size process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "access id size type",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update size type",
+                        "name": "name",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SizeType"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all size",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "base unit or factor of a size in use",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do move a size to the trash, a size of products, variants or other sizes can not be deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "DeleteID Size process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete size with id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all size",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "cookie not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "size still in use",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get the profile of the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Get profile process",
+                "responses": {
+                    "200": {
+                        "description": "the profile",
+                        "schema": {
+                            "$ref": "#/definitions/models.Profiles"
+                        }
+                    },
+                    "500": {
+                        "description": "Error Internal Server",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do update the profile of the current user, name, phone and avatar are synced to cognito",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Update profile process",
+                "parameters": [
+                    {
+                        "description": "fields to update",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Profile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the updated profile",
+                        "schema": {
+                            "$ref": "#/definitions/models.Profiles"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "permission denied",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/carts": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of carts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "GetAll carts process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "created or name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get all transactions",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid query",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create a cart of a product variant, variant_id or else the variant of size_type_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "CreateCart access process",
+                "parameters": [
+                    {
+                        "description": "create a cart",
+                        "name": "create",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Carts"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the message successfully create",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/carts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get id carts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "GetID carts process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id transaction",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "get id transaction",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do update cart",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "update cart process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "access id transactions",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update carts",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.Carts"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "update transaction successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Error Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do delete a transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "delete id transaction process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete charts",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "delete successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/checkout": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do Get All checkout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get All Checkout access process",
+                "responses": {
+                    "200": {
+                        "description": "message responses",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "not authorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create a checkout of the carts priced at their tiers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "CreateCheckout access process",
+                "responses": {
+                    "201": {
+                        "description": "line by line price breakdown",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PriceBreakdown"
+                        }
+                    },
+                    "400": {
+                        "description": "not authorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/checkout/preview": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do price the carts at their tiers without creating a checkout",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Preview Checkout access process",
+                "responses": {
+                    "200": {
+                        "description": "line by line price breakdown",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.PriceBreakdown"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/checkout/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do Get id checkout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get id Checkout access process",
+                "responses": {
+                    "200": {
+                        "description": "message responses",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "not authorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do update id checkout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "update id Checkout access process",
+                "responses": {
+                    "200": {
+                        "description": "message responses",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "not authorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do delete id checkout",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "delete id Checkout access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete by id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message responses",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "not authorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/orders": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get a page of orders",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get All Order access process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "date or total",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "rows to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create a order, reserving the stock of every cart until the groceries confirm it. The items keep\nthe product name and the price at the time of the order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create Orders access process",
+                "responses": {
+                    "201": {
+                        "description": "placed order",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.Orders"
+                        }
+                    },
+                    "400": {
+                        "description": "the cart is empty",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "carts the stock cannot fill",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/products.StockShortage"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get id order with its items as they were ordered",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get id order access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "get id order",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "order with its items",
+                        "schema": {
+                            "$ref": "#/definitions/transactions.Orders"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "recalculate the total of the order from its items as they were ordered",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "update id order access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "update id order",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "hide the order, a placed order is cancelled and its stock released, the order and its items are kept for its invoice",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "delete id order access process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "delete by id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/transactions/orders/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do cancel a placed order and release the stock it reserved",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Cancel order process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "order cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "order not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "order is no longer placed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/orders/{id}/payment-proofs": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do get the payment proofs uploaded for an order, to its customer or a grocery holding lines of it.\nProofs are private, each url is presigned and valid for a short time only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get payment proofs of order process",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "order id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "payment proofs of the order",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/transactions.PaymentProofs"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do create a presigned URL to PUT a product image or a payment proof straight to the storage, then call complete",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Create upload process",
+                "parameters": [
+                    {
+                        "description": "purpose, target id, content type and size of the file",
+                        "name": "upload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "where and how to upload",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UploadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid upload",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/local/{token}": {
+            "get": {
+                "description": "do serve a private file with a URL signed by the local storage driver",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Local download process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "signed download token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stored file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid or expired token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "file not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "do receive a file uploaded with a URL signed by the local storage driver",
+                "consumes": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Local upload process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "signed upload token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "file stored",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MessagesResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "invalid or expired token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/uploads/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "do attach an uploaded file to its product or order once it is found in the storage. The file is read\nback and checked by its content, a product image also gets a thumbnail like an image uploaded to the product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "uploads"
+                ],
+                "summary": "Complete upload process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "upload id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "attached image or payment proof",
+                        "schema": {
+                            "$ref": "#/definitions/products.ProductImages"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid image or the product has too many images",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "upload not found or its product is in the trash",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "file not uploaded yet or upload closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "file too large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "cognito.User": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.APIKeyData": {
+            "type": "object",
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dtos.AuthCodeData": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AuthData": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.AuthResetData": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Carts": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "size_type_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.CategoryProducts": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "global": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.CustomerGroup": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.GroupMember": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ImageOrder": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "dtos.LockoutResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "locked": {
+                    "type": "boolean"
+                },
+                "retry_after": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "expiresIn": {
+                    "type": "integer"
+                },
+                "idToken": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.MessagesResponses": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Operate": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "close": {
+                    "type": "string"
+                },
+                "day_operational": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "senin",
+                            "selasa",
+                            "rabu",
+                            "kamis",
+                            "jum'at",
+                            "sabtu",
+                            "minggu"
+                        ]
+                    }
+                },
+                "open": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.PriceTier": {
+            "type": "object",
+            "properties": {
+                "group_id": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.Product": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "buy_price": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "defective": {
+                    "type": "integer"
+                },
+                "min_retail_price": {
+                    "type": "integer"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "product_code": {
+                    "type": "string"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "size_type_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.ProductVariant": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "buy_price": {
+                    "type": "integer"
+                },
+                "conversion": {
+                    "type": "integer"
+                },
+                "min_retail_price": {
+                    "type": "integer"
+                },
+                "size_type_id": {
+                    "type": "integer"
+                },
+                "sku": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Profile": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "business_name": {
+                    "type": "string"
+                },
+                "default_address": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "store_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RefreshData": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.ReviewRoleRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.SizeType": {
+            "type": "object",
+            "properties": {
+                "base_unit_id": {
+                    "type": "integer"
+                },
+                "factor": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.StockMovement": {
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "to_product_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.UploadRequest": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "purpose": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "target_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.UploadResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "integer"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "method": {
+                    "type": "string"
+                },
+                "upload_id": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Users": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKeys": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "owner_id": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "integer"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Profiles": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "business_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "default_address": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "store_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoleRequests": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "integer"
+                },
+                "reviewer": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.CategoryProducts": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.CategoryProducts"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "global": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "product": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.Product"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.Conversion": {
+            "type": "object",
+            "properties": {
+                "base_quantity": {
+                    "type": "integer"
+                },
+                "exact": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "$ref": "#/definitions/products.SizeType"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "result": {
+                    "type": "number"
+                },
+                "to": {
+                    "$ref": "#/definitions/products.SizeType"
+                }
+            }
+        },
+        "products.CustomerGroupMembers": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.CustomerGroups": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "grocery_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "products.ImportError": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.ImportJobs": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "created_rows": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.ImportError"
+                    }
+                },
+                "filename": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "integer"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "grocery_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "processed_rows": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "updated_rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.PriceTiers": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "group_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_quantity": {
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.Product": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "base_stock": {
+                    "type": "integer"
+                },
+                "buy_price": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "category_name": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "defective": {
+                    "type": "integer"
+                },
+                "highlight": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_retail_price": {
+                    "type": "integer"
+                },
+                "picture": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "product_code": {
+                    "type": "string"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "reserved_stock": {
+                    "type": "integer"
+                },
+                "size_type_id": {
+                    "type": "integer"
+                },
+                "size_type_name": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                }
             }
-        }
-    },
-    "definitions": {
-        "dtos.AuthCodeData": {
+        },
+        "products.ProductImages": {
             "type": "object",
             "properties": {
-                "code": {
+                "content_type": {
                     "type": "string"
                 },
-                "email": {
+                "created_at": {
+                    "type": "integer"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thumbnail_url": {
                     "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "dtos.AuthData": {
+        "products.ProductRevisions": {
             "type": "object",
             "properties": {
-                "email": {
+                "actor": {
                     "type": "string"
                 },
-                "password": {
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/products.RevisionChange"
+                    }
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "products.ProductVariants": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "buy_price": {
+                    "type": "integer"
+                },
+                "conversion": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_retail_price": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "size_type_id": {
+                    "type": "integer"
+                },
+                "size_type_name": {
+                    "type": "string"
+                },
+                "sku": {
                     "type": "string"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
                 }
             }
         },
-        "dtos.AuthResetData": {
+        "products.RevisionChange": {
             "type": "object",
             "properties": {
-                "code": {
+                "new": {},
+                "old": {}
+            }
+        },
+        "products.SizeType": {
+            "type": "object",
+            "properties": {
+                "base_unit_id": {
+                    "type": "integer"
+                },
+                "base_unit_name": {
                     "type": "string"
                 },
-                "email": {
+                "factor": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "password": {
+                "product": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/products.Product"
+                    }
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "dtos.Carts": {
+        "products.StockMovements": {
             "type": "object",
             "properties": {
-                "comments": {
+                "actor": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
                     "type": "string"
                 },
                 "product_id": {
@@ -1818,118 +6030,228 @@ const docTemplate = `{
                 "quantity": {
                     "type": "integer"
                 },
-                "size_type_id": {
+                "reason": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "type": "integer"
+                },
+                "variant_quantity": {
                     "type": "integer"
                 }
             }
         },
-        "dtos.CategoryProducts": {
+        "products.StockShortage": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
+                "available": {
+                    "type": "integer"
                 },
-                "name": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
                     "type": "string"
+                },
+                "requested": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
                 }
             }
         },
-        "dtos.MessagesResponses": {
+        "products.TrashItems": {
             "type": "object",
             "properties": {
-                "message": {
+                "deleted_at": {
+                    "type": "integer"
+                },
+                "global": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "dtos.Operate": {
+        "transactions.OrderConfirmations": {
             "type": "object",
             "properties": {
-                "active": {
-                    "type": "boolean"
+                "confirmation_date": {
+                    "type": "integer"
                 },
-                "close": {
+                "groceries_id": {
                     "type": "string"
                 },
-                "day_operational": {
-                    "type": "array",
-                    "items": {
-                        "type": "string",
-                        "enum": [
-                            "senin",
-                            "selasa",
-                            "rabu",
-                            "kamis",
-                            "jum'at",
-                            "sabtu",
-                            "minggu"
-                        ]
-                    }
+                "id": {
+                    "type": "integer"
                 },
-                "open": {
+                "lines": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "order_status": {
                     "type": "string"
                 }
             }
         },
-        "dtos.Product": {
+        "transactions.OrderItems": {
             "type": "object",
             "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "buy_price": {
+                "base_price": {
                     "type": "integer"
                 },
-                "category_id": {
+                "id": {
                     "type": "integer"
                 },
-                "defective": {
+                "order_id": {
                     "type": "integer"
                 },
-                "min_retail_price": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
                     "type": "integer"
                 },
-                "picture": {
+                "product_name": {
                     "type": "string"
                 },
-                "position": {
-                    "type": "string"
+                "quantity": {
+                    "type": "integer"
                 },
-                "product_code": {
+                "size_type_name": {
                     "type": "string"
                 },
-                "product_name": {
+                "unit_price": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transactions.Orders": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
                     "type": "string"
                 },
-                "quantity": {
+                "expires_at": {
                     "type": "integer"
                 },
-                "size_type_id": {
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transactions.OrderItems"
+                    }
+                },
+                "order_date": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_amount": {
                     "type": "integer"
                 }
             }
         },
-        "dtos.SizeType": {
+        "transactions.PaymentProofs": {
             "type": "object",
             "properties": {
-                "name": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "integer"
+                },
+                "customer_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "dtos.UpdateGroup": {
+        "transactions.PriceBreakdown": {
             "type": "object",
             "properties": {
-                "groups": {
-                    "type": "string"
+                "discount": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transactions.PriceLine"
+                    }
+                },
+                "total_amount": {
+                    "type": "number"
                 }
             }
         },
-        "dtos.Users": {
+        "transactions.PriceLine": {
             "type": "object",
             "properties": {
-                "email": {
+                "base_price": {
+                    "type": "integer"
+                },
+                "cart_id": {
+                    "type": "integer"
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "size_type_name": {
                     "type": "string"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "tier_id": {
+                    "type": "integer"
+                },
+                "unit_price": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "type": "integer"
                 }
             }
         }
@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type Users struct {
 	Username string `json:"email"`
 }
@@ -27,6 +29,57 @@ type AuthResetData struct {
 	CodeData
 }
 
-type UpdateGroup struct {
-	Groups string `json:"groups"`
+type RoleRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type ReviewRoleRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+type RefreshData struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LoginResponse struct {
+	IdToken      string `json:"idToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	SessionID    string `json:"sessionId"`
+	ExpiresIn    int32  `json:"expiresIn"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+type LockoutResponse struct {
+	Action     string `json:"action"`
+	Failures   int64  `json:"failures"`
+	Locked     bool   `json:"locked"`
+	RetryAfter int64  `json:"retry_after"`
+}
+
+type APIKeyData struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// APIKeyResponse carries the plain key, which is not stored and can not be
+// shown again.
+type APIKeyResponse struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Key       string   `json:"key"`
+	Prefix    string   `json:"prefix"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt *int64   `json:"expires_at,omitempty"`
+	CreatedAt int64    `json:"created_at"`
 }
@@ -2,6 +2,7 @@ package dtos
 
 type Carts struct {
 	ProductID  int64  `json:"product_id"`
+	VariantID  int64  `json:"variant_id"`
 	Quantity   int32  `json:"quantity"`
 	SizeTypeID int8   `json:"size_type_id"`
 	Comments   string `json:"comments"`
@@ -3,4 +3,6 @@ package dtos
 type CategoryProducts struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
+	ParentID    *int   `json:"parent_id,omitempty"`
+	Global      bool   `json:"global,omitempty"`
 }
@@ -5,7 +5,7 @@ type Product struct {
 	ProductName string `json:"product_name,omitempty"`
 	Picture     string `json:"picture,omitempty"`
 	Position    string `json:"position"`
-	Quantity    int16  `json:"quantity,omitempty"`
+	Quantity    int64  `json:"quantity,omitempty"`
 	SizeTypeId  int    `json:"size_type_id,omitempty"`
 	CategoryId  int    `json:"category_id,omitempty"`
 	BuyPrice    int32  `json:"buy_price,omitempty"`
@@ -13,3 +13,40 @@ type Product struct {
 	Defective   int32  `json:"defective,omitempty"`
 	Active      bool   `json:"active"`
 }
+
+type ImageOrder struct {
+	IDs []int64 `json:"ids"`
+}
+
+type ProductVariant struct {
+	SizeTypeID int    `json:"size_type_id"`
+	SKU        string `json:"sku"`
+	Barcode    string `json:"barcode"`
+	BuyPrice   int32  `json:"buy_price"`
+	MRP        int32  `json:"min_retail_price"`
+	Conversion int32  `json:"conversion"`
+	Active     bool   `json:"active"`
+}
+
+type StockMovement struct {
+	Kind        string `json:"kind"`
+	VariantID   int64  `json:"variant_id,omitempty"`
+	Quantity    int64  `json:"quantity"`
+	Reason      string `json:"reason"`
+	Reference   string `json:"reference"`
+	ToProductID int64  `json:"to_product_id,omitempty"`
+}
+
+type PriceTier struct {
+	GroupID     *int64 `json:"group_id,omitempty"`
+	MinQuantity int32  `json:"min_quantity"`
+	UnitPrice   int32  `json:"unit_price"`
+}
+
+type CustomerGroup struct {
+	Name string `json:"name"`
+}
+
+type GroupMember struct {
+	CustomerID string `json:"customer_id"`
+}
@@ -0,0 +1,10 @@
+package dtos
+
+type Profile struct {
+	DisplayName    string `json:"display_name,omitempty"`
+	Phone          string `json:"phone,omitempty"`
+	BusinessName   string `json:"business_name,omitempty"`
+	StoreName      string `json:"store_name,omitempty"`
+	Avatar         string `json:"avatar,omitempty"`
+	DefaultAddress string `json:"default_address,omitempty"`
+}
@@ -1,5 +1,7 @@
 package dtos
 
 type SizeType struct {
-	Name string `json:"name"`
+	Name       string `json:"name"`
+	BaseUnitID *int   `json:"base_unit_id,omitempty"`
+	Factor     int32  `json:"factor,omitempty"`
 }
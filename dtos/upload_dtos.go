@@ -0,0 +1,16 @@
+package dtos
+
+type UploadRequest struct {
+	Purpose     string `json:"purpose"`
+	TargetID    int64  `json:"target_id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type UploadResponse struct {
+	UploadID  string            `json:"upload_id"`
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt int64             `json:"expires_at"`
+}
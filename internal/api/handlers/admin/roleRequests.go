@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/pkg/aws"
+)
+
+// @Summary List role requests process
+// @Description do list role requests, pending ones by default
+// @Tags admin
+// @Produce json
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {array} models.RoleRequests "role requests"
+// @Router /admin/role-requests [get]
+// @Security Bearer
+func GetRoleRequests(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request models.RoleRequests
+
+		result, err := request.GetAll("", ctx.DefaultQuery("status", models.RoleRequestPending), db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"requests": result})
+	}
+}
+
+// pendingRequest loads the role request of the id param, writing the
+// response when it can not be reviewed.
+func pendingRequest(ctx *gin.Context, db *sql.DB) (*models.RoleRequests, bool) {
+	var request models.RoleRequests
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	result, err := request.Get(id, db)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if result.Status != models.RoleRequestPending {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "permintaan role sudah diproses"})
+		return nil, false
+	}
+
+	return result, true
+}
+
+// reviewNote reads the optional review body.
+func reviewNote(ctx *gin.Context) (string, bool) {
+	var reviewData dtos.ReviewRoleRequest
+
+	if ctx.Request.ContentLength == 0 {
+		return "", true
+	}
+
+	if err := ctx.ShouldBindJSON(&reviewData); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	return reviewData.Note, true
+}
+
+func review(ctx *gin.Context, db *sql.DB, request *models.RoleRequests, status, note string) {
+	result, err := request.Review(request.ID, status, middleware.GetPrincipal(ctx).Username, note, db)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "permintaan role sudah diproses"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"request": result})
+}
+
+// @Summary Approve role request process
+// @Description do approve a role request and add the user to the group
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path integer true "role request id"
+// @Param review body dtos.ReviewRoleRequest false "review note"
+// @Success 200 {object} models.RoleRequests "approved request"
+// @Failure 409 {string} string "request already reviewed"
+// @Router /admin/role-requests/{id}/approve [post]
+// @Security Bearer
+func ApproveRoleRequest(db *sql.DB, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		note, ok := reviewNote(ctx)
+		if !ok {
+			return
+		}
+
+		request, ok := pendingRequest(ctx, db)
+		if !ok {
+			return
+		}
+
+		if err := conn.Cognito.AddUserToGroup(ctx, request.UserID, request.Role); err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		review(ctx, db, request, models.RoleRequestApproved, note)
+	}
+}
+
+// @Summary Reject role request process
+// @Description do reject a role request
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path integer true "role request id"
+// @Param review body dtos.ReviewRoleRequest false "review note"
+// @Success 200 {object} models.RoleRequests "rejected request"
+// @Failure 409 {string} string "request already reviewed"
+// @Router /admin/role-requests/{id}/reject [post]
+// @Security Bearer
+func RejectRoleRequest(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		note, ok := reviewNote(ctx)
+		if !ok {
+			return
+		}
+
+		request, ok := pendingRequest(ctx, db)
+		if !ok {
+			return
+		}
+
+		review(ctx, db, request, models.RoleRequestRejected, note)
+	}
+}
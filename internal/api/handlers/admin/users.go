@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/cognito"
+)
+
+// cognito returns at most 60 users per ListUsers call
+const maxUsers = 60
+
+func userErrorStatus(err error) int {
+	if errors.Is(err, cognito.ErrUserNotFound) || errors.Is(err, cognito.ErrGroupNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// @Summary Search users process
+// @Description do search users by email prefix
+// @Tags admin
+// @Produce json
+// @Param email query string false "email prefix"
+// @Param limit query integer false "max users, up to 60"
+// @Success 200 {array} cognito.User "users"
+// @Failure 403 {string} string "permission denied"
+// @Router /admin/users [get]
+// @Security Bearer
+func SearchUsers(conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+		if err != nil || limit <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		if limit > maxUsers {
+			limit = maxUsers
+		}
+
+		users, err := conn.Cognito.ListUsers(ctx, ctx.Query("email"), int32(limit))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"users": users})
+	}
+}
+
+// @Summary Get user process
+// @Description do get a user with its groups
+// @Tags admin
+// @Produce json
+// @Param username path string true "username"
+// @Success 200 {object} cognito.User "user"
+// @Failure 404 {string} string "user not found"
+// @Router /admin/users/{username} [get]
+// @Security Bearer
+func GetUser(conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := ctx.Param("username")
+
+		user, err := conn.Cognito.LookupUser(ctx, username)
+		if err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		groups, err := conn.Cognito.CheckUserInGroup(ctx, username)
+		if err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"user":   user,
+			"groups": groups,
+		})
+	}
+}
+
+// @Summary Disable user process
+// @Description do disable an account, end all of its sessions and revoke its API keys
+// @Tags admin
+// @Produce json
+// @Param username path string true "username"
+// @Success 200 {object} dtos.MessagesResponses "user disabled"
+// @Failure 404 {string} string "user not found"
+// @Router /admin/users/{username}/disable [post]
+// @Security Bearer
+func DisableUser(db *sql.DB, sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := ctx.Param("username")
+
+		if username == middleware.GetPrincipal(ctx).Username {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "admin tidak dapat menonaktifkan akun sendiri"})
+			return
+		}
+
+		if err := conn.Cognito.DisableUser(ctx, username); err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := sessions.RevokeAll(ctx, username); err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		var key models.APIKeys
+		if _, err := key.RevokeAll(username, db); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("akun %s telah dinonaktifkan", username)})
+	}
+}
+
+// @Summary Enable user process
+// @Description do enable a disabled account
+// @Tags admin
+// @Produce json
+// @Param username path string true "username"
+// @Success 200 {object} dtos.MessagesResponses "user enabled"
+// @Failure 404 {string} string "user not found"
+// @Router /admin/users/{username}/enable [post]
+// @Security Bearer
+func EnableUser(conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := ctx.Param("username")
+
+		if err := conn.Cognito.EnableUser(ctx, username); err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("akun %s telah diaktifkan", username)})
+	}
+}
+
+// @Summary Remove user group process
+// @Description do remove a user from a group, leaving grosir also revokes the API keys of the user
+// @Tags admin
+// @Produce json
+// @Param username path string true "username"
+// @Param group path string true "group name"
+// @Success 200 {object} dtos.MessagesResponses "user removed from group"
+// @Failure 404 {string} string "user or group not found"
+// @Router /admin/users/{username}/groups/{group} [delete]
+// @Security Bearer
+func RemoveUserFromGroup(db *sql.DB, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := ctx.Param("username")
+		group := ctx.Param("group")
+
+		if err := conn.Cognito.RemoveUserFromGroup(ctx, username, group); err != nil {
+			ctx.JSON(userErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		// keys carry the scopes of a grosir and are not checked against groups
+		if group == middleware.RoleGrosir {
+			var key models.APIKeys
+			if _, err := key.RevokeAll(username, db); err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("pengguna %s telah dikeluarkan dari group %s", username, group)})
+	}
+}
@@ -2,79 +2,83 @@ package category
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
+// categoryStatus maps the errors of the category model to a response status.
+func categoryStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound), errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrInvalidParent):
+		return http.StatusBadRequest
+	case errors.Is(err, products.ErrCategoryHasChildren), errors.Is(err, products.ErrCategoryInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// categoryOwner returns the owner the category is changed as, empty for a
+// global category, which only a taxonomy manager may change.
+func categoryOwner(ctx *gin.Context, id int, db *sql.DB) (string, bool) {
+	principal := middleware.GetPrincipal(ctx)
+
+	var category products.CategoryProducts
+	result, err := category.Get(id, db)
+	if err != nil {
+		ctx.JSON(categoryStatus(err), gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	if !result.Global {
+		return principal.Username, true
+	}
+	if !principal.Can(middleware.PermTaxonomyManage) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
+		return "", false
+	}
+	return "", true
+}
+
 // @Summary create category process
-// @Description do create category
+// @Description do create category under parent_id, up to 5 levels deep. A global category is part of the
+// @Description taxonomy shared by every grocery and needs the taxonomy:manage permission.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param category body dtos.CategoryProducts true "create category"
 // @Success 200 {object} dtos.MessagesResponses "create category successfully"
 // @Failure 400 {string} string "Error Bad request"
+// @Failure 403 {string} string "global category without taxonomy:manage"
 // @Router /products/category [post]
 // @Security Bearer
 func Create(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var category products.CategoryProducts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
 		if err := ctx.ShouldBindJSON(&category); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
-
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-
-		targetValue := []string{
-			"grosir",
-		}
+		principal := middleware.GetPrincipal(ctx)
 
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		if category.Global && !principal.Can(middleware.PermTaxonomyManage) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
 			return
 		}
 
-		if err := category.Insert(db, *output.Username); err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := category.Insert(db, principal.Username); err != nil {
+			ctx.JSON(categoryStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -85,37 +89,49 @@ func Create(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary GetAll category process
-// @Description do get all category
+// @Description do get a page of the global categories and the private ones of the grocery
 // @Tags products
 // @Accept json
 // @Produce json
+// @Param grocery_id query string false "user id of the grocery, the caller by default"
+// @Param parent_id query int false "only the subcategories of the category"
+// @Param sort query string false "name or id"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Success 200 {object} dtos.MessagesResponses "get all category"
-// @Failure 400 {string} string "cookies not found"
+// @Failure 400 {string} string "invalid query"
 // @Router /products/category [get]
 // @Security Bearer
 func GetAll(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var category products.CategoryProducts
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
 
-		token := splitted[1]
+		params, err := helpers.ListParams(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		_, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		parentId, err := helpers.QueryInt(ctx, "parent_id")
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := category.GetAll(db)
+		groceryId := ctx.Query("grocery_id")
+		if groceryId == "" {
+			groceryId = middleware.GetPrincipal(ctx).Username
+		}
+
+		result, page, err := category.GetAll(groceryId, parentId, params, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"category": result})
+		ctx.JSON(http.StatusOK, gin.H{"category": result, "page": page})
 	}
 }
 
@@ -140,18 +156,6 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		_, err = aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
 
 		result, err := category.Get(id, db)
 		if err != nil {
@@ -166,7 +170,8 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary update category process
-// @Description do update category
+// @Description do update category, a new parent_id must not be the category or one of its subcategories.
+// @Description A global category needs the taxonomy:manage permission.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -174,24 +179,14 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 // @Param category body dtos.CategoryProducts true "update category"
 // @Success 200 {object} dtos.MessagesResponses "update category successfully"
 // @Failure 400 {string} string "Error Bad request"
+// @Failure 403 {string} string "global category without taxonomy:manage"
+// @Failure 404 {string} string "category not found"
 // @Router /products/category/{id} [put]
 // @Security Bearer
 func Update(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var category products.CategoryProducts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
-
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -207,37 +202,14 @@ func Update(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-
-		targetValue := []string{
-			"grosir",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		owner, ok := categoryOwner(ctx, id, db)
+		if !ok {
 			return
 		}
 
-		err = category.Update(id, *output.Username, db)
+		err = category.Update(id, owner, db)
 		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{
+			ctx.JSON(categoryStatus(err), gin.H{
 				"message": err.Error(),
 			})
 			return
@@ -250,77 +222,107 @@ func Update(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary DeleteID category process
-// @Description do delete a category
+// @Description do move a category without subcategories or products to the trash, a global category needs
+// @Description the taxonomy:manage permission
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path integer true "delete size with category"
 // @Success 200 {object} dtos.MessagesResponses "get all size"
 // @Failure 400 {string} string "cookie not found"
+// @Failure 403 {string} string "global category without taxonomy:manage"
+// @Failure 409 {string} string "category has subcategories or products"
 // @Router /products/category/{id} [delete]
 // @Security Bearer
 func Delete(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var category products.CategoryProducts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message": err.Error(),
 			})
 			return
 		}
 
-		id, err := strconv.Atoi(ctx.Params.ByName("id"))
+		owner, ok := categoryOwner(ctx, id, db)
+		if !ok {
+			return
+		}
+
+		err = category.Delete(id, owner, db)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{
+			ctx.JSON(categoryStatus(err), gin.H{
 				"message": err.Error(),
 			})
 			return
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("delete successfully id: %d", id),
+		})
+	}
+}
+
+// @Summary Tree category process
+// @Description do get the global categories and the private ones of the grocery as trees
+// @Tags products
+// @Produce json
+// @Param grocery_id query string false "user id of the grocery, the caller by default"
+// @Success 200 {array} products.CategoryProducts "category tree"
+// @Router /products/category/tree [get]
+// @Security Bearer
+func Tree(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var category products.CategoryProducts
+
+		groceryId := ctx.Query("grocery_id")
+		if groceryId == "" {
+			groceryId = middleware.GetPrincipal(ctx).Username
+		}
+
+		result, err := category.Tree(groceryId, db)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		targetValue := []string{
-			"grosir",
-		}
+		ctx.JSON(http.StatusOK, gin.H{"category": result})
+	}
+}
 
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
+// @Summary Breadcrumb category process
+// @Description do get the path from the root category to the category
+// @Tags products
+// @Produce json
+// @Param id path integer true "category id"
+// @Param grocery_id query string false "user id of the grocery, the caller by default"
+// @Success 200 {array} products.CategoryProducts "root first, the category last"
+// @Failure 404 {string} string "category not found"
+// @Router /products/category/{id}/breadcrumb [get]
+// @Security Bearer
+func Breadcrumb(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var category products.CategoryProducts
 
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		id, err := strconv.Atoi(ctx.Params.ByName("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		err = category.Delete(id, *output.Username, db)
+		groceryId := ctx.Query("grocery_id")
+		if groceryId == "" {
+			groceryId = middleware.GetPrincipal(ctx).Username
+		}
+
+		result, err := category.Breadcrumb(id, groceryId, db)
 		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"message": err.Error(),
-			})
+			ctx.JSON(categoryStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{
-			"message": fmt.Sprintf("delete successfully id: %d", id),
-		})
+		ctx.JSON(http.StatusOK, gin.H{"breadcrumb": result})
 	}
 }
@@ -3,14 +3,12 @@ package operationals
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary delete operational process
@@ -27,16 +25,7 @@ func DeleteID(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var operate models.Operationals
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -46,35 +35,7 @@ func DeleteID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-
-		targetValue := []string{
-			"grosir",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		err = operate.Delete(uint64(id), *output.Username, db)
+		err = operate.Delete(uint64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
 			return
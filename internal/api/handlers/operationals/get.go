@@ -4,11 +4,9 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"payuoge.com/internal/api/models"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary GetAllOperational access process
@@ -24,19 +22,6 @@ func GetAll(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var operate models.Operationals
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		_, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
-
 		result, err := operate.GetAll(db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
@@ -58,18 +43,6 @@ func GetAll(db *sql.DB) gin.HandlerFunc {
 func GetId(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var operate models.Operationals
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		_, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -3,14 +3,12 @@ package operationals
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary UpdateOperational access process
@@ -29,16 +27,7 @@ func Update(db *sql.DB) gin.HandlerFunc {
 		var operateData models.Operationals
 		var updateData models.Operationals
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -69,35 +58,7 @@ func Update(db *sql.DB) gin.HandlerFunc {
 			updateData.Active = true
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-
-		targetValue := []string{
-			"GROSIR_MANAGER", "GROSIR_OWNER",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		err = operateData.Update(uint64(id), *output.Username, db)
+		err = operateData.Update(uint64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"messsage": err.Error(),
@@ -2,13 +2,12 @@ package products
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
 // CreateProductHandler godoc
@@ -21,6 +20,7 @@ import (
 // @Param product body dtos.Product true "create a product"
 // @Success 200 {object} dtos.MessagesResponses "the message successfully create"
 // @Failure 400 {string} string "Error Bad Request"
+// @Failure 409 {string} string "product code already used by the grocery"
 // @Router /groceries/products [post]
 // @Security Bearer
 func Create(db *sql.DB) gin.HandlerFunc {
@@ -28,33 +28,37 @@ func Create(db *sql.DB) gin.HandlerFunc {
 
 		var product products.Product
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
+		principal := middleware.GetPrincipal(ctx)
 
-		token := splitted[1]
+		if err := ctx.ShouldBindJSON(&product); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+		if product.Quantity < 0 || product.Quantity > products.MaxOpeningQuantity {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": products.ErrOpeningQuantity.Error()})
 			return
 		}
 
-		if err := ctx.ShouldBindJSON(&product); err != nil {
+		code, err := products.CheckCode(product.ProductCode)
+		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		product.ProductCode = code
 
-		// check roles group
-		err = helpers.CheckAccountGroceries(output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		var category products.CategoryProducts
+		if err := category.Visible(product.CategoryId, principal.Username, db); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "category_id tidak ditemukan"})
 			return
 		}
 
-		if err := product.Insert(db, product.SizeTypeId, product.CategoryId, *output.Username); err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := product.Insert(db, product.SizeTypeId, product.CategoryId, principal.Username); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, products.ErrProductCodeExists) {
+				status = http.StatusConflict
+			}
+			ctx.JSON(status, gin.H{"error": err.Error()})
 			return
 		}
 
@@ -5,16 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary DeleteID product process
-// @Description do delete a product
+// @Description do move a product to the trash, it can be restored until the purge removes it with its images
 // @Tags groceries
 // @Accept json
 // @Produce json
@@ -29,16 +27,7 @@ func DeleteID(db *sql.DB) gin.HandlerFunc {
 		var product products.Product
 
 		// check authorized
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -48,14 +37,7 @@ func DeleteID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles group
-		err = helpers.CheckAccountGroceries(output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
-			return
-		}
-
-		err = product.Delete(int64(id), *output.Username, db)
+		err = product.Delete(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -2,82 +2,225 @@ package products
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
+// productFilter reads the filters of a product listing from the query.
+func productFilter(ctx *gin.Context) (products.ProductFilter, error) {
+	var filter products.ProductFilter
+	var err error
+
+	if filter.CategoryID, err = helpers.QueryInt(ctx, "category_id"); err != nil {
+		return filter, err
+	}
+	if filter.SizeTypeID, err = helpers.QueryInt(ctx, "size_type_id"); err != nil {
+		return filter, err
+	}
+	if filter.MinPrice, err = helpers.QueryInt(ctx, "min_price"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = helpers.QueryInt(ctx, "max_price"); err != nil {
+		return filter, err
+	}
+	if filter.Active, err = helpers.QueryBool(ctx, "active"); err != nil {
+		return filter, err
+	}
+	inStock, err := helpers.QueryBool(ctx, "in_stock")
+	if err != nil {
+		return filter, err
+	}
+	filter.InStock = inStock != nil && *inStock
+	filter.GroceryID = ctx.Query("grocery_id")
+
+	return filter, nil
+}
+
+func listProducts(ctx *gin.Context, db *sql.DB, filter products.ProductFilter) {
+	var product products.Product
+
+	params, err := helpers.ListParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, page, err := product.GetAll(filter, params, db)
+	if err != nil {
+		ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"products": result, "page": page})
+}
+
 // @Summary GetAll product process
-// @Description do get all product
+// @Description do get a page of products, sort by name, price, created or updated
 // @Tags products
 // @Accept json
 // @Produce json
+// @Param category_id query int false "category id, subcategories included"
+// @Param size_type_id query int false "size type id"
+// @Param grocery_id query string false "user id of the grocery"
+// @Param active query bool false "active products only, or inactive only"
+// @Param min_price query int false "lowest retail price"
+// @Param max_price query int false "highest retail price"
+// @Param in_stock query bool false "products with quantity left"
+// @Param sort query string false "name, price, created or updated"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Success 200 {object} dtos.MessagesResponses "get all category"
-// @Failure 400 {string} string "cookies not found"
+// @Failure 400 {string} string "invalid query"
 // @Router /products [get]
 // @Security Bearer
 func GetAll(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var product products.Product
+		filter, err := productFilter(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
+		listProducts(ctx, db, filter)
+	}
+}
 
-		token := splitted[1]
+// @Summary GetAll product groceries process
+// @Description do get a page of the products of the current grocery
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param category_id query int false "category id, subcategories included"
+// @Param size_type_id query int false "size type id"
+// @Param active query bool false "active products only, or inactive only"
+// @Param min_price query int false "lowest retail price"
+// @Param max_price query int false "highest retail price"
+// @Param in_stock query bool false "products with quantity left"
+// @Param sort query string false "name, price, created or updated"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Router /groceries/products [get]
+// @Security Bearer
+func GetProductsGrocery(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, err := productFilter(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.GroceryID = middleware.GetPrincipal(ctx).Username
 
-		_, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		listProducts(ctx, db, filter)
+	}
+}
+
+// @Summary Get product by code process
+// @Description do resolve a scanned product code or variant barcode of the current grocery to its product
+// @Tags groceries
+// @Produce json
+// @Param code path string true "product code or variant barcode"
+// @Success 200 {object} products.Product "product, with the variant when the code is a variant barcode"
+// @Failure 404 {string} string "no product with the code"
+// @Router /groceries/products/by-code/{code} [get]
+// @Security Bearer
+func GetByCode(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		productId, variantId, err := products.FindCode(principal.Username, ctx.Param("code"), db)
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+			status := http.StatusInternalServerError
+			if errors.Is(err, models.ErrRecordNotFound) {
+				status = http.StatusNotFound
+			}
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		var product products.Product
+		result, err := product.Get(productId, db)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, models.ErrRecordNotFound) {
+				status = http.StatusNotFound
+			}
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		if variantId == nil {
+			ctx.JSON(http.StatusOK, gin.H{"product": result})
 			return
 		}
 
-		result, err := product.GetAll(db)
+		var variant products.ProductVariants
+		matched, err := variant.Get(*variantId, productId, db)
 		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"products": result})
+		ctx.JSON(http.StatusOK, gin.H{"product": result, "variant": matched})
 	}
 }
 
-// @Summary GetAll product groceries process
-// @Description do get all product
-// @Tags groceries
+// @Summary Search product process
+// @Description do search products by name, code and category, best match first
+// @Tags products
 // @Accept json
 // @Produce json
-// @Router /groceries/products [get]
+// @Param q query string true "search text, words in any order"
+// @Param category_id query int false "category id, subcategories included"
+// @Param size_type_id query int false "size type id"
+// @Param grocery_id query string false "user id of the grocery"
+// @Param active query bool false "active products only, or inactive only"
+// @Param min_price query int false "lowest retail price"
+// @Param max_price query int false "highest retail price"
+// @Param in_stock query bool false "products with quantity left"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Success 200 {object} dtos.MessagesResponses "matching products"
+// @Failure 400 {string} string "invalid query"
+// @Router /products/search [get]
 // @Security Bearer
-func GetProductsGrocery(db *sql.DB) gin.HandlerFunc {
+func Search(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var product products.Product
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
+		filter, err := productFilter(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		params, err := helpers.ListParams(ctx)
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := product.GetProductsGroceries(*output.Username, db)
+		result, page, err := product.Search(ctx.Query("q"), filter, params, db)
 		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			if errors.Is(err, products.ErrEmptySearch) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"products": result})
+		ctx.JSON(http.StatusOK, gin.H{"products": result, "page": page})
 	}
 }
 
@@ -101,19 +244,6 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		_, err = aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
-
 		result, err := product.Get(int64(id), db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
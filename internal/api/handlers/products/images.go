@@ -0,0 +1,303 @@
+package products
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/configs"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+	"payuoge.com/pkg/storage"
+)
+
+// multipartOverhead is allowed on top of the files of an upload for the
+// multipart boundaries and headers.
+const multipartOverhead = 1 << 20
+
+// ownProduct returns the id of the product in the path when it belongs to the
+// current grocery, otherwise it answers the request and returns false.
+func ownProduct(ctx *gin.Context, db *sql.DB) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+		return 0, false
+	}
+
+	var product products.Product
+	result, err := product.Get(id, db)
+	if err != nil || result.UserID != middleware.GetPrincipal(ctx).Username {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": models.ErrRecordNotFound.Error()})
+		return 0, false
+	}
+
+	return id, true
+}
+
+// deleteObjects removes objects left by a removed image or product, a failure
+// only leaves an orphan object behind.
+func deleteObjects(ctx *gin.Context, store storage.ObjectStore, keys ...string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("delete object %s: %v", key, err)
+		}
+	}
+}
+
+// @Summary Upload product images process
+// @Description do upload jpeg, png or gif images of a product, appended after its current images
+// @Tags groceries
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path integer true "product id"
+// @Param images formData file true "one or more images"
+// @Success 201 {array} products.ProductImages "uploaded images"
+// @Failure 400 {string} string "invalid image"
+// @Failure 404 {string} string "product not found or in the trash"
+// @Failure 413 {string} string "image too large"
+// @Router /groceries/products/{id}/images [post]
+// @Security Bearer
+func UploadImages(db *sql.DB, store storage.ObjectStore, conf configs.StorageConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		var image products.ProductImages
+		current, err := image.GetAll(productId, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		room := conf.MaxImages - len(current)
+		if room <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("produk sudah memiliki %d gambar", conf.MaxImages)})
+			return
+		}
+
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, conf.MaxImageSize*int64(room)+multipartOverhead)
+
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "upload terlalu besar atau tidak valid"})
+			return
+		}
+
+		files := form.File["images"]
+		if len(files) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "images wajib diisi"})
+			return
+		}
+		if len(files) > room {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("maksimal %d gambar lagi untuk produk ini", room)})
+			return
+		}
+
+		// every file is checked before anything is stored
+		processed := make([]*storage.Image, len(files))
+		for i, header := range files {
+			if header.Size > conf.MaxImageSize {
+				ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s melebihi %d byte", header.Filename, conf.MaxImageSize)})
+				return
+			}
+
+			file, err := header.Open()
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			data, err := io.ReadAll(io.LimitReader(file, conf.MaxImageSize))
+			file.Close()
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			processed[i], err = storage.ProcessImage(data, conf.ThumbnailWidth)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %s", header.Filename, err.Error())})
+				return
+			}
+		}
+
+		uploaded := make([]products.ProductImages, 0, len(processed))
+		for _, img := range processed {
+			result, err := storeImage(ctx, db, store, productId, img, conf.MaxImages)
+			if errors.Is(err, products.ErrTooManyImages) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "images": uploaded})
+				return
+			}
+			if errors.Is(err, models.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "images": uploaded})
+				return
+			}
+			if err != nil {
+				log.Println(err.Error())
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "images": uploaded})
+				return
+			}
+			uploaded = append(uploaded, *result)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"images": uploaded})
+	}
+}
+
+// storeImage puts the image and its thumbnail in the store and records them
+// while the product has less than maxImages images, the objects are removed
+// again when the record fails.
+func storeImage(ctx *gin.Context, db *sql.DB, store storage.ObjectStore, productId int64, img *storage.Image, maxImages int) (*products.ProductImages, error) {
+	name, err := storage.NewName()
+	if err != nil {
+		return nil, err
+	}
+
+	image := products.ProductImages{
+		ProductID:    productId,
+		ObjectKey:    fmt.Sprintf("products/%d/%s%s", productId, name, img.Ext),
+		ThumbnailKey: fmt.Sprintf("products/%d/%s_thumb%s", productId, name, img.ThumbnailExt),
+		ContentType:  img.ContentType,
+		Size:         int64(len(img.Data)),
+		Width:        img.Width,
+		Height:       img.Height,
+	}
+
+	image.URL, err = store.Put(ctx, image.ObjectKey, img.ContentType, bytes.NewReader(img.Data))
+	if err != nil {
+		return nil, err
+	}
+
+	image.ThumbnailURL, err = store.Put(ctx, image.ThumbnailKey, img.ThumbnailType, bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		deleteObjects(ctx, store, image.ObjectKey)
+		return nil, err
+	}
+
+	if err := image.Insert(maxImages, db); err != nil {
+		deleteObjects(ctx, store, image.ObjectKey, image.ThumbnailKey)
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// @Summary List product images process
+// @Description do list the images of a product in display order
+// @Tags products
+// @Produce json
+// @Param id path integer true "product id"
+// @Success 200 {array} products.ProductImages "images of the product"
+// @Router /product/{id}/images [get]
+// @Security Bearer
+func ListImages(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
+		}
+
+		var image products.ProductImages
+		result, err := image.GetAll(id, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"images": result})
+	}
+}
+
+// @Summary Reorder product images process
+// @Description do set the display order of the images of a product, the first one is the cover
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param id path integer true "product id"
+// @Param order body dtos.ImageOrder true "every image id of the product in the new order"
+// @Success 200 {array} products.ProductImages "images in the new order"
+// @Failure 400 {string} string "invalid order"
+// @Router /groceries/products/{id}/images/order [put]
+// @Security Bearer
+func ReorderImages(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		var order dtos.ImageOrder
+		if err := ctx.ShouldBindJSON(&order); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var image products.ProductImages
+		if err := image.Reorder(productId, order.IDs, db); err != nil {
+			if errors.Is(err, products.ErrImageOrder) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := image.GetAll(productId, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"images": result})
+	}
+}
+
+// @Summary Delete product image process
+// @Description do delete an image of a product
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param imageId path integer true "image id"
+// @Success 200 {object} dtos.MessagesResponses "image deleted"
+// @Failure 404 {string} string "image not found"
+// @Router /groceries/products/{id}/images/{imageId} [delete]
+// @Security Bearer
+func DeleteImage(db *sql.DB, store storage.ObjectStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		imageId, err := strconv.ParseInt(ctx.Param("imageId"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "imageId tidak valid"})
+			return
+		}
+
+		var image products.ProductImages
+		deleted, err := image.Delete(imageId, productId, db)
+		if err != nil {
+			if errors.Is(err, models.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		deleteObjects(ctx, store, deleted.ObjectKey, deleted.ThumbnailKey)
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("delete image successfully id:%d", imageId)})
+	}
+}
@@ -0,0 +1,186 @@
+package products
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/configs"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+	"payuoge.com/pkg/spreadsheet"
+)
+
+// @Summary Import products process
+// @Description do import products from a csv or xlsx file in the background, upserting them by product_code.
+// @Description The header names the columns product_code, product_name, category, size_type, buy_price,
+// @Description min_retail_price, quantity, position and active, mapping renames them. A dry run only validates.
+// @Tags groceries
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "csv or xlsx file"
+// @Param mapping formData string false "json object of column to header, such as {\"product_code\":\"Kode\"}"
+// @Param dry_run formData boolean false "only validate the rows"
+// @Success 202 {object} products.ImportJobs "started import"
+// @Failure 400 {string} string "unreadable file or header"
+// @Failure 413 {string} string "file too large"
+// @Router /groceries/products/import [post]
+// @Security Bearer
+func ImportProducts(db *sql.DB, conf configs.ImportConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, conf.MaxFileSize+multipartOverhead)
+
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "file wajib diisi"})
+			return
+		}
+		if header.Size > conf.MaxFileSize {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s melebihi %d byte", header.Filename, conf.MaxFileSize)})
+			return
+		}
+
+		format, err := spreadsheet.Format(header.Filename)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var mapping map[string]string
+		if value := ctx.PostForm("mapping"); value != "" {
+			if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "mapping harus berupa json object"})
+				return
+			}
+		}
+
+		dryRun := false
+		if value := ctx.PostForm("dry_run"); value != "" {
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run harus true atau false"})
+				return
+			}
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(file, conf.MaxFileSize))
+		file.Close()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows, err := spreadsheet.Read(data, format)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(rows)-1 > conf.MaxRows {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file melebihi %d baris", conf.MaxRows)})
+			return
+		}
+
+		job := products.ImportJobs{
+			GroceryID: principal.Username,
+			Filename:  header.Filename,
+			Format:    format,
+			DryRun:    dryRun,
+		}
+		parsed, err := job.Parse(rows, mapping)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := job.Insert(db); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"job": job})
+
+		// the job outlives the request, its progress is read back by id
+		go job.Run(parsed, db)
+	}
+}
+
+// @Summary Get product import process
+// @Description do get the progress and the row errors of a product import
+// @Tags groceries
+// @Produce json
+// @Param jobId path integer true "import job id"
+// @Success 200 {object} products.ImportJobs "import job"
+// @Failure 404 {string} string "import job not found"
+// @Router /groceries/products/import/{jobId} [get]
+// @Security Bearer
+func GetImportJob(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		id, err := strconv.ParseInt(ctx.Param("jobId"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "jobId tidak valid"})
+			return
+		}
+
+		var job products.ImportJobs
+		result, err := job.Get(id, principal.Username, db)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, models.ErrRecordNotFound) {
+				status = http.StatusNotFound
+			}
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"job": result})
+	}
+}
+
+// @Summary Export products process
+// @Description do download the products of the grocery in the format of an import
+// @Tags groceries
+// @Produce octet-stream
+// @Param format query string false "csv or xlsx, csv by default"
+// @Success 200 {file} file "products"
+// @Failure 400 {string} string "unknown format"
+// @Router /groceries/products/export [get]
+// @Security Bearer
+func ExportProducts(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		format, err := spreadsheet.Format("products." + ctx.DefaultQuery("format", spreadsheet.CSV))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows, err := products.ExportRows(principal.Username, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.Header("Content-Type", spreadsheet.ContentTypes[format])
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+		ctx.Status(http.StatusOK)
+		if err := spreadsheet.Write(ctx.Writer, format, rows); err != nil {
+			log.Println(err.Error())
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package products
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+func pricingStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrCustomerGroupExists), errors.Is(err, products.ErrPriceTierExists):
+		return http.StatusConflict
+	case errors.Is(err, products.ErrInvalidGroup):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// ownVariant returns the variant in the path when its product belongs to the
+// current grocery, otherwise it answers the request and returns false.
+func ownVariant(ctx *gin.Context, db *sql.DB) (int64, bool) {
+	productId, ok := ownProduct(ctx, db)
+	if !ok {
+		return 0, false
+	}
+
+	variantId, err := strconv.ParseInt(ctx.Param("variantId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "variantId tidak valid"})
+		return 0, false
+	}
+
+	var variant products.ProductVariants
+	if _, err := variant.Get(variantId, productId, db); err != nil {
+		ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+		return 0, false
+	}
+
+	return variantId, true
+}
+
+func groupID(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("groupId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "groupId tidak valid"})
+		return 0, false
+	}
+	return id, true
+}
+
+// @Summary Create price tier process
+// @Description do add a unit price of a variant from a minimum quantity, for every customer or a customer group
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variantId path integer true "variant id"
+// @Param tier body dtos.PriceTier true "price tier"
+// @Success 201 {object} products.PriceTiers "created tier"
+// @Failure 409 {string} string "tier already exists"
+// @Router /groceries/products/{id}/variants/{variantId}/tiers [post]
+// @Security Bearer
+func CreatePriceTier(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		variantId, ok := ownVariant(ctx, db)
+		if !ok {
+			return
+		}
+
+		var data dtos.PriceTier
+		if err := ctx.ShouldBindJSON(&data); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if data.MinQuantity <= 0 || data.UnitPrice < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "min_quantity harus lebih dari 0 dan unit_price tidak boleh negatif"})
+			return
+		}
+
+		tier := products.PriceTiers{
+			VariantID:   variantId,
+			GroupID:     data.GroupID,
+			MinQuantity: data.MinQuantity,
+			UnitPrice:   data.UnitPrice,
+		}
+		if err := tier.Insert(middleware.GetPrincipal(ctx).Username, db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"tier": tier})
+	}
+}
+
+// @Summary List price tiers process
+// @Description do list the price tiers of a variant
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variantId path integer true "variant id"
+// @Success 200 {array} products.PriceTiers "tiers of the variant"
+// @Router /groceries/products/{id}/variants/{variantId}/tiers [get]
+// @Security Bearer
+func ListPriceTiers(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		variantId, ok := ownVariant(ctx, db)
+		if !ok {
+			return
+		}
+
+		var tier products.PriceTiers
+		result, err := tier.GetAll(variantId, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"tiers": result})
+	}
+}
+
+// @Summary Delete price tier process
+// @Description do delete a price tier of a variant
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variantId path integer true "variant id"
+// @Param tierId path integer true "tier id"
+// @Success 200 {object} dtos.MessagesResponses "tier deleted"
+// @Failure 404 {string} string "tier not found"
+// @Router /groceries/products/{id}/variants/{variantId}/tiers/{tierId} [delete]
+// @Security Bearer
+func DeletePriceTier(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		variantId, ok := ownVariant(ctx, db)
+		if !ok {
+			return
+		}
+
+		tierId, err := strconv.ParseInt(ctx.Param("tierId"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "tierId tidak valid"})
+			return
+		}
+
+		var tier products.PriceTiers
+		if err := tier.Delete(tierId, variantId, middleware.GetPrincipal(ctx).Username, db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("delete tier successfully id:%d", tierId)})
+	}
+}
+
+// @Summary Create customer group process
+// @Description do create a customer group, a price list for some retailers of the grocery
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param group body dtos.CustomerGroup true "name of the group"
+// @Success 201 {object} products.CustomerGroups "created group"
+// @Failure 409 {string} string "group already exists"
+// @Router /groceries/customer-groups [post]
+// @Security Bearer
+func CreateCustomerGroup(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var data dtos.CustomerGroup
+		if err := ctx.ShouldBindJSON(&data); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.TrimSpace(data.Name) == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "name wajib diisi"})
+			return
+		}
+
+		group := products.CustomerGroups{
+			GroceryID: middleware.GetPrincipal(ctx).Username,
+			Name:      strings.TrimSpace(data.Name),
+		}
+		if err := group.Insert(db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"group": group})
+	}
+}
+
+// @Summary List customer groups process
+// @Description do list the customer groups of the grocery
+// @Tags groceries
+// @Produce json
+// @Success 200 {array} products.CustomerGroups "groups of the grocery"
+// @Router /groceries/customer-groups [get]
+// @Security Bearer
+func ListCustomerGroups(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var group products.CustomerGroups
+		result, err := group.GetAll(middleware.GetPrincipal(ctx).Username, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"groups": result})
+	}
+}
+
+// @Summary Delete customer group process
+// @Description do delete a customer group with its members and price tiers
+// @Tags groceries
+// @Produce json
+// @Param groupId path integer true "group id"
+// @Success 200 {object} dtos.MessagesResponses "group deleted"
+// @Failure 404 {string} string "group not found"
+// @Router /groceries/customer-groups/{groupId} [delete]
+// @Security Bearer
+func DeleteCustomerGroup(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := groupID(ctx)
+		if !ok {
+			return
+		}
+
+		var group products.CustomerGroups
+		if err := group.Delete(id, middleware.GetPrincipal(ctx).Username, db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("delete group successfully id:%d", id)})
+	}
+}
+
+// @Summary List customer group members process
+// @Description do list the customers of a customer group
+// @Tags groceries
+// @Produce json
+// @Param groupId path integer true "group id"
+// @Success 200 {array} products.CustomerGroupMembers "members of the group"
+// @Router /groceries/customer-groups/{groupId}/members [get]
+// @Security Bearer
+func ListGroupMembers(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := groupID(ctx)
+		if !ok {
+			return
+		}
+
+		var group products.CustomerGroups
+		result, err := group.GetMembers(id, middleware.GetPrincipal(ctx).Username, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"members": result})
+	}
+}
+
+// @Summary Add customer group member process
+// @Description do add a customer to a customer group
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param groupId path integer true "group id"
+// @Param member body dtos.GroupMember true "customer to add"
+// @Success 200 {object} dtos.MessagesResponses "member added"
+// @Failure 404 {string} string "group not found"
+// @Router /groceries/customer-groups/{groupId}/members [post]
+// @Security Bearer
+func AddGroupMember(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := groupID(ctx)
+		if !ok {
+			return
+		}
+
+		var data dtos.GroupMember
+		if err := ctx.ShouldBindJSON(&data); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.TrimSpace(data.CustomerID) == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "customer_id wajib diisi"})
+			return
+		}
+
+		var group products.CustomerGroups
+		if err := group.AddMember(id, middleware.GetPrincipal(ctx).Username, data.CustomerID, db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("add member successfully %s", data.CustomerID)})
+	}
+}
+
+// @Summary Remove customer group member process
+// @Description do remove a customer from a customer group
+// @Tags groceries
+// @Produce json
+// @Param groupId path integer true "group id"
+// @Param customerId path string true "customer id"
+// @Success 200 {object} dtos.MessagesResponses "member removed"
+// @Failure 404 {string} string "member not found"
+// @Router /groceries/customer-groups/{groupId}/members/{customerId} [delete]
+// @Security Bearer
+func RemoveGroupMember(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := groupID(ctx)
+		if !ok {
+			return
+		}
+
+		customerId := ctx.Param("customerId")
+
+		var group products.CustomerGroups
+		if err := group.RemoveMember(id, middleware.GetPrincipal(ctx).Username, customerId, db); err != nil {
+			ctx.JSON(pricingStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("remove member successfully %s", customerId)})
+	}
+}
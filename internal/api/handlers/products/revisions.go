@@ -0,0 +1,64 @@
+package products
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/models/products"
+)
+
+// @Summary Product history process
+// @Description do get a page of the changes of a product, who made each and the old and new value of every changed field.
+// @Description Prices of its variants and their price tiers are recorded too.
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param field query string false "only changes of the field, such as min_retail_price, variants.12.buy_price or variants.12.tiers.3"
+// @Param from query int false "changes at or after the unix second"
+// @Param to query int false "changes before the unix second"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {array} products.ProductRevisions "product revisions"
+// @Failure 400 {string} string "invalid query"
+// @Router /groceries/products/{id}/history [get]
+// @Security Bearer
+func ProductHistory(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		params, err := helpers.ListParams(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filter := products.RevisionFilter{Field: ctx.Query("field")}
+		from, err := helpers.QueryInt(ctx, "from")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		to, err := helpers.QueryInt(ctx, "to")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.From, filter.To = int64(from), int64(to)
+
+		var revision products.ProductRevisions
+		result, page, err := revision.GetAll(productId, filter, params, db)
+		if err != nil {
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"revisions": result, "page": page})
+	}
+}
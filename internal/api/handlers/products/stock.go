@@ -0,0 +1,161 @@
+package products
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+func stockStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound), errors.Is(err, products.ErrVariantNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, products.ErrStockKind), errors.Is(err, products.ErrStockSign):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// @Summary Record stock movement process
+// @Description do record a receipt, sale, return, adjustment, defective write-off or transfer of a product. The quantity is signed and in units of variant_id, or in base units without it. A transfer moves a positive quantity to to_product_id.
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param id path integer true "product id"
+// @Param movement body dtos.StockMovement true "stock movement"
+// @Success 201 {array} products.StockMovements "recorded movements"
+// @Failure 409 {string} string "insufficient stock"
+// @Router /groceries/products/{id}/stock [post]
+// @Security Bearer
+func RecordStock(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		var data dtos.StockMovement
+		if err := ctx.ShouldBindJSON(&data); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if data.Kind == "" {
+			data.Kind = products.StockAdjustment
+		}
+
+		principal := middleware.GetPrincipal(ctx)
+
+		movement := &products.StockMovements{
+			ProductID: productId,
+			Kind:      data.Kind,
+			Quantity:  data.Quantity,
+			Reason:    data.Reason,
+			Actor:     principal.Username,
+			Reference: data.Reference,
+		}
+		if data.VariantID != 0 {
+			movement.VariantID = &data.VariantID
+			movement.VariantQuantity = &data.Quantity
+		}
+
+		movements := []*products.StockMovements{movement}
+
+		if data.Kind == products.StockTransfer {
+			if data.Quantity <= 0 || data.ToProductID == 0 || data.ToProductID == productId {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "transfer membutuhkan quantity positif dan to_product_id produk lain"})
+				return
+			}
+
+			var product products.Product
+			target, err := product.Get(data.ToProductID, db)
+			if err != nil || target.UserID != principal.Username {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": models.ErrRecordNotFound.Error()})
+				return
+			}
+
+			if movement.Reference == "" {
+				movement.Reference = fmt.Sprintf("transfer:%d:%d", productId, data.ToProductID)
+			}
+
+			// the source gives the quantity, the target gets it in base units
+			baseQuantity := data.Quantity
+			if movement.VariantID != nil {
+				var variant products.ProductVariants
+				source, err := variant.Get(data.VariantID, productId, db)
+				if err != nil {
+					ctx.JSON(stockStatus(err), gin.H{"error": err.Error()})
+					return
+				}
+				baseQuantity *= int64(source.Conversion)
+
+				out := -data.Quantity
+				movement.VariantQuantity = &out
+			}
+			movement.Quantity = -baseQuantity
+
+			movements = append(movements, &products.StockMovements{
+				ProductID: data.ToProductID,
+				Kind:      products.StockTransfer,
+				Quantity:  baseQuantity,
+				Reason:    data.Reason,
+				Actor:     principal.Username,
+				Reference: movement.Reference,
+			})
+		}
+
+		if err := products.Record(movements, db); err != nil {
+			ctx.JSON(stockStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"movements": movements})
+	}
+}
+
+// @Summary Stock history process
+// @Description do get a page of the stock ledger of a product
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param kind query string false "receipt, sale, return, adjustment, defective or transfer"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {array} products.StockMovements "stock movements"
+// @Failure 400 {string} string "invalid query"
+// @Router /groceries/products/{id}/stock-history [get]
+// @Security Bearer
+func StockHistory(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		params, err := helpers.ListParams(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var movement products.StockMovements
+		result, page, err := movement.GetAll(productId, ctx.Query("kind"), params, db)
+		if err != nil {
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"movements": result, "page": page})
+	}
+}
@@ -2,15 +2,14 @@ package products
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
-	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary UpdateProduct access process
@@ -21,8 +20,9 @@ import (
 // @Param id path integer true "id a product"
 // @Param product body dtos.Product true "update a product"
 // @Success 200 {object} dtos.MessagesResponses "the message successfully create"
-// @Failure 400 {string} string "Error Bad Request"
+// @Failure 400 {string} string "Error Bad Request or size type of another base unit"
 // @Failure 404 {string} string "Not Found"
+// @Failure 409 {string} string "product code already used by the grocery"
 // @Router /groceries/products/{id} [put]
 // @Security Bearer
 func Update(db *sql.DB) gin.HandlerFunc {
@@ -30,17 +30,7 @@ func Update(db *sql.DB) gin.HandlerFunc {
 		var product products.Product
 		var updateData products.Product
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -61,7 +51,12 @@ func Update(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 		if updateData.ProductCode != "" {
-			productData.ProductCode = updateData.ProductCode
+			code, err := products.CheckCode(updateData.ProductCode)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			productData.ProductCode = code
 		}
 
 		if updateData.ProductName != "" {
@@ -81,6 +76,11 @@ func Update(db *sql.DB) gin.HandlerFunc {
 		}
 
 		if updateData.CategoryId != 0 {
+			var category products.CategoryProducts
+			if err := category.Visible(updateData.CategoryId, principal.Username, db); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "category_id tidak ditemukan"})
+				return
+			}
 			productData.CategoryId = updateData.CategoryId
 		}
 
@@ -100,14 +100,15 @@ func Update(db *sql.DB) gin.HandlerFunc {
 			productData.Active = true
 		}
 
-		// check roles group
-		err = helpers.CheckAccountGroceries(output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		err = productData.Update(db, productData.ID, principal.Username)
+		if errors.Is(err, products.ErrIncompatibleUnits) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, products.ErrProductCodeExists) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 			return
 		}
-
-		err = productData.Update(db, productData.ID, *output.Username)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
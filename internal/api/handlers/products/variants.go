@@ -0,0 +1,247 @@
+package products
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+// bindVariant reads a variant of productId from the body, otherwise it
+// answers the request and returns false.
+func bindVariant(ctx *gin.Context, productId int64) (*products.ProductVariants, bool) {
+	var data dtos.ProductVariant
+	if err := ctx.ShouldBindJSON(&data); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if data.SizeTypeID <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "size_type_id wajib diisi"})
+		return nil, false
+	}
+	barcode, err := products.CheckCode(data.Barcode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	// a conversion of 0 takes the factor of the size type
+	if data.MRP < 0 || data.BuyPrice < 0 || data.Conversion < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "harga dan conversion tidak boleh negatif"})
+		return nil, false
+	}
+
+	return &products.ProductVariants{
+		ProductID:  productId,
+		SizeTypeID: data.SizeTypeID,
+		SKU:        data.SKU,
+		Barcode:    barcode,
+		BuyPrice:   data.BuyPrice,
+		MRP:        data.MRP,
+		Conversion: data.Conversion,
+		Active:     data.Active,
+	}, true
+}
+
+func variantStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrIncompatibleUnits):
+		return http.StatusBadRequest
+	case errors.Is(err, products.ErrVariantExists), errors.Is(err, products.ErrDefaultVariant):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// @Summary Create product variant process
+// @Description do add a unit the product is sold in, with its own price and stock
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variant body dtos.ProductVariant true "variant of the product"
+// @Success 201 {object} products.ProductVariants "created variant"
+// @Failure 400 {string} string "size type of another base unit"
+// @Failure 409 {string} string "size type already used"
+// @Router /groceries/products/{id}/variants [post]
+// @Security Bearer
+func CreateVariant(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		variant, ok := bindVariant(ctx, productId)
+		if !ok {
+			return
+		}
+
+		if err := variant.Insert(db); err != nil {
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := variant.Get(variant.ID, productId, db)
+		if err != nil {
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"variant": result})
+	}
+}
+
+// @Summary List product variants process
+// @Description do list the active units a product is sold in, smallest first
+// @Tags products
+// @Produce json
+// @Param id path integer true "product id"
+// @Success 200 {array} products.ProductVariants "variants of the product"
+// @Router /product/{id}/variants [get]
+// @Security Bearer
+func ListVariants(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
+		}
+
+		var variant products.ProductVariants
+		all, err := variant.GetAll(id, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// buyers only see the variants they can put in a cart
+		result := make([]products.ProductVariants, 0, len(all))
+		for _, each := range all {
+			if each.Active {
+				result = append(result, each)
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"variants": result})
+	}
+}
+
+// @Summary List own product variants process
+// @Description do list the variants of a product of the grocery, inactive ones included
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Success 200 {array} products.ProductVariants "variants of the product"
+// @Router /groceries/products/{id}/variants [get]
+// @Security Bearer
+func ListOwnVariants(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		var variant products.ProductVariants
+		result, err := variant.GetAll(productId, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"variants": result})
+	}
+}
+
+// @Summary Update product variant process
+// @Description do update the price, stock or unit of a product variant. The variant of the size type of the product
+// @Description keeps its size type, its prices are the prices of the product and are changed with it.
+// @Tags groceries
+// @Accept json
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variantId path integer true "variant id"
+// @Param variant body dtos.ProductVariant true "variant of the product"
+// @Success 200 {object} products.ProductVariants "updated variant"
+// @Failure 400 {string} string "size type of another base unit"
+// @Failure 404 {string} string "variant not found"
+// @Failure 409 {string} string "size type already used or of the default variant"
+// @Router /groceries/products/{id}/variants/{variantId} [put]
+// @Security Bearer
+func UpdateVariant(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		variantId, err := strconv.ParseInt(ctx.Param("variantId"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "variantId tidak valid"})
+			return
+		}
+
+		variant, ok := bindVariant(ctx, productId)
+		if !ok {
+			return
+		}
+
+		principal := middleware.GetPrincipal(ctx)
+		if err := variant.Update(variantId, productId, principal.Username, db); err != nil {
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := variant.Get(variantId, productId, db)
+		if err != nil {
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"variant": result})
+	}
+}
+
+// @Summary Delete product variant process
+// @Description do delete a product variant, carts holding it are removed. The variant of the size type of the product
+// @Description is deleted only with the product.
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "product id"
+// @Param variantId path integer true "variant id"
+// @Success 200 {object} dtos.MessagesResponses "variant deleted"
+// @Failure 404 {string} string "variant not found"
+// @Failure 409 {string} string "default variant of the product"
+// @Router /groceries/products/{id}/variants/{variantId} [delete]
+// @Security Bearer
+func DeleteVariant(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		productId, ok := ownProduct(ctx, db)
+		if !ok {
+			return
+		}
+
+		variantId, err := strconv.ParseInt(ctx.Param("variantId"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "variantId tidak valid"})
+			return
+		}
+
+		var variant products.ProductVariants
+		if err := variant.Delete(variantId, productId, middleware.GetPrincipal(ctx).Username, db); err != nil {
+			ctx.JSON(variantStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("delete variant successfully id:%d", variantId)})
+	}
+}
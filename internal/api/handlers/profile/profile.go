@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/pkg/aws"
+)
+
+// phone numbers are stored in the E.164 format cognito expects
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// load returns the profile of the caller, creating it for users that signed
+// in before profiles existed.
+func load(ctx *gin.Context, db *sql.DB) (*models.Profiles, error) {
+	var profile models.Profiles
+
+	principal := middleware.GetPrincipal(ctx)
+
+	result, err := profile.Get(principal.Username, db)
+	if !errors.Is(err, models.ErrRecordNotFound) {
+		return result, err
+	}
+
+	if err := profile.Ensure(principal.Username, principal.Email, db); err != nil {
+		return nil, err
+	}
+
+	return profile.Get(principal.Username, db)
+}
+
+// @Summary Get profile process
+// @Description do get the profile of the current user
+// @Tags profile
+// @Produce json
+// @Success 200 {object} models.Profiles "the profile"
+// @Failure 500 {string} string "Error Internal Server"
+// @Router /profile [get]
+// @Security Bearer
+func Get(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, err := load(ctx, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"profile": result})
+	}
+}
+
+// @Summary Update profile process
+// @Description do update the profile of the current user, name, phone and avatar are synced to cognito
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param profile body dtos.Profile true "fields to update"
+// @Success 200 {object} models.Profiles "the updated profile"
+// @Failure 400 {string} string "Error Bad Request"
+// @Failure 403 {string} string "permission denied"
+// @Router /profile [put]
+// @Security Bearer
+func Update(db *sql.DB, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var updateData dtos.Profile
+
+		principal := middleware.GetPrincipal(ctx)
+
+		if err := ctx.ShouldBindJSON(&updateData); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if updateData.StoreName != "" && !principal.HasRole(middleware.RoleGrosir) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "store name hanya untuk akun grosir"})
+			return
+		}
+
+		if updateData.Phone != "" && !phonePattern.MatchString(updateData.Phone) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "nomor telepon harus diawali kode negara, contoh +628123456789"})
+			return
+		}
+
+		profile, err := load(ctx, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// attributes cognito keeps a copy of
+		attributes := map[string]string{}
+
+		if updateData.DisplayName != "" && updateData.DisplayName != profile.DisplayName {
+			profile.DisplayName = updateData.DisplayName
+			attributes["name"] = updateData.DisplayName
+		}
+
+		if updateData.Phone != "" && updateData.Phone != profile.Phone {
+			profile.Phone = updateData.Phone
+			attributes["phone_number"] = updateData.Phone
+		}
+
+		if updateData.Avatar != "" && updateData.Avatar != profile.Avatar {
+			profile.Avatar = updateData.Avatar
+			attributes["picture"] = updateData.Avatar
+		}
+
+		if updateData.BusinessName != "" {
+			profile.BusinessName = updateData.BusinessName
+		}
+
+		if updateData.StoreName != "" {
+			profile.StoreName = updateData.StoreName
+		}
+
+		if updateData.DefaultAddress != "" {
+			profile.DefaultAddress = updateData.DefaultAddress
+		}
+
+		for attribute, value := range attributes {
+			if err := conn.Cognito.UpdateUserAttributes(ctx, principal.Username, attribute, value); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if err := profile.Update(principal.Username, db); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"profile": profile})
+	}
+}
@@ -2,19 +2,34 @@ package size
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
 	"payuoge.com/internal/api/models/products"
-	"payuoge.com/pkg/aws"
 )
 
+func sizeStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrInvalidBaseUnit),
+		errors.Is(err, products.ErrBaseUnitInUse),
+		errors.Is(err, products.ErrIncompatibleUnits):
+		return http.StatusBadRequest
+	case errors.Is(err, products.ErrSizeTypeInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 // @Summary create sizeType process
-// @Description do create size type
+// @Description do create size type, a base unit or factor of a base unit of the grocery
 // @Tags products
 // @Accept json
 // @Produce json
@@ -26,54 +41,25 @@ import (
 func Create(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var size products.SizeType
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
 
 		if err := ctx.ShouldBindJSON(&size); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		if size.Factor < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "factor tidak boleh negatif"})
 			return
 		}
-
-		targetValue := []string{
-			"grosir",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		if size.BaseUnitID != nil && size.Factor == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "factor wajib diisi untuk size type dengan base unit"})
 			return
 		}
 
-		if err := size.Insert(db, *output.Username); err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{
+		if err := size.Insert(db, principal.Username); err != nil {
+			ctx.JSON(sizeStatus(err), gin.H{
 				"error": err.Error(),
 			})
 			return
@@ -86,44 +72,76 @@ func Create(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary GetAll Size process
-// @Description do get all size
+// @Description do get a page of sizes
 // @Tags products
 // @Accept json
 // @Produce json
+// @Param grocery_id query string false "user id of the grocery"
+// @Param sort query string false "name or id"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Success 200 {object} dtos.MessagesResponses "get all size"
-// @Failure 400 {string} string "cookie not found"
+// @Failure 400 {string} string "invalid query"
 // @Router /products/size [get]
 // @Security Bearer
 func GetAll(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var size products.SizeType
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
 
-		_, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		params, err := helpers.ListParams(ctx)
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := size.GetAll(db)
+		result, page, err := size.GetAll(ctx.Query("grocery_id"), params, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"message": err.Error(),
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
 
 		ctx.JSON(http.StatusOK, gin.H{
 			"size_type": result,
+			"page":      page,
 		})
 	}
 }
 
+// @Summary Convert Size process
+// @Description do convert a quantity between two size types sharing a base unit
+// @Tags products
+// @Produce json
+// @Param from query integer true "size type id to convert from"
+// @Param to query integer true "size type id to convert to"
+// @Param quantity query integer true "quantity in the from size type"
+// @Success 200 {object} products.Conversion "converted quantity"
+// @Failure 400 {string} string "size types without a shared base unit"
+// @Router /products/size/convert [get]
+// @Security Bearer
+func Convert(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		from, errFrom := strconv.Atoi(ctx.Query("from"))
+		to, errTo := strconv.Atoi(ctx.Query("to"))
+		quantity, errQuantity := strconv.ParseInt(ctx.Query("quantity"), 10, 64)
+		if errFrom != nil || errTo != nil || errQuantity != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "from, to dan quantity harus berupa angka"})
+			return
+		}
+
+		result, err := products.Convert(from, to, quantity, db)
+		if err != nil {
+			ctx.JSON(sizeStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"conversion": result})
+	}
+}
+
 // @Summary GetID Size process
 // @Description do get all size
 // @Tags products
@@ -144,18 +162,6 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 			})
 			return
 		}
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		_, err = aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
 
 		result, err := size.Get(id, db)
 		if err != nil {
@@ -180,23 +186,14 @@ func GetID(db *sql.DB) gin.HandlerFunc {
 // @Param name body dtos.SizeType true "update size type"
 // @Success 200 {object} dtos.MessagesResponses "get all size"
 // @Failure 400 {string} string "cookie not found"
+// @Failure 409 {string} string "base unit or factor of a size in use"
 // @Router /products/size/{id} [put]
 // @Security Bearer
 func Update(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var size products.SizeType
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
 
-		token := splitted[1]
-
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -213,37 +210,18 @@ func Update(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		if size.Factor < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "factor tidak boleh negatif"})
 			return
 		}
-
-		targetValue := []string{
-			"grosir",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
+		if size.BaseUnitID != nil && size.Factor == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "factor wajib diisi untuk size type dengan base unit"})
 			return
 		}
 
-		err = size.Update(id, *output.Username, db)
+		err = size.Update(id, principal.Username, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{
+			ctx.JSON(sizeStatus(err), gin.H{
 				"message": err.Error(),
 			})
 			return
@@ -256,29 +234,20 @@ func Update(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary DeleteID Size process
-// @Description do delete a size
+// @Description do move a size to the trash, a size of products, variants or other sizes can not be deleted
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path integer true "delete size with id"
 // @Success 200 {object} dtos.MessagesResponses "get all size"
 // @Failure 400 {string} string "cookie not found"
+// @Failure 409 {string} string "size still in use"
 // @Router /products/size/{id} [delete]
 // @Security Bearer
 func Delete(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var size products.SizeType
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -288,37 +257,9 @@ func Delete(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles group
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-
-		targetValue := []string{
-			"grosir",
-		}
-
-		found := false
-		for _, value := range resp {
-			for _, tV := range targetValue {
-				if value == tV {
-					found = true
-					break
-				}
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		err = size.Delete(id, *output.Username, db)
+		err = size.Delete(id, principal.Username, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{
+			ctx.JSON(sizeStatus(err), gin.H{
 				"message": err.Error(),
 			})
 			return
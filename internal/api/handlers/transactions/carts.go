@@ -2,19 +2,20 @@ package transactions
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models/products"
 	"payuoge.com/internal/api/models/transactions"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary CreateCart access process
-// @Description do create a cart
+// @Description do create a cart of a product variant, variant_id or else the variant of size_type_id
 // @Tags transactions
 // @Accept json
 // @Produce json
@@ -27,109 +28,62 @@ func CreateCart(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		if err := ctx.ShouldBindJSON(&cart); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
+		if err := cart.Insert(principal.Username, db); err != nil {
+			if errors.Is(err, products.ErrVariantNotFound) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		if err := cart.Insert(*output.Username, db); err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		ctx.JSON(http.StatusCreated, gin.H{
 			"message": "create successfully",
-			"group":   resp,
+			"group":   principal.Groups,
 		})
 	}
 }
 
 // @Summary GetAll carts process
-// @Description do get all carts
+// @Description do get a page of carts
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Param sort query string false "created or name"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Success 200 {object} dtos.MessagesResponses "get all transactions"
-// @Failure 400 {string} string "not authorized"
+// @Failure 400 {string} string "invalid query"
 // @Router /transactions/carts [get]
 // @Security Bearer
 func GetAllCart(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
+		params, err := helpers.ListParams(ctx)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		result, err := cart.GetAll(*output.Username, db)
+		result, page, err := cart.GetAll(principal.Username, params, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"transactions": result})
+		ctx.JSON(http.StatusOK, gin.H{"transactions": result, "page": page})
 	}
 }
 
@@ -146,16 +100,7 @@ func GetAllCart(db *sql.DB) gin.HandlerFunc {
 func GetIDCart(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -165,29 +110,7 @@ func GetIDCart(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		result, err := cart.GetID(int64(id), *output.Username, db)
+		result, err := cart.GetID(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -214,17 +137,7 @@ func UpdateChart(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -241,29 +154,7 @@ func UpdateChart(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		err = cart.Update(int64(id), *output.Username, db)
+		err = cart.Update(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -291,15 +182,7 @@ func DeleteChart(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -308,28 +191,7 @@ func DeleteChart(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-		err = cart.Delete(int64(id), *output.Username, db)
+		err = cart.Delete(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
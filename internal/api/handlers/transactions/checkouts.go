@@ -3,22 +3,20 @@ package transactions
 import (
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/middleware"
 	"payuoge.com/internal/api/models/transactions"
-	"payuoge.com/pkg/aws"
 )
 
 // @Summary CreateCheckout access process
-// @Description do create a create checkout
+// @Description do create a checkout of the carts priced at their tiers
 // @Tags transactions
 // @Accept json
 // @Produce json
-// @Success 200 {object} dtos.MessagesResponses "message responses"
+// @Success 201 {object} transactions.PriceBreakdown "line by line price breakdown"
 // @Failure 400 {string} string "not authorized"
 // @Router /transactions/checkout [post]
 // @Security Bearer
@@ -27,46 +25,44 @@ func CreateCheckout(db *sql.DB) gin.HandlerFunc {
 		var checkout transactions.Checkouts
 		// var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
+		principal := middleware.GetPrincipal(ctx)
+
+		breakdown, err := checkout.CalculateTotalAmount(principal.Username, db)
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
+		err = checkout.Insert(principal.Username, breakdown.TotalAmount, db)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 			return
 		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
 
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
+		ctx.JSON(http.StatusCreated, gin.H{"message": "checkout successfully", "breakdown": breakdown})
+	}
+}
 
-		totalAmount := checkout.CalculateTotalAmount(*output.Username, db)
-		err = checkout.Insert(*output.Username, totalAmount, db)
+// @Summary Preview Checkout access process
+// @Description do price the carts at their tiers without creating a checkout
+// @Tags transactions
+// @Produce json
+// @Success 200 {object} transactions.PriceBreakdown "line by line price breakdown"
+// @Router /transactions/checkout/preview [get]
+// @Security Bearer
+func PreviewCheckout(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var checkout transactions.Checkouts
+
+		principal := middleware.GetPrincipal(ctx)
+
+		breakdown, err := checkout.CalculateTotalAmount(principal.Username, db)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusCreated, gin.H{"message": "checkout successfully"})
+		ctx.JSON(http.StatusOK, gin.H{"breakdown": breakdown})
 	}
 }
 
@@ -83,39 +79,9 @@ func GetCheckout(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var checkout transactions.Checkouts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		result, err := checkout.GetAll(*output.Username, db)
+		result, err := checkout.GetAll(principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 			return
@@ -138,16 +104,7 @@ func GetIDCheckout(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var cart transactions.Carts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -157,29 +114,7 @@ func GetIDCheckout(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		result, err := cart.GetID(int64(id), *output.Username, db)
+		result, err := cart.GetID(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -204,16 +139,7 @@ func UpdateCheckout(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var checkout transactions.Checkouts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -223,29 +149,7 @@ func UpdateCheckout(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		err = checkout.Update(int64(id), *output.Username, db)
+		err = checkout.Update(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -273,16 +177,7 @@ func DeleteCheckout(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var checkout transactions.Checkouts
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -292,29 +187,7 @@ func DeleteCheckout(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
-			return
-		}
-
-		err = checkout.Delete(int64(id), *output.Username, db)
+		err = checkout.Delete(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
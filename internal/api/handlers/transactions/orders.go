@@ -2,134 +2,110 @@ package transactions
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"payuoge.com/configs"
 	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
 	"payuoge.com/internal/api/models/transactions"
-	"payuoge.com/pkg/aws"
 )
 
+func orderStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, transactions.ErrOrderClosed):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 // @Summary Create Orders access process
-// @Description do create a order
+// @Description do create a order, reserving the stock of every cart until the groceries confirm it. The items keep
+// @Description the product name and the price at the time of the order.
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Success 201 {object} transactions.Orders "placed order"
+// @Failure 400 {string} string "the cart is empty"
+// @Failure 409 {array} products.StockShortage "carts the stock cannot fill"
 // @Router /transactions/orders [post]
 // @Security Bearer
-func CreateOrders(db *sql.DB) gin.HandlerFunc {
+func CreateOrders(db *sql.DB, config configs.OrderConfig) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var order transactions.Orders
-		var checkout transactions.Checkouts
-
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
-
-		// check roles groups
-		err = helpers.CheckAccountRetail(output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
-			return
-		}
 
-		totalAmount := checkout.CalculateTotalAmount(*output.Username, db)
+		principal := middleware.GetPrincipal(ctx)
 
-		err = order.Insert(*output.Username, int32(totalAmount), db)
+		err := order.Insert(principal.Username, config.ReservationTTL, db)
 		if err != nil {
+			var shortage *products.InsufficientStockError
+			if errors.As(err, &shortage) {
+				ctx.JSON(http.StatusConflict, gin.H{"message": err.Error(), "items": shortage.Items})
+				return
+			}
 			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusCreated, gin.H{"message": "order success"})
+		ctx.JSON(http.StatusCreated, gin.H{"message": "order success", "order": order})
 	}
 }
 
 // @Summary Get All Order access process
-// @Description do get all order
+// @Description do get a page of orders
 // @Tags transactions
 // @Accept json
 // @Produce json
+// @Param sort query string false "date or total"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
 // @Router /transactions/orders [get]
 // @Security Bearer
 func GetOrders(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var order transactions.Orders
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
-			})
-		}
+		principal := middleware.GetPrincipal(ctx)
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
+		params, err := helpers.ListParams(ctx)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
 
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		result, err := order.GetAll(*output.Username, db)
+		result, page, err := order.GetAll(principal.Username, params, db)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"orders": result})
+		ctx.JSON(http.StatusOK, gin.H{"orders": result, "page": page})
 	}
 }
 
 // @Summary Get id order access process
-// @Description do get id order
+// @Description do get id order with its items as they were ordered
 // @Tags transactions
 // @Accept json
 // @Produce json
 // @Param id path integer true "get id order"
+// @Success 200 {object} transactions.Orders "order with its items"
 // @Router /transactions/orders/{id} [get]
 // @Security Bearer
 func GetIDOrder(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var order transactions.Orders
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -139,29 +115,7 @@ func GetIDOrder(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		result, err := order.GetID(int64(id), *output.Username, db)
+		result, err := order.GetID(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": "not found record",
@@ -174,7 +128,7 @@ func GetIDOrder(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary update id order access process
-// @Description do update id order
+// @Description recalculate the total of the order from its items as they were ordered
 // @Tags transactions
 // @Accept json
 // @Produce json
@@ -185,16 +139,7 @@ func UpdateOrder(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var order transactions.Orders
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -204,29 +149,7 @@ func UpdateOrder(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
-			return
-		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
-		}
-
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "permission denied"})
-			return
-		}
-
-		err = order.Update(int64(id), *output.Username, db)
+		err = order.Update(int64(id), principal.Username, db)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"message": err.Error(),
@@ -241,7 +164,7 @@ func UpdateOrder(db *sql.DB) gin.HandlerFunc {
 }
 
 // @Summary delete id order access process
-// @Description do delete id order
+// @Description hide the order, a placed order is cancelled and its stock released, the order and its items are kept for its invoice
 // @Tags transactions
 // @Accept json
 // @Produce json
@@ -252,16 +175,7 @@ func DeleteOrder(db *sql.DB) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var order transactions.Orders
 
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-		token := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
+		principal := middleware.GetPrincipal(ctx)
 
 		id, err := strconv.Atoi(ctx.Params.ByName("id"))
 		if err != nil {
@@ -271,38 +185,80 @@ func DeleteOrder(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		// check roles groups
-		resp, err := aws.NewConnect().Cognito.CheckUserInGroup(*output.Username)
+		err = order.Delete(int64(id), principal.Username, db)
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
 			return
 		}
-		targetValue := "retail"
-		found := false
-		for _, value := range resp {
-			if value == targetValue {
-				found = true
-				break
-			}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("delete successfully checkout :%d", id),
+		})
+	}
+}
+
+// @Summary Cancel order process
+// @Description do cancel a placed order and release the stock it reserved
+// @Tags transactions
+// @Produce json
+// @Param id path integer true "order id"
+// @Success 200 {object} dtos.MessagesResponses "order cancelled"
+// @Failure 404 {string} string "order not found"
+// @Failure 409 {string} string "order is no longer placed"
+// @Router /transactions/orders/{id}/cancel [post]
+// @Security Bearer
+func CancelOrder(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var order transactions.Orders
+
+		principal := middleware.GetPrincipal(ctx)
+
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
 		}
 
-		if found {
-			log.Printf("Found %s\n", targetValue)
-		} else {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+		if err := order.Cancel(id, principal.Username, db); err != nil {
+			ctx.JSON(orderStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		err = order.Delete(int64(id), *output.Username, db)
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("cancel order successfully id:%d", id)})
+	}
+}
+
+// @Summary Confirm order process
+// @Description do confirm the lines of an order at the grocery, turning the stock they reserved into sales
+// @Tags groceries
+// @Produce json
+// @Param id path integer true "order id"
+// @Success 201 {object} transactions.OrderConfirmations "confirmation of the grocery"
+// @Failure 404 {string} string "no lines of the order to confirm"
+// @Failure 409 {string} string "order is no longer placed"
+// @Router /groceries/orders/{id}/confirm [post]
+// @Security Bearer
+func ConfirmOrder(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 		if err != nil {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"message": err.Error(),
-			})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{
-			"message": fmt.Sprintf("delete successfully checkout :%d", id),
-		})
+		confirmation := transactions.OrderConfirmations{
+			OrderID:     id,
+			GroceriesID: principal.Username,
+		}
+		if err := confirmation.Insert(db); err != nil {
+			ctx.JSON(orderStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"confirmation": confirmation})
 	}
 }
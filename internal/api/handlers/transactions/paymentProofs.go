@@ -0,0 +1,52 @@
+package transactions
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/configs"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models/transactions"
+	"payuoge.com/pkg/storage"
+)
+
+// @Summary Get payment proofs of order process
+// @Description do get the payment proofs uploaded for an order, to its customer or a grocery holding lines of it.
+// @Description Proofs are private, each url is presigned and valid for a short time only.
+// @Tags transactions
+// @Produce json
+// @Param id path integer true "order id"
+// @Success 200 {array} transactions.PaymentProofs "payment proofs of the order"
+// @Router /transactions/orders/{id}/payment-proofs [get]
+// @Router /groceries/orders/{id}/payment-proofs [get]
+// @Security Bearer
+func GetPaymentProofs(db *sql.DB, store storage.ObjectStore, conf configs.StorageConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
+		}
+
+		var proof transactions.PaymentProofs
+		result, err := proof.GetAll(id, middleware.GetPrincipal(ctx).Username, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		for i := range result {
+			result[i].URL, err = store.PresignGet(ctx, result[i].ObjectKey, conf.DownloadTTL)
+			if err != nil {
+				log.Println(err.Error())
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"payment_proofs": result})
+	}
+}
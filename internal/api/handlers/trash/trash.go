@@ -0,0 +1,127 @@
+package trash
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+// trashPermissions is what a caller needs to see and restore each kind.
+var trashPermissions = map[string]middleware.Permission{
+	products.TrashProduct:  middleware.PermProductWrite,
+	products.TrashCategory: middleware.PermCategoryWrite,
+	products.TrashSizeType: middleware.PermSizeWrite,
+}
+
+func trashStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrRecordNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, products.ErrTrashKind):
+		return http.StatusBadRequest
+	case errors.Is(err, products.ErrRestoreRequires), errors.Is(err, products.ErrProductCodeExists):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// trashKind returns true when kind is known and the caller may handle it,
+// otherwise it answers the request and returns false.
+func trashKind(ctx *gin.Context, kind string) bool {
+	permission, ok := trashPermissions[kind]
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": products.ErrTrashKind.Error()})
+		return false
+	}
+	if !middleware.GetPrincipal(ctx).Can(permission) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
+		return false
+	}
+	return true
+}
+
+// @Summary Trash process
+// @Description do get a page of the products, categories or sizes the grocery deleted, taxonomy managers also see
+// @Description the deleted global categories. The purge removes them for good after a while.
+// @Tags groceries
+// @Produce json
+// @Param type query string false "product, category or size_type, product by default"
+// @Param sort query string false "deleted or name"
+// @Param order query string false "asc or desc"
+// @Param limit query int false "page size, at most 100"
+// @Param offset query int false "rows to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {array} products.TrashItems "deleted rows"
+// @Failure 400 {string} string "invalid query"
+// @Router /groceries/trash [get]
+// @Security Bearer
+func GetAll(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		kind := ctx.DefaultQuery("type", products.TrashProduct)
+		if !trashKind(ctx, kind) {
+			return
+		}
+
+		params, err := helpers.ListParams(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, page, err := products.Trash(kind, principal.Username, principal.Can(middleware.PermTaxonomyManage), params, db)
+		if err != nil {
+			ctx.JSON(helpers.ListErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"trash": result, "page": page})
+	}
+}
+
+// @Summary Restore process
+// @Description do take a product, category or size out of the trash. A product needs its category and size,
+// @Description a category its parent and a size its base unit out of the trash first.
+// @Tags groceries
+// @Produce json
+// @Param type path string true "product, category or size_type"
+// @Param id path integer true "id of the deleted row"
+// @Success 200 {object} dtos.MessagesResponses "restored"
+// @Failure 404 {string} string "not in the trash"
+// @Failure 409 {string} string "a required row is in the trash or the product code is used again"
+// @Router /groceries/trash/{type}/{id}/restore [post]
+// @Security Bearer
+func Restore(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := middleware.GetPrincipal(ctx)
+
+		kind := ctx.Param("type")
+		if !trashKind(ctx, kind) {
+			return
+		}
+
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
+		}
+
+		if err := products.Restore(kind, id, principal.Username, principal.Can(middleware.PermTaxonomyManage), db); err != nil {
+			ctx.JSON(trashStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("restore %s successfully id:%d", kind, id),
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package uploads
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/configs"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/middleware"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+	"payuoge.com/internal/api/models/transactions"
+	"payuoge.com/pkg/storage"
+)
+
+// target checks the principal may attach an upload of purpose to targetId and
+// returns the prefix of its object key, otherwise it answers the request and
+// returns false.
+func target(ctx *gin.Context, db *sql.DB, conf configs.StorageConfig, purpose string, targetId int64) (string, bool) {
+	principal := middleware.GetPrincipal(ctx)
+
+	switch purpose {
+	case models.UploadProductImage:
+		if !principal.Can(middleware.PermProductWrite) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
+			return "", false
+		}
+
+		var product products.Product
+		result, err := product.Get(targetId, db)
+		if err != nil || result.UserID != principal.Username {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": models.ErrRecordNotFound.Error()})
+			return "", false
+		}
+
+		var image products.ProductImages
+		current, err := image.GetAll(targetId, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return "", false
+		}
+		if len(current) >= conf.MaxImages {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("produk sudah memiliki %d gambar", conf.MaxImages)})
+			return "", false
+		}
+
+		return fmt.Sprintf("products/%d/", targetId), true
+
+	case models.UploadPaymentProof:
+		if !principal.Can(middleware.PermOrderWrite) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
+			return "", false
+		}
+
+		var order transactions.Orders
+		if err := order.Owned(targetId, principal.Username, db); err != nil {
+			if errors.Is(err, models.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return "", false
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return "", false
+		}
+
+		// proofs are private, they are served with presigned URLs only
+		return fmt.Sprintf("%sorders/%d/proofs/", storage.PrivatePrefix, targetId), true
+	}
+
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "purpose harus product_image atau payment_proof"})
+	return "", false
+}
+
+// @Summary Create upload process
+// @Description do create a presigned URL to PUT a product image or a payment proof straight to the storage, then call complete
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param upload body dtos.UploadRequest true "purpose, target id, content type and size of the file"
+// @Success 201 {object} dtos.UploadResponse "where and how to upload"
+// @Failure 400 {string} string "invalid upload"
+// @Failure 413 {string} string "file too large"
+// @Router /uploads [post]
+// @Security Bearer
+func Create(db *sql.DB, store storage.ObjectStore, conf configs.StorageConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request dtos.UploadRequest
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ext, ok := storage.ImageExt(request.ContentType)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": storage.ErrImageType.Error()})
+			return
+		}
+		if request.Size <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "size wajib diisi"})
+			return
+		}
+		if request.Size > conf.MaxImageSize {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("size melebihi %d byte", conf.MaxImageSize)})
+			return
+		}
+
+		prefix, ok := target(ctx, db, conf, request.Purpose, request.TargetID)
+		if !ok {
+			return
+		}
+
+		id, err := storage.NewName()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		upload := models.PendingUploads{
+			ID:          id,
+			OwnerID:     middleware.GetPrincipal(ctx).Username,
+			Purpose:     request.Purpose,
+			TargetID:    request.TargetID,
+			ObjectKey:   prefix + id + ext,
+			ContentType: request.ContentType,
+			Size:        request.Size,
+			ExpiresAt:   time.Now().Add(conf.UploadTTL).Unix(),
+		}
+
+		url, err := store.PresignPut(ctx, upload.ObjectKey, upload.ContentType, upload.Size, conf.UploadTTL)
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := upload.Insert(db); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, dtos.UploadResponse{
+			UploadID:  upload.ID,
+			Method:    http.MethodPut,
+			URL:       url,
+			Headers:   map[string]string{"Content-Type": upload.ContentType},
+			ExpiresAt: upload.ExpiresAt,
+		})
+	}
+}
+
+// @Summary Complete upload process
+// @Description do attach an uploaded file to its product or order once it is found in the storage. The file is read
+// @Description back and checked by its content, a product image also gets a thumbnail like an image uploaded to the product.
+// @Tags uploads
+// @Produce json
+// @Param id path string true "upload id"
+// @Success 201 {object} products.ProductImages "attached image or payment proof"
+// @Failure 400 {string} string "invalid image or the product has too many images"
+// @Failure 404 {string} string "upload not found or its product is in the trash"
+// @Failure 409 {string} string "file not uploaded yet or upload closed"
+// @Failure 413 {string} string "file too large"
+// @Router /uploads/{id}/complete [post]
+// @Security Bearer
+func Complete(db *sql.DB, store storage.ObjectStore, conf configs.StorageConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var upload models.PendingUploads
+		_, err := upload.Get(ctx.Param("id"), middleware.GetPrincipal(ctx).Username, db)
+		if err != nil {
+			if errors.Is(err, models.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if upload.Status != models.UploadPending || time.Now().Unix() > upload.ExpiresAt {
+			ctx.JSON(http.StatusConflict, gin.H{"error": models.ErrUploadClosed.Error()})
+			return
+		}
+
+		exists, err := store.Exists(ctx, upload.ObjectKey)
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !exists {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "file belum diupload"})
+			return
+		}
+
+		var image *products.ProductImages
+		switch upload.Purpose {
+		case models.UploadProductImage:
+			var ok bool
+			if image, ok = productImage(ctx, store, conf, &upload); !ok {
+				return
+			}
+		case models.UploadPaymentProof:
+			if _, ok := readImage(ctx, store, conf, &upload); !ok {
+				return
+			}
+		}
+
+		result, err := attach(db, store, &upload, image, conf.MaxImages)
+		if err != nil {
+			// the thumbnail belongs to no image unless a concurrent complete
+			// attached the upload, a retry makes it again
+			if image != nil && !errors.Is(err, models.ErrUploadClosed) {
+				deleteObjects(ctx, store, image.ThumbnailKey)
+			}
+			switch {
+			case errors.Is(err, models.ErrUploadClosed):
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			case errors.Is(err, models.ErrRecordNotFound):
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, products.ErrTooManyImages):
+				deleteObjects(ctx, store, upload.ObjectKey)
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if proof, ok := result.(transactions.PaymentProofs); ok {
+			proof.URL, err = store.PresignGet(ctx, proof.ObjectKey, conf.DownloadTTL)
+			if err != nil {
+				log.Println(err.Error())
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			result = proof
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{upload.Purpose: result})
+	}
+}
+
+// readImage reads an uploaded file back and checks it by its content, the
+// signed content type and length only bound the upload itself. Otherwise it
+// removes the object, answers the request and returns false.
+func readImage(ctx *gin.Context, store storage.ObjectStore, conf configs.StorageConfig, upload *models.PendingUploads) (*storage.Image, bool) {
+	body, err := store.Get(ctx, upload.ObjectKey)
+	if err != nil {
+		log.Println(err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(body, conf.MaxImageSize+1))
+	body.Close()
+	if err != nil {
+		log.Println(err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if int64(len(data)) > conf.MaxImageSize {
+		deleteObjects(ctx, store, upload.ObjectKey)
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("size melebihi %d byte", conf.MaxImageSize)})
+		return nil, false
+	}
+
+	img, err := storage.ProcessImage(data, conf.ThumbnailWidth)
+	if err == nil && img.ContentType != upload.ContentType {
+		err = storage.ErrImageType
+	}
+	if err != nil {
+		deleteObjects(ctx, store, upload.ObjectKey)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return img, true
+}
+
+// productImage checks an uploaded product image like an image uploaded to
+// the product, then stores its thumbnail. Otherwise it answers the request
+// and returns false.
+func productImage(ctx *gin.Context, store storage.ObjectStore, conf configs.StorageConfig, upload *models.PendingUploads) (*products.ProductImages, bool) {
+	img, ok := readImage(ctx, store, conf, upload)
+	if !ok {
+		return nil, false
+	}
+
+	var err error
+	image := products.ProductImages{
+		ProductID:    upload.TargetID,
+		ObjectKey:    upload.ObjectKey,
+		ThumbnailKey: strings.TrimSuffix(upload.ObjectKey, img.Ext) + "_thumb" + img.ThumbnailExt,
+		URL:          store.URL(upload.ObjectKey),
+		ContentType:  img.ContentType,
+		Size:         int64(len(img.Data)),
+		Width:        img.Width,
+		Height:       img.Height,
+	}
+
+	image.ThumbnailURL, err = store.Put(ctx, image.ThumbnailKey, img.ThumbnailType, bytes.NewReader(img.Thumbnail))
+	if err != nil {
+		log.Println(err.Error())
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return &image, true
+}
+
+// deleteObjects removes objects of a rejected upload, a failure only leaves
+// an orphan object behind.
+func deleteObjects(ctx *gin.Context, store storage.ObjectStore, keys ...string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("delete object %s: %v", key, err)
+		}
+	}
+}
+
+// attach closes the upload and records the object on its target in one
+// transaction, so an upload is attached at most once. A product image is
+// the checked image, attached only while the product has less than
+// maxImages images.
+func attach(db *sql.DB, store storage.ObjectStore, upload *models.PendingUploads, image *products.ProductImages, maxImages int) (interface{}, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := upload.Complete(tx); err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch upload.Purpose {
+	case models.UploadProductImage:
+		if err := image.InsertTx(maxImages, tx); err != nil {
+			return nil, err
+		}
+		result = image
+
+	case models.UploadPaymentProof:
+		proof := transactions.PaymentProofs{
+			OrderID:     upload.TargetID,
+			CustomerID:  upload.OwnerID,
+			ObjectKey:   upload.ObjectKey,
+			ContentType: upload.ContentType,
+			Size:        upload.Size,
+		}
+		if err := proof.InsertTx(tx); err != nil {
+			return nil, err
+		}
+		result = proof
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// @Summary Local upload process
+// @Description do receive a file uploaded with a URL signed by the local storage driver
+// @Tags uploads
+// @Accept image/jpeg,image/png,image/gif
+// @Produce json
+// @Param token path string true "signed upload token"
+// @Success 200 {object} dtos.MessagesResponses "file stored"
+// @Failure 403 {string} string "invalid or expired token"
+// @Router /uploads/local/{token} [put]
+func LocalPut(local *storage.LocalStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := local.Verify(ctx.Param("token"), http.MethodPut)
+		if err != nil {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if ctx.ContentType() != token.ContentType {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Content-Type harus %s", token.ContentType)})
+			return
+		}
+		if ctx.Request.ContentLength != token.Size {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Content-Length harus %d", token.Size)})
+			return
+		}
+
+		body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, token.Size)
+		if _, err := local.Put(ctx, token.Key, token.ContentType, body); err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "upload successfully"})
+	}
+}
+
+// @Summary Local download process
+// @Description do serve a private file with a URL signed by the local storage driver
+// @Tags uploads
+// @Produce image/jpeg,image/png,image/gif
+// @Param token path string true "signed download token"
+// @Success 200 {file} file "stored file"
+// @Failure 403 {string} string "invalid or expired token"
+// @Failure 404 {string} string "file not found"
+// @Router /uploads/local/{token} [get]
+func LocalGet(local *storage.LocalStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, err := local.Verify(ctx.Param("token"), http.MethodGet)
+		if err != nil {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		body, err := local.Get(ctx, token.Key)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": models.ErrRecordNotFound.Error()})
+			return
+		}
+		defer body.Close()
+
+		ctx.Header("Cache-Control", "private, no-store")
+		ctx.DataFromReader(http.StatusOK, -1, mime.TypeByExtension(path.Ext(token.Key)), body, nil)
+	}
+}
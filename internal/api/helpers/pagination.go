@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/models"
+)
+
+// ListParams reads the paging and sorting query parameters shared by every
+// listing: limit, offset, cursor, sort and order (asc or desc).
+func ListParams(ctx *gin.Context) (models.ListParams, error) {
+	params := models.ListParams{
+		Cursor: ctx.Query("cursor"),
+		Sort:   ctx.Query("sort"),
+	}
+
+	var err error
+	if params.Limit, err = QueryInt(ctx, "limit"); err != nil {
+		return params, err
+	}
+	if params.Offset, err = QueryInt(ctx, "offset"); err != nil {
+		return params, err
+	}
+
+	switch strings.ToLower(ctx.Query("order")) {
+	case "", "asc":
+	case "desc":
+		params.Desc = true
+	default:
+		return params, fmt.Errorf("order harus asc atau desc")
+	}
+
+	return params, nil
+}
+
+// QueryInt reads an optional integer query parameter, zero when missing.
+func QueryInt(ctx *gin.Context, name string) (int, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s harus berupa angka", name)
+	}
+	return number, nil
+}
+
+// QueryBool reads an optional boolean query parameter, nil when missing.
+func QueryBool(ctx *gin.Context, name string) (*bool, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s harus true atau false", name)
+	}
+	return &flag, nil
+}
+
+// ListErrorStatus is the status of an error of a listing, a bad sort key or
+// cursor is the client's fault.
+func ListErrorStatus(err error) int {
+	if errors.Is(err, models.ErrInvalidSort) || errors.Is(err, models.ErrInvalidCursor) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
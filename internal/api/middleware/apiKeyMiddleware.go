@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/models"
+)
+
+// Scopes are what an API key can be minted for, each scope grants the
+// permissions listed. A key holds no permission outside of its scopes.
+var Scopes = map[string][]Permission{
+	"products:read":     {PermProductRead},
+	"products:write":    {PermProductRead, PermProductWrite, PermCategoryWrite, PermSizeWrite},
+	"stock:write":       {PermProductRead, PermStockWrite},
+	"operational:write": {PermOperationalWrite},
+	"orders:confirm":    {PermOrderConfirm},
+	"pricing:write":     {PermPricingWrite},
+}
+
+// maxAPIKeyDays caps the lifetime asked for a key.
+const maxAPIKeyDays = 365
+
+// requestAPIKey returns the API key of the request, sent in the X-API-Key
+// header or as "Authorization: ApiKey <key>".
+func requestAPIKey(ctx *gin.Context) string {
+	if key := ctx.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	splitted := strings.Split(ctx.GetHeader("Authorization"), " ")
+	if len(splitted) == 2 && strings.ToLower(splitted[0]) == "apikey" {
+		return splitted[1]
+	}
+	return ""
+}
+
+func scopePermissions(scopes []string) []Permission {
+	var permissions []Permission
+	for _, scope := range scopes {
+		for _, permission := range Scopes[scope] {
+			if !hasPermission(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// authenticateAPIKey sets the principal of a live key, acting for the owner
+// of the key with the permissions of its scopes only.
+func authenticateAPIKey(ctx *gin.Context, db *sql.DB, plainKey string) {
+	var key models.APIKeys
+
+	result, err := key.GetByKey(plainKey, db)
+	if err != nil && !errors.Is(err, models.ErrRecordNotFound) {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Abort()
+		return
+	}
+	if err != nil || !result.Usable(time.Now()) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+		ctx.Abort()
+		return
+	}
+
+	// last use is informative, a failed write must not fail the request
+	_ = result.Touch(db)
+
+	ctx.Set(ContextPrincipal, &Principal{
+		Username:    result.OwnerID,
+		APIKeyID:    result.ID,
+		Permissions: scopePermissions(result.Scopes),
+	})
+
+	ctx.Next()
+}
+
+// RequireSession rejects API keys on endpoints that manage the account
+// itself, those need a signed in user.
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if GetPrincipal(ctx).APIKeyID != 0 {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "endpoint ini membutuhkan login pengguna"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// CreateAPIKeyHandler godoc
+//
+// @Summary Create API key process
+// @Description do mint an API key for integrations, the key is only shown in this response
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param apiKey body dtos.APIKeyData true "name, scopes and lifetime of the key"
+// @Success 201 {object} dtos.APIKeyResponse "the new key"
+// @Failure 400 {string} string "Error Bad request"
+// @Failure 403 {string} string "permission denied"
+// @Router /auth/api-keys [post]
+// @Security Bearer
+func CreateAPIKey(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var keyData dtos.APIKeyData
+		if err := ctx.ShouldBindJSON(&keyData); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if strings.TrimSpace(keyData.Name) == "" || len(keyData.Scopes) == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "name dan scopes wajib diisi"})
+			return
+		}
+
+		if keyData.ExpiresInDays < 0 || keyData.ExpiresInDays > maxAPIKeyDays {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expires_in_days harus antara 0 dan %d", maxAPIKeyDays)})
+			return
+		}
+
+		principal := GetPrincipal(ctx)
+
+		// a key can not do more than its owner
+		scopes := make([]string, 0, len(keyData.Scopes))
+		for _, scope := range keyData.Scopes {
+			permissions, ok := Scopes[scope]
+			if !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("scope %s tidak dikenal", scope)})
+				return
+			}
+			for _, permission := range permissions {
+				if !principal.Can(permission) {
+					ctx.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("scope %s tidak diizinkan", scope)})
+					return
+				}
+			}
+			if !contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+		sort.Strings(scopes)
+
+		plainKey, prefix, err := models.NewAPIKey()
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		key := models.APIKeys{
+			OwnerID: principal.Username,
+			Name:    strings.TrimSpace(keyData.Name),
+			Prefix:  prefix,
+			Scopes:  pq.StringArray(scopes),
+		}
+		if keyData.ExpiresInDays > 0 {
+			expiresAt := time.Now().AddDate(0, 0, keyData.ExpiresInDays).Unix()
+			key.ExpiresAt = &expiresAt
+		}
+
+		if err := key.Insert(plainKey, db); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, dtos.APIKeyResponse{
+			ID:        key.ID,
+			Name:      key.Name,
+			Key:       plainKey,
+			Prefix:    key.Prefix,
+			Scopes:    key.Scopes,
+			ExpiresAt: key.ExpiresAt,
+			CreatedAt: key.CreatedAt,
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, each := range values {
+		if each == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ListAPIKeysHandler godoc
+//
+// @Summary List API keys process
+// @Description do list the API keys of the current user, revoked keys included
+// @Tags auth
+// @Produce json
+// @Success 200 {array} models.APIKeys "api keys"
+// @Router /auth/api-keys [get]
+// @Security Bearer
+func ListAPIKeys(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var key models.APIKeys
+
+		result, err := key.GetAll(GetPrincipal(ctx).Username, db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"keys": result})
+	}
+}
+
+// RevokeAPIKeyHandler godoc
+//
+// @Summary Revoke API key process
+// @Description do revoke an API key of the current user
+// @Tags auth
+// @Produce json
+// @Param id path int true "api key id"
+// @Success 200 {object} dtos.MessagesResponses "key revoked"
+// @Failure 404 {string} string "key not found"
+// @Router /auth/api-keys/{id} [delete]
+// @Security Bearer
+func RevokeAPIKey(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "id tidak valid"})
+			return
+		}
+
+		var key models.APIKeys
+		if err := key.Revoke(id, GetPrincipal(ctx).Username, db); err != nil {
+			if errors.Is(err, models.ErrRecordNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+	}
+}
@@ -1,30 +1,29 @@
 package middleware
 
 import (
-	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/redis/go-redis/v9"
-	"github.com/sethvargo/go-envconfig"
-	"payuoge.com/configs"
 	"payuoge.com/dtos"
-	"payuoge.com/internal/api/helpers"
+	"payuoge.com/internal/api/models"
 	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/cognito"
 )
 
-func Auth(redisClient *redis.Client) gin.HandlerFunc {
+// Auth accepts a bearer access token of a live session, or an API key in the
+// X-API-Key header.
+func Auth(db *sql.DB, sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var configs configs.AppConfiguration
-
-		if err := envconfig.Process(ctx, &configs); err != nil {
-			log.Fatal(err.Error())
+		if key := requestAPIKey(ctx); key != "" {
+			authenticateAPIKey(ctx, db, key)
+			return
 		}
 
 		authHeader := ctx.GetHeader("Authorization")
@@ -42,20 +41,43 @@ func Auth(redisClient *redis.Client) gin.HandlerFunc {
 		}
 
 		accessToken := splitted[1]
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, accessToken)
+		claims, err := conn.Verifier.Verify(ctx, accessToken, "access")
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			ctx.Abort()
+			return
+		}
+
+		revoked, err := sessions.IsRevoked(ctx, claims.JTI)
 		if err != nil {
 			log.Println(err.Error())
+		}
+		if err != nil || revoked {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			ctx.Abort()
 			return
 		}
-		key := fmt.Sprintf("user:%s:access_token", *output.Username)
 
-		cachedToken, err := redisClient.Get(ctx, key).Result()
-		if err != nil || cachedToken != accessToken {
+		// the token must still belong to a live session of the user
+		session, err := sessions.Validate(ctx, claims.User(), claims.JTI)
+		if err != nil {
+			if !errors.Is(err, cache.ErrSessionNotFound) {
+				log.Println(err.Error())
+			}
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			ctx.Abort()
 			return
 		}
 
+		// group membership comes from the token, changes apply on next login
+		ctx.Set(ContextPrincipal, &Principal{
+			Username:  claims.User(),
+			Email:     session.Email,
+			Groups:    claims.Groups,
+			SessionID: session.ID,
+		})
+
 		ctx.Next()
 	}
 }
@@ -68,10 +90,12 @@ func Auth(redisClient *redis.Client) gin.HandlerFunc {
 // @Accept json
 // @Produce json
 // @Param login body dtos.AuthData true "login data"
-// @Success 200 {object} dtos.MessagesResponses "the message success responses"
+// @Param X-Device-Name header string false "name of the device shown in the session list"
+// @Success 200 {object} dtos.LoginResponse "the tokens of the new session"
 // @Failure 400 {string} string "Error Bad request"
+// @Failure 429 {string} string "too many failed attempts"
 // @Router /auth/login [post]
-func Login(redisClient *redis.Client) gin.HandlerFunc {
+func Login(db *sql.DB, sessions *cache.SessionStore, throttles *Throttles, conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var loginData dtos.AuthData
 		if err := ctx.ShouldBindJSON(&loginData); err != nil {
@@ -79,85 +103,41 @@ func Login(redisClient *redis.Client) gin.HandlerFunc {
 			return
 		}
 
-		result, err := aws.NewConnect().Cognito.SignIn(ctx, loginData.Username, loginData.Password)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, err.Error())
+		if throttled(ctx, throttles.Login, loginData.Username) {
 			return
 		}
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, *result.AccessToken)
+		result, err := conn.Cognito.SignIn(ctx, loginData.Username, loginData.Password)
 		if err != nil {
-			log.Println(err.Error())
+			if errors.Is(err, cognito.ErrNotAuthorized) {
+				recordFailure(ctx, throttles.Login, loginData.Username)
+			}
+			ctx.JSON(http.StatusBadRequest, err.Error())
 			return
 		}
 
-		key := fmt.Sprintf("user:%s:access_token", *output.Username)
-
-		accessToken := *result.AccessToken
+		if err := throttles.Login.Reset(ctx, loginData.Username); err != nil {
+			log.Println(err.Error())
+		}
 
-		err = redisClient.Set(ctx, key, accessToken, time.Hour).Err()
+		session, claims, err := startSession(ctx, db, sessions, conn, result)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			log.Println(err.Error())
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
-		cookie := http.Cookie{
-			Name:     "access_token",
-			Value:    accessToken,
-			HttpOnly: true,
-			Secure:   true,
-			Path:     "/",
-			Expires:  time.Now().Add(time.Hour * 1),
-		}
-		http.SetCookie(ctx.Writer, &cookie)
+		setAccessCookie(ctx, result.AccessToken, claims.Expiry())
 
-		ctx.JSON(http.StatusOK, gin.H{
-			"idToken": accessToken,
+		ctx.JSON(http.StatusOK, dtos.LoginResponse{
+			IdToken:      result.AccessToken,
+			RefreshToken: result.RefreshToken,
+			SessionID:    session.ID,
+			ExpiresIn:    result.ExpiresIn,
 		})
 	}
 }
 
-// @Summary Login google access process
-// @Description do logout account
-// @Tags auth
-// @Accept json
-// @Produce json
-// @Success 200 {object} dtos.MessagesResponses "logged in via google successfully"
-// @Failure 400 {string} string "cookie not found"
-// @Router /auth/google [get]
-func LoginGA(ctx *gin.Context) {
-	var configs configs.AppConfiguration
-	if err := envconfig.Process(context.Background(), &configs); err != nil {
-		log.Fatal(err.Error())
-	}
-
-	URI := "https://auth.payuoge.com/oauth2/authorize?"
-	provider := "Google"
-	response := "CODE"
-	clientID := configs.AwsConf.ClientId
-	scope := "aws.cognito.signin.user.admin+email+openid"
-
-	if configs.AppEnv == "production" {
-		redirectURI := "https://api.payuoge.com/v1/auth/callback"
-		accessURI := URI +
-			"identity_provider=" + provider +
-			"&redirect_uri=" + redirectURI +
-			"&response_type=" + response +
-			"&client_id=" + clientID +
-			"&scope=" + scope
-		ctx.Redirect(http.StatusMovedPermanently, accessURI)
-	} else {
-		redirectURI := "http://localhost:4001/v1/auth/callback"
-		accessURI := URI +
-			"identity_provider=" + provider +
-			"&redirect_uri=" + redirectURI +
-			"&response_type=" + response +
-			"&client_id=" + clientID +
-			"&scope=" + scope
-		ctx.Redirect(http.StatusMovedPermanently, accessURI)
-	}
-}
-
 // LogoutHandler godoc
 //
 // @Summary Logout access process
@@ -169,35 +149,32 @@ func LoginGA(ctx *gin.Context) {
 // @Failure 400 {string} string "cookie not found"
 // @Router /auth/logout [get]
 // @Security Bearer
-func Logout(redisClient *redis.Client) gin.HandlerFunc {
+func Logout(sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		cookie, err := ctx.Request.Cookie("access_token")
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cookie not found"})
+		accessToken := requestToken(ctx)
+		if accessToken == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "token not found"})
 			return
 		}
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, cookie.Value)
+		claims, err := conn.Verifier.Verify(ctx, accessToken, "access")
 		if err != nil {
 			log.Println(err.Error())
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
-		key := fmt.Sprintf("user:%s:access_token", *output.Username)
 
-		err = redisClient.Del(ctx, key).Err()
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed deleted token from redis"})
+		// only the session of this device is signed out
+		session, err := sessions.Validate(ctx, claims.User(), claims.JTI)
+		if err == nil {
+			err = sessions.Revoke(ctx, session.Username, session.ID)
+		}
+		if err != nil && !errors.Is(err, cache.ErrSessionNotFound) {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed deleted session from redis"})
 			return
 		}
 
-		expiredCookie := http.Cookie{
-			Name:     "access_token",
-			Value:    "",
-			HttpOnly: true,
-			Secure:   true,
-			Path:     "/",
-			Expires:  time.Now().Add(-1 * time.Hour),
-		}
-		http.SetCookie(ctx.Writer, &expiredCookie)
+		setAccessCookie(ctx, "", time.Now().Add(-1*time.Hour))
 		ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 	}
 }
@@ -213,89 +190,24 @@ func Logout(redisClient *redis.Client) gin.HandlerFunc {
 // @Success 200 {object} dtos.MessagesResponses "send code confirmation"
 // @Failure 400 {string} string "Error Bad request"
 // @Router /auth/register [post]
-func Register(ctx *gin.Context) {
-	var registerData dtos.AuthData
-
-	if err := ctx.ShouldBindJSON(&registerData); err != nil {
-		ctx.JSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	result, err := aws.NewConnect().Cognito.SignUp(ctx, registerData.Username, registerData.Password)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, err.Error())
-		return
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("Kode konfirmasi telah dikirim ke %s.", result),
-	})
-}
-
-func CallbackCognito(redisClient *redis.Client) gin.HandlerFunc {
+func Register(conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var configs configs.AppConfiguration
-		if err := envconfig.Process(context.Background(), &configs); err != nil {
-			log.Fatal(err.Error())
-		}
-
-		code := ctx.DefaultQuery("code", "")
-		if code == "" {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "missing code parameter"})
-			return
-		}
-
-		// configuration oauth2
-		clientID := configs.AwsConf.ClientId
-		clientSecret := configs.AwsConf.ClientSecret
-		grantType := "authorization_code"
-		redirectURI := configs.GoogleAuth.RedirectURL
-
-		data := url.Values{}
-		data.Set("grant_type", grantType)
-		data.Set("client_id", clientID)
-		data.Set("client_secret", clientSecret)
-		data.Set("code", code)
-		data.Set("redirect_uri", redirectURI)
-
-		resp, err := http.Post("https://auth.payuoge.com/oauth2/token", "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
-
-		if err != nil {
-			log.Println(err.Error())
-		}
-
-		defer resp.Body.Close()
-
-		var idToken map[string]interface{}
+		var registerData dtos.AuthData
 
-		if err := json.NewDecoder(resp.Body).Decode(&idToken); err != nil {
-			return
-		}
-
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, idToken["access_token"].(string))
-		if err != nil {
-			log.Println(err.Error())
+		if err := ctx.ShouldBindJSON(&registerData); err != nil {
+			ctx.JSON(http.StatusBadRequest, err.Error())
 			return
 		}
 
-		key := fmt.Sprintf("user:%s:access_token", *output.Username)
-		err = redisClient.Set(ctx, key, idToken["access_token"].(string), time.Hour).Err()
+		result, err := conn.Cognito.SignUp(ctx, registerData.Username, registerData.Password)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			ctx.JSON(http.StatusBadRequest, err.Error())
 			return
 		}
 
-		cookie := http.Cookie{
-			Name:     "access_token",
-			Value:    idToken["access_token"].(string),
-			HttpOnly: true,
-			Secure:   true,
-			Path:     "/",
-			Expires:  time.Now().Add(time.Hour * 1),
-		}
-		http.SetCookie(ctx.Writer, &cookie)
-
-		ctx.Redirect(http.StatusMovedPermanently, "https://payuoge.com")
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("Kode konfirmasi telah dikirim ke %s.", result),
+		})
 	}
 }
 
@@ -310,21 +222,23 @@ func CallbackCognito(redisClient *redis.Client) gin.HandlerFunc {
 // @Success 200 {object} string "redirect to home"
 // @Failure 400 {string} string "Error Bad request"
 // @Router /auth/confirm [post]
-func Confirmation(ctx *gin.Context) {
-	var confirmData dtos.AuthCodeData
+func Confirmation(conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var confirmData dtos.AuthCodeData
 
-	if err := ctx.BindJSON(&confirmData); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+		if err := ctx.BindJSON(&confirmData); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	_, err := aws.NewConnect().Cognito.ConfirmSignUp(confirmData.Username, confirmData.ConfirmationCode)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+		err := conn.Cognito.ConfirmSignUp(ctx, confirmData.Username, confirmData.ConfirmationCode)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	ctx.JSON(http.StatusCreated, gin.H{"message": "akun telah dikonfirmasi"})
+		ctx.JSON(http.StatusCreated, gin.H{"message": "akun telah dikonfirmasi"})
+	}
 }
 
 // ResendCodeHandler godoc
@@ -337,22 +251,30 @@ func Confirmation(ctx *gin.Context) {
 // @Param resendCode body dtos.Users true "used for resend code"
 // @Success 200 {object} dtos.MessagesResponses "check inbox email address"
 // @Failure 400 {string} string "Error Bad request"
+// @Failure 429 {string} string "too many requests"
 // @Router /auth/resend [post]
-func ResendCode(ctx *gin.Context) {
-	var resendCode dtos.Users
+func ResendCode(throttles *Throttles, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var resendCode dtos.Users
 
-	if err := ctx.BindJSON(&resendCode); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+		if err := ctx.BindJSON(&resendCode); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	result, err := aws.NewConnect().Cognito.ResendConfirmationCode(ctx, resendCode.Username)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		return
-	}
+		if throttled(ctx, throttles.Code, resendCode.Username) {
+			return
+		}
+		recordFailure(ctx, throttles.Code, resendCode.Username)
+
+		result, err := conn.Cognito.ResendConfirmationCode(ctx, resendCode.Username)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("kode konfirmasi telah dikirim ulang kembali ke %s", result)})
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("kode konfirmasi telah dikirim ulang kembali ke %s", result)})
+	}
 }
 
 // @Summary ForgotPassword process
@@ -363,22 +285,30 @@ func ResendCode(ctx *gin.Context) {
 // @Param forgotData body dtos.Users true "used for forgot password"
 // @Success 200 {object} dtos.MessagesResponses "send code confirmation for forgot password"
 // @Failure 400 {string} string "Error Bad request"
+// @Failure 429 {string} string "too many requests"
 // @Router /auth/forgot [post]
-func ForgotPassword(ctx *gin.Context) {
-	var forgotData dtos.Users
+func ForgotPassword(throttles *Throttles, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var forgotData dtos.Users
 
-	if err := ctx.BindJSON(&forgotData); err != nil {
-		ctx.JSON(http.StatusBadRequest, err.Error())
-		return
-	}
+		if err := ctx.BindJSON(&forgotData); err != nil {
+			ctx.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
 
-	result, err := aws.NewConnect().Cognito.ForgotPassword(ctx, forgotData.Username)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		return
-	}
+		if throttled(ctx, throttles.Code, forgotData.Username) {
+			return
+		}
+		recordFailure(ctx, throttles.Code, forgotData.Username)
+
+		result, err := conn.Cognito.ForgotPassword(ctx, forgotData.Username)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("kode konfirmasi untuk lupa password telah dikirim, silahkan cek inbox %s", result)})
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("kode konfirmasi untuk lupa password telah dikirim, silahkan cek inbox %s", result)})
+	}
 }
 
 // ResetPasswordHandler godoc
@@ -392,64 +322,111 @@ func ForgotPassword(ctx *gin.Context) {
 // @Success 200 {object} dtos.MessagesResponses "send for reset password"
 // @Failure 400 {string} string "Error Bad request"
 // @Router /auth/reset [post]
-func ResetPassword(ctx *gin.Context) {
-	var resetPassword dtos.AuthResetData
-	if err := ctx.BindJSON(&resetPassword); err != nil {
-		ctx.JSON(http.StatusBadRequest, err.Error())
-		return
-	}
+func ResetPassword(conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var resetPassword dtos.AuthResetData
+		if err := ctx.BindJSON(&resetPassword); err != nil {
+			ctx.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
 
-	_, err := aws.NewConnect().Cognito.ResetPassword(ctx, resetPassword.Username, resetPassword.Password, resetPassword.ConfirmationCode)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+		err := conn.Cognito.ResetPassword(ctx, resetPassword.Username, resetPassword.Password, resetPassword.ConfirmationCode)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "reset password telah sukses"})
+		ctx.JSON(http.StatusOK, gin.H{"message": "reset password telah sukses"})
+	}
 }
 
-// Add User To Group godoc
+// RequestRoleHandler godoc
 //
-// @Summary Add user to group process
-// @Description do add user to group
+// @Summary Request role process
+// @Description do request a role, retail is granted right away while other roles wait for an admin
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param addUserToGroup body dtos.UpdateGroup true "user add to group"
-// @Success 200 {object} dtos.MessagesResponses "user success to add group"
+// @Param roleRequest body dtos.RoleRequest true "role to request"
+// @Success 201 {object} models.RoleRequests "the role request"
 // @Failure 400 {string} string "Error Bad request"
-// @Router /auth/add-user-groups [post]
+// @Failure 409 {string} string "request already pending"
+// @Router /auth/role-requests [post]
 // @Security Bearer
-func AddUserToGroup(redisClient *redis.Client) gin.HandlerFunc {
+func RequestRole(db *sql.DB, conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var addUserToGroup dtos.UpdateGroup
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
+		var roleData dtos.RoleRequest
+
+		if err := ctx.ShouldBindJSON(&roleData); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-		token := splitted[1]
+		principal := GetPrincipal(ctx)
 
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+		if roleData.Role != RoleRetail && roleData.Role != RoleGrosir {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "role tidak dapat diminta"})
 			return
 		}
 
-		if err := ctx.BindJSON(&addUserToGroup); err != nil {
-			ctx.JSON(http.StatusBadRequest, err.Error())
+		if principal.HasRole(roleData.Role) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("pengguna sudah berada di group %s", roleData.Role)})
 			return
 		}
 
-		err = aws.NewConnect().Cognito.AddUserToGroup(ctx, *output.Username, addUserToGroup.Groups)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		request := models.RoleRequests{
+			UserID: principal.Username,
+			Role:   roleData.Role,
+			Reason: roleData.Reason,
+		}
+
+		// every customer may shop, only selling needs a review
+		if roleData.Role == RoleRetail {
+			if err := conn.Cognito.AddUserToGroup(ctx, principal.Username, roleData.Role); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			now := time.Now().Unix()
+			request.Status = models.RoleRequestApproved
+			request.Reviewer = "system"
+			request.ReviewedAt = &now
+		}
+
+		if err := request.Insert(db); err != nil {
+			if errors.Is(err, models.ErrRoleRequestExists) {
+				ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("pengguna telah ditambahkan ke group %s", addUserToGroup.Groups)})
+		ctx.JSON(http.StatusCreated, gin.H{"request": request})
 	}
+}
+
+// MyRoleRequestsHandler godoc
+//
+// @Summary List own role requests process
+// @Description do list the role requests of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {array} models.RoleRequests "role requests"
+// @Router /auth/role-requests [get]
+// @Security Bearer
+func MyRoleRequests(db *sql.DB) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var request models.RoleRequests
+
+		result, err := request.GetAll(GetPrincipal(ctx).Username, "", db)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
+		ctx.JSON(http.StatusOK, gin.H{"requests": result})
+	}
 }
 
 // @Summary list group process
@@ -461,28 +438,9 @@ func AddUserToGroup(redisClient *redis.Client) gin.HandlerFunc {
 // @Failure 400 {string} string "Error Bad request"
 // @Router /auth/list-groups [get]
 // @Security Bearer
-func GetGroups() gin.HandlerFunc {
+func GetGroups(conn *aws.AwsConnect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		splitted := strings.Split(authHeader, " ")
-
-		token := splitted[1]
-
-		output, err := aws.NewConnect().Cognito.GetUsername(ctx, token)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
-			return
-		}
-
-		err = helpers.CheckAccountAdmin(output.Username)
-		if err != nil {
-			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
-			return
-		}
-
-		resp, err := aws.NewConnect().Cognito.ListGroup()
+		resp, err := conn.Cognito.ListGroups(ctx)
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
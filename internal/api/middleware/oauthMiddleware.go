@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/cognito"
+)
+
+var oauthErrorPage = template.Must(template.New("oauth-error").Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>Login gagal</title>
+</head>
+<body>
+<h1>Login gagal</h1>
+<p>{{.Message}}</p>
+<p><a href="{{.Retry}}">Coba login kembali</a></p>
+</body>
+</html>
+`))
+
+// renderOAuthError shows a page instead of JSON, the callback is opened by
+// the browser and not by the app.
+func renderOAuthError(ctx *gin.Context, status int, message string) {
+	ctx.Status(status)
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+
+	err := oauthErrorPage.Execute(ctx.Writer, gin.H{
+		"Message": message,
+		"Retry":   "/v1/auth/google",
+	})
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// LoginGAHandler godoc
+//
+// @Summary Login google access process
+// @Description redirect to the hosted UI to login with google
+// @Tags auth
+// @Success 302 {string} string "redirect to the hosted UI"
+// @Failure 500 {string} string "login could not be started"
+// @Router /auth/google [get]
+func LoginGA(states *cache.OAuthStateStore, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		state, err := cognito.NewState()
+		if err != nil {
+			log.Println(err.Error())
+			renderOAuthError(ctx, http.StatusInternalServerError, "login tidak dapat dimulai, silahkan coba lagi")
+			return
+		}
+
+		verifier, err := cognito.NewPKCEVerifier()
+		if err != nil {
+			log.Println(err.Error())
+			renderOAuthError(ctx, http.StatusInternalServerError, "login tidak dapat dimulai, silahkan coba lagi")
+			return
+		}
+
+		if err := states.Save(ctx, state, verifier); err != nil {
+			log.Println(err.Error())
+			renderOAuthError(ctx, http.StatusInternalServerError, "login tidak dapat dimulai, silahkan coba lagi")
+			return
+		}
+
+		ctx.Redirect(http.StatusFound, conn.GoogleAuth.AuthCodeURL(state, verifier))
+	}
+}
+
+// CallbackHandler godoc
+//
+// @Summary Hosted UI callback
+// @Description exchange the authorization code and start a session
+// @Tags auth
+// @Param code query string true "authorization code"
+// @Param state query string true "state sent to the hosted UI"
+// @Success 302 {string} string "redirect to the post login url"
+// @Failure 400 {string} string "invalid or expired login"
+// @Router /auth/callback [get]
+func CallbackCognito(db *sql.DB, sessions *cache.SessionStore, states *cache.OAuthStateStore, conn *aws.AwsConnect, postLoginURL string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if reason := ctx.Query("error"); reason != "" {
+			log.Printf("hosted ui login failed: %s %s", reason, ctx.Query("error_description"))
+			renderOAuthError(ctx, http.StatusBadRequest, "login dibatalkan atau ditolak oleh penyedia akun")
+			return
+		}
+
+		state := ctx.Query("state")
+		code := ctx.Query("code")
+		if state == "" || code == "" {
+			renderOAuthError(ctx, http.StatusBadRequest, "parameter login tidak lengkap")
+			return
+		}
+
+		verifier, err := states.Take(ctx, state)
+		if err != nil {
+			if !errors.Is(err, cache.ErrStateNotFound) {
+				log.Println(err.Error())
+			}
+			renderOAuthError(ctx, http.StatusBadRequest, "sesi login telah kadaluarsa, silahkan login kembali")
+			return
+		}
+
+		result, err := conn.GoogleAuth.Exchange(ctx, code, verifier)
+		if err != nil {
+			var retrieveErr *oauth2.RetrieveError
+			if errors.As(err, &retrieveErr) {
+				log.Printf("token exchange failed: %d %s", retrieveErr.Response.StatusCode, retrieveErr.Body)
+			} else {
+				log.Println(err.Error())
+			}
+			renderOAuthError(ctx, http.StatusBadGateway, "login gagal diproses, silahkan coba lagi")
+			return
+		}
+
+		_, claims, err := startSession(ctx, db, sessions, conn, result)
+		if err != nil {
+			log.Println(err.Error())
+			renderOAuthError(ctx, http.StatusUnauthorized, "token login tidak valid")
+			return
+		}
+
+		setAccessCookie(ctx, result.AccessToken, claims.Expiry())
+
+		ctx.Redirect(http.StatusFound, postLoginURL)
+	}
+}
@@ -0,0 +1,337 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"payuoge.com/configs"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/cognito"
+)
+
+// fakeRedis answers the few commands the state store sends, HELLO is refused
+// so the client stays on RESP2.
+type fakeRedis struct {
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func startFakeRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &fakeRedis{values: map[string]string{}, expires: map[string]time.Time{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() {
+		client.Close()
+		listener.Close()
+	})
+	return client
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		key := args[1]
+		s.values[key] = args[2]
+		delete(s.expires, key)
+		if len(args) == 5 {
+			n, _ := strconv.Atoi(args[4])
+			unit := time.Second
+			if strings.EqualFold(args[3], "px") {
+				unit = time.Millisecond
+			}
+			s.expires[key] = time.Now().Add(time.Duration(n) * unit)
+		}
+		return "+OK\r\n"
+	case "GETDEL":
+		key := args[1]
+		value, ok := s.values[key]
+		if expiry, set := s.expires[key]; set && time.Now().After(expiry) {
+			ok = false
+		}
+		delete(s.values, key)
+		delete(s.expires, key)
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// fakeTokenEndpoint stands in for the hosted UI, it accepts code only with
+// the verifier of challenge.
+type fakeTokenEndpoint struct {
+	mu        sync.Mutex
+	code      string
+	challenge string
+	exchanges int
+	verified  bool
+}
+
+func (f *fakeTokenEndpoint) setChallenge(challenge string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.challenge = challenge
+}
+
+func (f *fakeTokenEndpoint) calls() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.exchanges, f.verified
+}
+
+func (f *fakeTokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	clientId, _, ok := r.BasicAuth()
+	if r.URL.Path != "/oauth2/token" || !ok || clientId != "client" || r.ParseForm() != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_request"}`)
+		return
+	}
+
+	f.exchanges++
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("code") != f.code ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != f.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_grant"}`)
+		return
+	}
+
+	f.verified = true
+	io.WriteString(w, `{"access_token":"not-a-jwt","id_token":"not-a-jwt","token_type":"Bearer","expires_in":3600}`)
+}
+
+type oauthTest struct {
+	router   *gin.Engine
+	states   *cache.OAuthStateStore
+	endpoint *fakeTokenEndpoint
+}
+
+func newOAuthTest(t *testing.T, stateTTL time.Duration) *oauthTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	endpoint := &fakeTokenEndpoint{code: "good-code"}
+	server := httptest.NewServer(endpoint)
+	t.Cleanup(server.Close)
+
+	conn := &aws.AwsConnect{
+		GoogleAuth: cognito.NewGoogleAuthenticator(configs.GoogleAuthConfig{
+			Domain:      server.URL,
+			RedirectURL: "http://localhost/v1/auth/callback",
+			Scopes:      []string{"openid"},
+		}, "client", "secret"),
+		Verifier: cognito.NewTokenVerifier(server.URL, "client", cognito.NewStaticKeySource(&cognito.JSONWebKeySet{}), 0),
+	}
+	states := cache.NewOAuthStateStore(startFakeRedis(t), stateTTL)
+
+	router := gin.New()
+	router.GET("/auth/google", LoginGA(states, conn))
+	router.GET("/auth/callback", CallbackCognito(nil, nil, states, conn, "http://localhost/home"))
+
+	return &oauthTest{router: router, states: states, endpoint: endpoint}
+}
+
+func (o *oauthTest) get(target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	o.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func (o *oauthTest) callback(state, code string) *httptest.ResponseRecorder {
+	return o.get("/auth/callback?" + url.Values{"state": {state}, "code": {code}}.Encode())
+}
+
+// login starts a login and returns the state sent to the hosted UI.
+func (o *oauthTest) login(t *testing.T) string {
+	t.Helper()
+
+	w := o.get("/auth/google")
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want %d", w.Code, http.StatusFound)
+	}
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	if location.Path != "/oauth2/authorize" || query.Get("state") == "" || query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("login redirect = %s", location)
+	}
+
+	o.endpoint.setChallenge(query.Get("code_challenge"))
+	return query.Get("state")
+}
+
+func TestCallbackExchangesWithVerifier(t *testing.T) {
+	o := newOAuthTest(t, time.Minute)
+	state := o.login(t)
+
+	// the fake tokens are no JWTs, so the login stops once they are verified
+	if w := o.callback(state, "good-code"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("callback status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if exchanges, verified := o.endpoint.calls(); exchanges != 1 || !verified {
+		t.Fatalf("exchanges = %d verified = %v, want 1 verified exchange", exchanges, verified)
+	}
+
+	// a state can be used only once
+	if w := o.callback(state, "good-code"); w.Code != http.StatusBadRequest {
+		t.Fatalf("reused state status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if exchanges, _ := o.endpoint.calls(); exchanges != 1 {
+		t.Fatalf("exchanges = %d, want 1", exchanges)
+	}
+}
+
+func TestCallbackRejectsWrongVerifier(t *testing.T) {
+	o := newOAuthTest(t, time.Minute)
+	o.login(t)
+
+	if err := o.states.Save(context.Background(), "forged-state", "other-verifier"); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := o.callback("forged-state", "good-code"); w.Code != http.StatusBadGateway {
+		t.Fatalf("callback status = %d, want %d", w.Code, http.StatusBadGateway)
+	}
+	if exchanges, verified := o.endpoint.calls(); exchanges != 1 || verified {
+		t.Fatalf("exchanges = %d verified = %v, want 1 refused exchange", exchanges, verified)
+	}
+}
+
+func TestCallbackRejectsStateMismatch(t *testing.T) {
+	o := newOAuthTest(t, time.Minute)
+	state := o.login(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown state", url.Values{"state": {"other-state"}, "code": {"good-code"}}.Encode()},
+		{"missing state", url.Values{"code": {"good-code"}}.Encode()},
+		{"missing code", url.Values{"state": {state}}.Encode()},
+		{"denied by provider", url.Values{"state": {state}, "error": {"access_denied"}}.Encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := o.get("/auth/callback?" + tt.query); w.Code != http.StatusBadRequest {
+				t.Fatalf("callback status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+
+	if exchanges, _ := o.endpoint.calls(); exchanges != 0 {
+		t.Fatalf("exchanges = %d, want 0", exchanges)
+	}
+
+	// the mismatches did not consume the state of the started login
+	if w := o.callback(state, "good-code"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("callback status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCallbackRejectsExpiredState(t *testing.T) {
+	o := newOAuthTest(t, 50*time.Millisecond)
+	state := o.login(t)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if w := o.callback(state, "good-code"); w.Code != http.StatusBadRequest {
+		t.Fatalf("callback status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if exchanges, _ := o.endpoint.calls(); exchanges != 0 {
+		t.Fatalf("exchanges = %d, want 0", exchanges)
+	}
+}
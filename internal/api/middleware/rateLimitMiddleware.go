@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/pkg/cache"
+)
+
+// quotaDefault is the quota key for callers without a known role.
+const quotaDefault = "default"
+
+// quotaFor returns the highest quota among the roles of the caller.
+func quotaFor(principal *Principal, quotas map[string]int) int {
+	limit := quotas[quotaDefault]
+	for _, group := range principal.Groups {
+		if quota, ok := quotas[group]; ok && quota > limit {
+			limit = quota
+		}
+	}
+	return limit
+}
+
+// RateLimit limits the requests of a route group per window. Behind Auth the
+// caller is counted by username with the quota of its role, on public routes
+// by client IP with the default quota. The X-RateLimit-Reset header holds the
+// seconds until a request leaves the window. A quota of zero disables the
+// limit.
+func RateLimit(limiter *cache.RateLimiter, group string, quotas map[string]int, window time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := GetPrincipal(ctx)
+
+		subject := "ip:" + ctx.ClientIP()
+		if principal.Username != "" {
+			subject = "user:" + principal.Username
+		}
+
+		limit := quotaFor(principal, quotas)
+		if limit <= 0 {
+			ctx.Next()
+			return
+		}
+
+		result := limiter.Allow(ctx, group+":"+subject, limit, window)
+		reset := retryAfterSeconds(result.Reset)
+
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.FormatInt(reset, 10))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "rate limit exceeded",
+				"retry_after": reset,
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/internal/api/helpers"
+)
+
+type Permission string
+
+const (
+	PermProductRead      Permission = "product:read"
+	PermProductWrite     Permission = "product:write"
+	PermCategoryWrite    Permission = "category:write"
+	PermSizeWrite        Permission = "size:write"
+	PermOperationalWrite Permission = "operational:write"
+	PermCartWrite        Permission = "cart:write"
+	PermCheckoutWrite    Permission = "checkout:write"
+	PermOrderWrite       Permission = "order:write"
+	PermOrderConfirm     Permission = "order:confirm"
+	PermGroupRead        Permission = "group:read"
+	PermSessionRevoke    Permission = "session:revoke"
+	PermLockoutManage    Permission = "lockout:manage"
+	PermUserManage       Permission = "user:manage"
+	PermAPIKeyManage     Permission = "apikey:manage"
+	PermPricingWrite     Permission = "pricing:write"
+	PermStockWrite       Permission = "stock:write"
+	PermTaxonomyManage   Permission = "taxonomy:manage"
+)
+
+// roles are the cognito groups known to the policy
+const (
+	RoleRetail = "retail"
+	RoleGrosir = "grosir"
+	RoleAdmin  = "admin"
+)
+
+// Policy is the single source of truth for what each role may do. Read-only
+// endpoints only require an authenticated user and are not listed here, see
+// Baseline.
+var Policy = map[string][]Permission{
+	RoleRetail: {
+		PermCartWrite,
+		PermCheckoutWrite,
+		PermOrderWrite,
+	},
+	RoleGrosir: {
+		PermProductWrite,
+		PermCategoryWrite,
+		PermSizeWrite,
+		PermOperationalWrite,
+		PermOrderConfirm,
+		PermAPIKeyManage,
+		PermPricingWrite,
+		PermStockWrite,
+	},
+	RoleAdmin: {
+		PermGroupRead,
+		PermSessionRevoke,
+		PermLockoutManage,
+		PermUserManage,
+		PermCategoryWrite,
+		PermTaxonomyManage,
+	},
+}
+
+// Baseline is granted to every signed in user whatever their groups, it only
+// matters for API keys, which must hold a scope for it.
+var Baseline = []Permission{
+	PermProductRead,
+}
+
+const ContextPrincipal = "principal"
+
+// Principal is the authenticated caller, set by Auth from the verified token
+// or API key. A principal of an API key is limited to Permissions, the
+// scopes of the key, instead of the policy of its owner's groups.
+type Principal struct {
+	Username    string
+	Email       string
+	Groups      []string
+	SessionID   string
+	APIKeyID    int64
+	Permissions []Permission
+}
+
+func hasPermission(permissions []Permission, permission Permission) bool {
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) HasRole(roles ...string) bool {
+	for _, group := range p.Groups {
+		for _, role := range roles {
+			if group == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	if p.APIKeyID != 0 {
+		return hasPermission(p.Permissions, permission)
+	}
+
+	if hasPermission(Baseline, permission) {
+		return true
+	}
+
+	for _, group := range p.Groups {
+		if hasPermission(Policy[group], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPrincipal returns the caller set by Auth, handlers behind Auth can rely
+// on it being present.
+func GetPrincipal(ctx *gin.Context) *Principal {
+	value, ok := ctx.Get(ContextPrincipal)
+	if !ok {
+		return &Principal{}
+	}
+	return value.(*Principal)
+}
+
+func forbidden(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": helpers.ErrPermission.Error()})
+}
+
+// RequireRoles allows the request when the caller belongs to any of roles.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !GetPrincipal(ctx).HasRole(roles...) {
+			forbidden(ctx)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequirePermissions allows the request when the caller's roles grant every
+// permission in permissions.
+func RequirePermissions(permissions ...Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := GetPrincipal(ctx)
+		for _, permission := range permissions {
+			if !principal.Can(permission) {
+				forbidden(ctx)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/internal/api/models"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/cognito"
+)
+
+// newSession describes the device the request comes from, clients may name
+// themselves with the X-Device-Name header.
+func newSession(ctx *gin.Context, username string) *cache.Session {
+	return &cache.Session{
+		Username:  username,
+		Device:    ctx.GetHeader("X-Device-Name"),
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+	}
+}
+
+// requestToken returns the bearer token of the request, falling back to the
+// access_token cookie set at login.
+func requestToken(ctx *gin.Context) string {
+	splitted := strings.Split(ctx.GetHeader("Authorization"), " ")
+	if len(splitted) == 2 && strings.ToLower(splitted[0]) == "bearer" {
+		return splitted[1]
+	}
+
+	cookie, err := ctx.Request.Cookie("access_token")
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func setAccessCookie(ctx *gin.Context, accessToken string, expires time.Time) {
+	cookie := http.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		HttpOnly: true,
+		Secure:   true,
+		Path:     "/",
+		Expires:  expires,
+	}
+	http.SetCookie(ctx.Writer, &cookie)
+}
+
+// startSession verifies the tokens of a fresh sign in and stores them as a
+// new session of the user. The email comes from the id token, which must
+// belong to the same user as the access token. The profile of a first time
+// user is created here.
+func startSession(ctx *gin.Context, db *sql.DB, sessions *cache.SessionStore, conn *aws.AwsConnect, result *cognito.AuthResult) (*cache.Session, *cognito.Claims, error) {
+	claims, err := conn.Verifier.Verify(ctx, result.AccessToken, "access")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := newSession(ctx, claims.User())
+
+	if result.IdToken != "" {
+		idClaims, err := conn.Verifier.Verify(ctx, result.IdToken, "id")
+		if err != nil {
+			return nil, nil, err
+		}
+		if idClaims.Subject != claims.Subject {
+			return nil, nil, cognito.ErrInvalidToken
+		}
+		session.Email = idClaims.Email
+	}
+
+	if err := sessions.Create(ctx, session, result.RefreshToken, claims.JTI, claims.Expiry()); err != nil {
+		return nil, nil, err
+	}
+
+	// a missing profile must not block the login, it is created on next use
+	var profile models.Profiles
+	if err := profile.Ensure(session.Username, session.Email, db); err != nil {
+		log.Println(err.Error())
+	}
+
+	return session, claims, nil
+}
+
+// RefreshHandler godoc
+//
+// @Summary Refresh access token
+// @Description issue a new access token for the session of a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body dtos.RefreshData true "refresh token from login"
+// @Success 200 {object} dtos.LoginResponse "new access token"
+// @Failure 401 {string} string "invalid refresh token"
+// @Router /auth/refresh [post]
+func Refresh(sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var refreshData dtos.RefreshData
+		if err := ctx.ShouldBindJSON(&refreshData); err != nil || refreshData.RefreshToken == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "refreshToken is required"})
+			return
+		}
+
+		session, err := sessions.FindByRefreshToken(ctx, refreshData.RefreshToken)
+		if err != nil {
+			if !errors.Is(err, cache.ErrSessionNotFound) {
+				log.Println(err.Error())
+			}
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": cognito.ErrRefreshToken.Error()})
+			return
+		}
+
+		result, err := conn.Cognito.RefreshToken(ctx, session.Username, refreshData.RefreshToken)
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		claims, err := conn.Verifier.Verify(ctx, result.AccessToken, "access")
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := sessions.Rotate(ctx, session, claims.JTI, claims.Expiry()); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		setAccessCookie(ctx, result.AccessToken, claims.Expiry())
+
+		ctx.JSON(http.StatusOK, dtos.LoginResponse{
+			IdToken:   result.AccessToken,
+			SessionID: session.ID,
+			ExpiresIn: result.ExpiresIn,
+		})
+	}
+}
+
+// ListSessionsHandler godoc
+//
+// @Summary List sessions
+// @Description list the signed in devices of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {array} dtos.SessionResponse "sessions of the user"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /auth/sessions [get]
+// @Security Bearer
+func ListSessions(sessions *cache.SessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := GetPrincipal(ctx)
+
+		list, err := sessions.List(ctx, principal.Username)
+		if err != nil {
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := make([]dtos.SessionResponse, len(list))
+		for i, session := range list {
+			response[i] = dtos.SessionResponse{
+				ID:         session.ID,
+				Device:     session.Device,
+				UserAgent:  session.UserAgent,
+				IP:         session.IP,
+				CreatedAt:  session.CreatedAt,
+				LastUsedAt: session.LastUsedAt,
+				Current:    session.ID == principal.SessionID,
+			}
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// RevokeSessionHandler godoc
+//
+// @Summary Revoke session
+// @Description sign out one device of the current user
+// @Tags auth
+// @Produce json
+// @Param id path string true "session id"
+// @Success 200 {object} dtos.MessagesResponses "session revoked"
+// @Failure 404 {string} string "session not found"
+// @Router /auth/sessions/{id} [delete]
+// @Security Bearer
+func RevokeSession(sessions *cache.SessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal := GetPrincipal(ctx)
+
+		err := sessions.Revoke(ctx, principal.Username, ctx.Param("id"))
+		if err != nil {
+			if errors.Is(err, cache.ErrSessionNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			log.Println(err.Error())
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+	}
+}
+
+// LogoutAllHandler godoc
+//
+// @Summary Logout from every device
+// @Description global sign out, every session and token of the user is revoked
+// @Tags auth
+// @Produce json
+// @Success 200 {object} dtos.MessagesResponses "logged out from every device"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /auth/logout-all [post]
+// @Security Bearer
+func LogoutAll(sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := GetPrincipal(ctx).Username
+
+		if err := signOutEverywhere(ctx, sessions, conn, username); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		setAccessCookie(ctx, "", time.Now().Add(-1*time.Hour))
+		ctx.JSON(http.StatusOK, gin.H{"message": "Logged out from every device"})
+	}
+}
+
+// ForceLogoutHandler godoc
+//
+// @Summary Force logout a user
+// @Description revoke every session and token of a compromised account
+// @Tags auth
+// @Produce json
+// @Param username path string true "username of the account"
+// @Success 200 {object} dtos.MessagesResponses "user logged out"
+// @Failure 403 {string} string "permission denied"
+// @Router /auth/users/{username}/logout [post]
+// @Security Bearer
+func ForceLogout(sessions *cache.SessionStore, conn *aws.AwsConnect) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		username := ctx.Param("username")
+
+		if err := signOutEverywhere(ctx, sessions, conn, username); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		log.Printf("%s forced logout of %s", GetPrincipal(ctx).Username, username)
+		ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("%s logged out from every device", username)})
+	}
+}
+
+// signOutEverywhere invalidates the refresh tokens at the identity provider
+// and revokes the access tokens still held by the user's sessions.
+func signOutEverywhere(ctx *gin.Context, sessions *cache.SessionStore, conn *aws.AwsConnect, username string) error {
+	if err := conn.Cognito.SignOut(ctx, username); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	count, err := sessions.RevokeAll(ctx, username)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	log.Printf("revoked %d sessions of %s", count, username)
+	return nil
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"payuoge.com/dtos"
+	"payuoge.com/pkg/cache"
+)
+
+// Throttles groups the limiters of the public auth endpoints. Login counts
+// wrong credentials, Code counts every request that sends an email code.
+type Throttles struct {
+	Login *cache.Throttle
+	Code  *cache.Throttle
+}
+
+func (t *Throttles) all() []*cache.Throttle {
+	return []*cache.Throttle{t.Login, t.Code}
+}
+
+func retryAfterSeconds(wait time.Duration) int64 {
+	return int64(math.Ceil(wait.Seconds()))
+}
+
+func tooManyRequests(ctx *gin.Context, wait time.Duration) {
+	seconds := retryAfterSeconds(wait)
+
+	ctx.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       "terlalu banyak percobaan, silahkan coba lagi nanti",
+		"retry_after": seconds,
+	})
+}
+
+// throttled answers 429 when email or the client IP has to wait. Redis
+// errors are logged and let the request through.
+func throttled(ctx *gin.Context, throttle *cache.Throttle, email string) bool {
+	wait, err := throttle.Check(ctx, email, ctx.ClientIP())
+	if err != nil {
+		log.Println(err.Error())
+		return false
+	}
+
+	if wait > 0 {
+		tooManyRequests(ctx, wait)
+		return true
+	}
+	return false
+}
+
+func recordFailure(ctx *gin.Context, throttle *cache.Throttle, email string) {
+	if _, err := throttle.Record(ctx, email, ctx.ClientIP()); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// LockoutStatusHandler godoc
+//
+// @Summary Lockout status
+// @Description show the failed attempts and lockout of an account
+// @Tags auth
+// @Produce json
+// @Param username path string true "email of the account"
+// @Success 200 {array} dtos.LockoutResponse "lockout per action"
+// @Failure 403 {string} string "permission denied"
+// @Router /auth/users/{username}/lockout [get]
+// @Security Bearer
+func LockoutStatus(throttles *Throttles) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		email := ctx.Param("username")
+
+		response := make([]dtos.LockoutResponse, 0, 2)
+		for _, throttle := range throttles.all() {
+			status, err := throttle.Status(ctx, email)
+			if err != nil {
+				log.Println(err.Error())
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			response = append(response, dtos.LockoutResponse{
+				Action:     throttle.Action(),
+				Failures:   status.Failures,
+				Locked:     status.Locked,
+				RetryAfter: retryAfterSeconds(status.RetryAfter),
+			})
+		}
+
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// UnlockHandler godoc
+//
+// @Summary Unlock account
+// @Description clear the failed attempts and lockout of an account
+// @Tags auth
+// @Produce json
+// @Param username path string true "email of the account"
+// @Success 200 {object} dtos.MessagesResponses "account unlocked"
+// @Failure 403 {string} string "permission denied"
+// @Router /auth/users/{username}/lockout [delete]
+// @Security Bearer
+func Unlock(throttles *Throttles) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		email := ctx.Param("username")
+
+		for _, throttle := range throttles.all() {
+			if err := throttle.Unlock(ctx, email); err != nil {
+				log.Println(err.Error())
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		log.Printf("%s unlocked %s", GetPrincipal(ctx).Username, email)
+		ctx.JSON(http.StatusOK, gin.H{"message": "account unlocked"})
+	}
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// apiKeyPrefix marks the keys so they are recognised in headers and leaks.
+const apiKeyPrefix = "pk_"
+
+// lastUsedPrecision avoids a write on every request of a busy key.
+const lastUsedPrecision = 60
+
+// APIKeys is a key minted by a user for scripts and terminals. Only the
+// sha256 of the key is stored, Prefix is what the owner sees to tell keys
+// apart.
+type APIKeys struct {
+	ID         int64          `json:"id"`
+	OwnerID    string         `json:"owner_id"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`
+	Scopes     pq.StringArray `json:"scopes" swaggertype:"array,string"`
+	ExpiresAt  *int64         `json:"expires_at,omitempty"`
+	LastUsedAt *int64         `json:"last_used_at,omitempty"`
+	RevokedAt  *int64         `json:"revoked_at,omitempty"`
+	CreatedAt  int64          `json:"created_at"`
+}
+
+// NewAPIKey returns a random key and its display prefix.
+func NewAPIKey() (string, string, error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(secret), prefix, nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Usable reports whether the key is neither revoked nor expired.
+func (key *APIKeys) Usable(now time.Time) bool {
+	if key.RevokedAt != nil {
+		return false
+	}
+	return key.ExpiresAt == nil || now.Unix() < *key.ExpiresAt
+}
+
+const apiKeyColumns = `
+    id, owner_id, name, prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+    `
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }, key *APIKeys) error {
+	return row.Scan(
+		&key.ID,
+		&key.OwnerID,
+		&key.Name,
+		&key.Prefix,
+		&key.Scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+}
+
+// Insert stores the key under the hash of plainKey.
+func (key *APIKeys) Insert(plainKey string, db *sql.DB) error {
+	query := `
+    INSERT INTO api_keys(
+    owner_id,
+    name,
+    prefix,
+    key_hash,
+    scopes,
+    expires_at,
+    created_at
+    ) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+    )
+    RETURNING id
+    `
+
+	key.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		key.OwnerID,
+		key.Name,
+		key.Prefix,
+		HashAPIKey(plainKey),
+		key.Scopes,
+		key.ExpiresAt,
+		key.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&key.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetByKey returns the key matching plainKey, revoked and expired keys
+// included.
+func (key *APIKeys) GetByKey(plainKey string, db *sql.DB) (*APIKeys, error) {
+	query := `
+    SELECT` + apiKeyColumns + `FROM api_keys
+    WHERE key_hash = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := scanAPIKey(db.QueryRowContext(ctx, query, HashAPIKey(plainKey)), key)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (key *APIKeys) GetAll(ownerId string, db *sql.DB) ([]APIKeys, error) {
+	query := `
+    SELECT` + apiKeyColumns + `FROM api_keys
+    WHERE owner_id = $1
+    ORDER BY created_at DESC
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, ownerId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []APIKeys
+	for rows.Next() {
+		var each APIKeys
+		if err := scanAPIKey(rows, &each); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// Touch records that the key was used, at most once per lastUsedPrecision.
+func (key *APIKeys) Touch(db *sql.DB) error {
+	query := `
+    UPDATE api_keys
+    SET last_used_at = $1
+    WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+    `
+
+	now := time.Now().Unix()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, query, now, key.ID, now-lastUsedPrecision)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (key *APIKeys) Revoke(id int64, ownerId string, db *sql.DB) error {
+	query := `
+    UPDATE api_keys
+    SET revoked_at = $1
+    WHERE id = $2 AND owner_id = $3 AND revoked_at IS NULL
+    `
+
+	args := []interface{}{
+		time.Now().Unix(),
+		id,
+		ownerId,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll revokes every live key of ownerId, it returns the number of keys
+// revoked.
+func (key *APIKeys) RevokeAll(ownerId string, db *sql.DB) (int64, error) {
+	query := `
+    UPDATE api_keys
+    SET revoked_at = $1
+    WHERE owner_id = $2 AND revoked_at IS NULL
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, time.Now().Unix(), ownerId)
+	if err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
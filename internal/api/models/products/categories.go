@@ -3,69 +3,293 @@ package products
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"payuoge.com/internal/api/models"
 )
 
+// maxCategoryDepth is how many levels a category tree may have, such as
+// Minuman > Kopi > Kopi Sachet.
+const maxCategoryDepth = 5
+
+var (
+	ErrInvalidParent       = errors.New("parent category tidak ditemukan, membentuk siklus atau melebihi 5 tingkat")
+	ErrCategoryHasChildren = errors.New("category masih memiliki subcategory")
+	ErrCategoryInUse       = errors.New("category masih digunakan produk")
+)
+
+// CategoryProducts is a node of the category tree. Global categories are the
+// taxonomy shared by every grocery, the others are private to the grocery in
+// UserID. A private category may sit under a global one, a global category
+// only under another global one.
 type CategoryProducts struct {
-	ID          int       `json:"id"`
-	UserID      string    `json:"user_id,omitempty"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Products    []Product `json:"product,omitempty"`
+	ID          int                `json:"id"`
+	UserID      string             `json:"user_id,omitempty"`
+	ParentID    *int               `json:"parent_id,omitempty"`
+	Global      bool               `json:"global"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Products    []Product          `json:"product,omitempty"`
+	Children    []CategoryProducts `json:"children,omitempty"`
 }
 
+// categoryScope matches the categories of the owner in placeholder, global
+// ones when the owner is empty.
+func categoryScope(placeholder string) string {
+	return fmt.Sprintf(`(CASE WHEN %s::text = '' THEN global ELSE NOT global AND user_id = %s END)`, placeholder, placeholder)
+}
+
+// categoryAncestors is the category in $4 and its ancestors, at most one
+// more than the $6 levels a tree may have so a cycle can not recurse forever.
+const categoryAncestors = `
+    up AS (
+    SELECT id, parent_id, 1 AS depth FROM category_products WHERE id = $4
+    UNION ALL
+    SELECT c.id, c.parent_id, up.depth + 1 FROM category_products c INNER JOIN up ON c.id = up.parent_id
+    WHERE up.depth <= $6
+    )
+    `
+
+// categorySubtree selects the category in ? and its descendants, down to
+// maxCategoryDepth levels.
+var categorySubtree = fmt.Sprintf(`
+    WITH RECURSIVE down AS (
+    SELECT id, 1 AS depth FROM category_products WHERE id = ?
+    UNION ALL
+    SELECT c.id, down.depth + 1 FROM category_products c INNER JOIN down ON c.parent_id = down.id
+    WHERE down.depth < %d
+    )
+    SELECT id FROM down
+    `, maxCategoryDepth)
+
+// Insert adds the category of userId, a parent must be global or, for a
+// private category, belong to userId too.
 func (category *CategoryProducts) Insert(db *sql.DB, userId string) error {
 	query := `
+    WITH RECURSIVE` + categoryAncestors + `
     INSERT INTO category_products(
     name,
     user_id,
-    Description
+    description,
+    parent_id,
+    global
+    )
+    SELECT $1, $2, $3, $4, $5
+    WHERE $4::integer IS NULL OR (
+    EXISTS (
+    SELECT 1 FROM category_products p
+    WHERE p.id = $4 AND p.deleted_at IS NULL AND (p.global OR NOT $5 AND p.user_id = $2)
+    ) AND (SELECT COUNT(*) FROM up) < $6
     )
-    VALUES($1, $2, $3)
+    RETURNING id
     `
 	args := []interface{}{
 		category.Name,
 		userId,
 		category.Description,
+		category.ParentID,
+		category.Global,
+		maxCategoryDepth,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := db.ExecContext(ctx, query, args...)
+	err := db.QueryRowContext(ctx, query, args...).Scan(&category.ID)
+	if err == sql.ErrNoRows {
+		return ErrInvalidParent
+	}
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
+	category.UserID = userId
 	return nil
 }
 
-func (category *CategoryProducts) GetAll(db *sql.DB) ([]CategoryProducts, error) {
+var categorySorting = models.Sorting{
+	Keys: map[string]string{
+		"name": "name",
+		"id":   "id",
+	},
+	Default: "name",
+	ID:      "id",
+}
+
+// GetAll returns one page of the global categories and the private ones of
+// the grocery, the children of parentId only when it is not 0.
+func (category *CategoryProducts) GetAll(groceryId string, parentId int, params models.ListParams, db *sql.DB) ([]CategoryProducts, *models.PageInfo, error) {
+	q := models.NewQuery().Where("(global OR user_id = ?)", groceryId).Where("deleted_at IS NULL")
+	if parentId != 0 {
+		q.Where("parent_id = ?", parentId)
+	}
+
+	page, err := q.Page(params, categorySorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := "FROM category_products"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL("id, name, description, parent_id, global", from)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	result := []CategoryProducts{}
+	for rows.Next() {
+		var each = CategoryProducts{}
+		var sortKey interface{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.Name,
+			&each.Description,
+			&each.ParentID,
+			&each.Global,
+			&sortKey,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		if !page.Keep(sortKey, int64(each.ID)) {
+			break
+		}
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
+}
+
+// Tree returns the global categories and the private ones of the grocery as
+// trees, roots and children sorted by name.
+func (category *CategoryProducts) Tree(groceryId string, db *sql.DB) ([]CategoryProducts, error) {
 	query := `
-    SELECT id, name, description FROM category_products
-	`
+    SELECT id, name, description, parent_id, global
+    FROM category_products
+    WHERE (global OR user_id = $1) AND deleted_at IS NULL
+    ORDER BY name, id
+    `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query, groceryId)
 	if err != nil {
 		log.Println(err.Error())
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []CategoryProducts
+	var all []CategoryProducts
 	for rows.Next() {
 		var each = CategoryProducts{}
 		var err = rows.Scan(
 			&each.ID,
 			&each.Name,
 			&each.Description,
+			&each.ParentID,
+			&each.Global,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		all = append(all, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	children := map[int][]CategoryProducts{}
+	visible := map[int]bool{}
+	for _, each := range all {
+		visible[each.ID] = true
+	}
+	for _, each := range all {
+		parent := 0
+		// a category under a category the grocery can not see becomes a root
+		if each.ParentID != nil && visible[*each.ParentID] {
+			parent = *each.ParentID
+		}
+		children[parent] = append(children[parent], each)
+	}
+
+	var build func(parent int) []CategoryProducts
+	build = func(parent int) []CategoryProducts {
+		nodes := children[parent]
+		for i := range nodes {
+			nodes[i].Children = build(nodes[i].ID)
+		}
+		return nodes
+	}
+
+	result := build(0)
+	if result == nil {
+		result = []CategoryProducts{}
+	}
+	return result, nil
+}
+
+// Breadcrumb returns the path from the root to the category, the category
+// last. The category must be global or belong to the grocery.
+func (category *CategoryProducts) Breadcrumb(id int, groceryId string, db *sql.DB) ([]CategoryProducts, error) {
+	query := `
+    WITH RECURSIVE up AS (
+    SELECT id, name, description, parent_id, global, 0 AS depth
+    FROM category_products
+    WHERE id = $1 AND (global OR user_id = $2) AND deleted_at IS NULL
+    UNION ALL
+    SELECT c.id, c.name, c.description, c.parent_id, c.global, up.depth + 1
+    FROM category_products c INNER JOIN up ON c.id = up.parent_id
+    WHERE up.depth < $3
+    )
+    SELECT id, name, description, parent_id, global FROM up
+    ORDER BY depth DESC
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, id, groceryId, maxCategoryDepth)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []CategoryProducts{}
+	for rows.Next() {
+		var each = CategoryProducts{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.Name,
+			&each.Description,
+			&each.ParentID,
+			&each.Global,
 		)
 		if err != nil {
 			log.Println(err.Error())
@@ -74,12 +298,22 @@ func (category *CategoryProducts) GetAll(db *sql.DB) ([]CategoryProducts, error)
 
 		result = append(result, each)
 	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return nil, models.ErrRecordNotFound
+	}
 	return result, nil
 }
 
 func (category *CategoryProducts) Get(id int, db *sql.DB) (*CategoryProducts, error) {
 	query := `
-    SELECT id, name, description FROM category_products WHERE id = $1
+    SELECT id, user_id, name, description, parent_id, global FROM category_products
+    WHERE id = $1 AND deleted_at IS NULL
     `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -91,8 +325,11 @@ func (category *CategoryProducts) Get(id int, db *sql.DB) (*CategoryProducts, er
 	}
 	if err := db.QueryRowContext(ctx, query, args...).Scan(
 		&category.ID,
+		&category.UserID,
 		&category.Name,
 		&category.Description,
+		&category.ParentID,
+		&category.Global,
 	); err != nil {
 		log.Println(err.Error())
 		return nil, err
@@ -101,57 +338,158 @@ func (category *CategoryProducts) Get(id int, db *sql.DB) (*CategoryProducts, er
 	return category, nil
 }
 
-func (category *CategoryProducts) Update(id int, userId string, db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		log.Fatal(err)
+// Visible returns ErrRecordNotFound unless the category is global or belongs
+// to the grocery.
+func (category *CategoryProducts) Visible(id int, groceryId string, db *sql.DB) error {
+	query := `
+    SELECT EXISTS(
+    SELECT 1 FROM category_products WHERE id = $1 AND (global OR user_id = $2) AND deleted_at IS NULL
+    )
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, id, groceryId).Scan(&exists); err != nil {
+		log.Println(err.Error())
+		return err
 	}
 
+	if !exists {
+		return models.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Update changes the category of owner, a global one when owner is empty.
+// A new parent must not be the category or one of its descendants, and the
+// tree must stay within maxCategoryDepth levels.
+func (category *CategoryProducts) Update(id int, owner string, db *sql.DB) error {
 	query := `
+    WITH RECURSIVE` + categoryAncestors + `, down AS (
+    SELECT id, 1 AS depth FROM category_products WHERE id = $3
+    UNION ALL
+    SELECT c.id, down.depth + 1 FROM category_products c INNER JOIN down ON c.parent_id = down.id
+    WHERE down.depth <= $6
+    )
     UPDATE category_products
     SET name = $1,
-    description = $2
-    WHERE id = $3 AND user_id = $4
-    RETURNING id
+    description = $2,
+    parent_id = $4
+    WHERE id = $3 AND deleted_at IS NULL AND ` + categoryScope("$5") + ` AND ($4::integer IS NULL OR (
+    NOT EXISTS (SELECT 1 FROM down WHERE id = $4)
+    AND EXISTS (
+    SELECT 1 FROM category_products p
+    WHERE p.id = $4 AND p.deleted_at IS NULL
+    AND (p.global OR NOT category_products.global AND p.user_id = category_products.user_id)
+    )
+    AND (SELECT COUNT(*) FROM up) + (SELECT MAX(depth) FROM down) <= $6
+    ))
     `
 
 	args := []interface{}{
 		category.Name,
 		category.Description,
 		id,
-		userId,
+		category.ParentID,
+		owner,
+		maxCategoryDepth,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	// a concurrent move of the new parent or one of its ancestors under the
+	// category waits for this one, the cycle check then sees it
+	if _, err := tx.ExecContext(ctx, `
+    WITH RECURSIVE up AS (
+    SELECT id, parent_id, 1 AS depth FROM category_products WHERE id = $2
+    UNION ALL
+    SELECT c.id, c.parent_id, up.depth + 1 FROM category_products c INNER JOIN up ON c.id = up.parent_id
+    WHERE up.depth <= $3
+    )
+    SELECT id FROM category_products
+    WHERE id = $1 OR id IN (SELECT id FROM up)
+    ORDER BY id
+    FOR UPDATE
+    `, id, category.ParentID, maxCategoryDepth); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		tx.Rollback()
 		log.Println(err.Error())
 		return err
 	}
 
-	err = tx.Commit()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		if err := category.owned(ctx, id, owner, db); err != nil {
+			return err
+		}
+		return ErrInvalidParent
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
 		return err
 	}
 
 	return nil
 }
 
-func (category *CategoryProducts) Delete(id int, userId string, db *sql.DB) error {
+// owned returns ErrRecordNotFound unless the category belongs to owner, or
+// is global when owner is empty.
+func (category *CategoryProducts) owned(ctx context.Context, id int, owner string, db *sql.DB) error {
 	query := `
-    DELETE FROM category_products
-    WHERE id = $1 AND user_id = $2
+    SELECT EXISTS(
+    SELECT 1 FROM category_products WHERE id = $1 AND deleted_at IS NULL AND ` + categoryScope("$2") + `
+    )
+    `
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, id, owner).Scan(&exists); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if !exists {
+		return models.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete moves the category of owner, a global one when owner is empty, to
+// the trash. A category with subcategories or products not in the trash can
+// not be deleted.
+func (category *CategoryProducts) Delete(id int, owner string, db *sql.DB) error {
+	query := `
+    UPDATE category_products
+    SET deleted_at = $3
+    WHERE id = $1 AND deleted_at IS NULL AND ` + categoryScope("$2") + `
+    AND NOT EXISTS (SELECT 1 FROM category_products c WHERE c.parent_id = $1 AND c.deleted_at IS NULL)
+    AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = $1 AND p.deleted_at IS NULL)
     `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	args := []interface{}{
-		id, userId,
+		id, owner, time.Now().Unix(),
 	}
 
 	result, err := db.ExecContext(ctx, query, args...)
@@ -167,8 +505,30 @@ func (category *CategoryProducts) Delete(id int, userId string, db *sql.DB) erro
 	}
 
 	if rowsAffected == 0 {
-		return models.ErrRecordNotFound
+		if err := category.owned(ctx, id, owner, db); err != nil {
+			return err
+		}
+		return category.deleteError(ctx, id, db)
 	}
 
 	return nil
 }
+
+// deleteError tells whether subcategories or products kept Delete from
+// deleting the category.
+func (category *CategoryProducts) deleteError(ctx context.Context, id int, db *sql.DB) error {
+	query := `
+    SELECT EXISTS(SELECT 1 FROM category_products WHERE parent_id = $1 AND deleted_at IS NULL)
+    `
+
+	var hasChildren bool
+	if err := db.QueryRowContext(ctx, query, id).Scan(&hasChildren); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if hasChildren {
+		return ErrCategoryHasChildren
+	}
+	return ErrCategoryInUse
+}
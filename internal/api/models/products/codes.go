@@ -0,0 +1,75 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+var (
+	ErrProductCodeExists = errors.New("grosir sudah memiliki produk dengan product_code tersebut")
+	ErrBarcodeChecksum   = errors.New("check digit barcode EAN-13/UPC tidak valid")
+)
+
+// CheckCode trims a product code or barcode. A code of 12 or 13 digits is
+// taken for a UPC-A or EAN-13 barcode and must have a valid check digit.
+func CheckCode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if len(code) != 12 && len(code) != 13 {
+		return code, nil
+	}
+
+	sum := 0
+	for i, r := range code {
+		if r < '0' || r > '9' {
+			return code, nil
+		}
+		// weights alternate 3 and 1 from the digit before the check digit
+		weight := 1
+		if (len(code)-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+
+	if sum%10 != 0 {
+		return code, ErrBarcodeChecksum
+	}
+	return code, nil
+}
+
+// FindCode returns the product of the grocery with the code, or the product
+// and its variant when the code is the barcode of one of its variants.
+func FindCode(groceryId, code string, db *sql.DB) (int64, *int64, error) {
+	query := `
+    SELECT id, NULL::bigint FROM products
+    WHERE user_id = $1 AND product_code = $2 AND deleted_at IS NULL
+    UNION ALL
+    SELECT v.product_id, v.id FROM product_variants v
+    INNER JOIN products p ON v.product_id = p.id
+    WHERE p.user_id = $1 AND v.barcode = $2 AND p.deleted_at IS NULL
+    ORDER BY 2 NULLS FIRST
+    LIMIT 1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var productId int64
+	var variantId *int64
+	err := db.QueryRowContext(ctx, query, groceryId, strings.TrimSpace(code)).Scan(&productId, &variantId)
+	if err == sql.ErrNoRows {
+		return 0, nil, models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return 0, nil, err
+	}
+
+	return productId, variantId, nil
+}
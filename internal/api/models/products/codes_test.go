@@ -0,0 +1,48 @@
+package products
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+		err  error
+	}{
+		{"valid EAN-13", "4006381333931", "4006381333931", nil},
+		{"valid EAN-13 of another prefix", "5901234123457", "5901234123457", nil},
+		{"EAN-13 with wrong check digit", "4006381333932", "4006381333932", ErrBarcodeChecksum},
+		{"EAN-13 with swapped digits", "4003681333931", "4003681333931", ErrBarcodeChecksum},
+		{"valid UPC-A", "036000291452", "036000291452", nil},
+		{"valid UPC-A of another prefix", "012345678905", "012345678905", nil},
+		{"UPC-A with wrong check digit", "036000291453", "036000291453", ErrBarcodeChecksum},
+		{"UPC-A read as EAN-13 with leading zero", "0036000291452", "0036000291452", nil},
+		{"trimmed before the check", " 4006381333931\n", "4006381333931", nil},
+		{"trimmed wrong barcode", "  036000291453 ", "036000291453", ErrBarcodeChecksum},
+		// only UPC-A and EAN-13 are checked, shorter numbers are internal codes
+		{"8 digit code with EAN-8 check digit", "96385074", "96385074", nil},
+		{"8 digit code without EAN-8 check digit", "96385075", "96385075", nil},
+		{"11 digits", "03600029145", "03600029145", nil},
+		{"14 digits", "40063813339310", "40063813339310", nil},
+		{"internal code", "SKU-001", "SKU-001", nil},
+		{"12 characters not all digits", "ABC000291452", "ABC000291452", nil},
+		{"13 characters with a letter", "400638133393X", "400638133393X", nil},
+		{"13 characters with a space inside", "400638 333931", "400638 333931", nil},
+		{"empty", "  ", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckCode(tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CheckCode(%q) error = %v, want %v", tt.code, err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("CheckCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
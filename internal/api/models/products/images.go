@@ -0,0 +1,291 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"payuoge.com/internal/api/models"
+)
+
+var (
+	ErrImageOrder    = errors.New("urutan harus berisi semua gambar produk tepat satu kali")
+	ErrTooManyImages = errors.New("produk sudah memiliki jumlah gambar maksimal")
+)
+
+// ProductImages is one picture of a product, Position 0 is the cover shown as
+// the product picture.
+type ProductImages struct {
+	ID           int64  `json:"id"`
+	ProductID    int64  `json:"product_id"`
+	Position     int    `json:"position"`
+	ObjectKey    string `json:"-"`
+	ThumbnailKey string `json:"-"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// syncPicture copies the url of the cover image to products.picture.
+func syncPicture(ctx context.Context, tx *sql.Tx, productId int64) error {
+	query := `
+    UPDATE products
+    SET picture = COALESCE((
+    SELECT url FROM product_images
+    WHERE product_id = $1
+    ORDER BY position, id
+    LIMIT 1
+    ), '')
+    WHERE id = $1
+    `
+
+	_, err := tx.ExecContext(ctx, query, productId)
+	return err
+}
+
+// Insert adds the image after the last image of the product, unless the
+// product already has maxImages images.
+func (image *ProductImages) Insert(maxImages int, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := image.InsertTx(maxImages, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertTx is Insert within tx, the product stays locked until tx ends so
+// concurrent uploads can not pass maxImages together.
+func (image *ProductImages) InsertTx(maxImages int, tx *sql.Tx) error {
+	query := `
+    INSERT INTO product_images(
+    product_id,
+    position,
+    object_key,
+    thumbnail_key,
+    url,
+    thumbnail_url,
+    content_type,
+    size,
+    width,
+    height,
+    created_at
+    ) VALUES (
+    $1,
+    (SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1),
+    $2, $3, $4, $5, $6, $7, $8, $9, $10
+    )
+    RETURNING id, position
+    `
+
+	image.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		image.ProductID,
+		image.ObjectKey,
+		image.ThumbnailKey,
+		image.URL,
+		image.ThumbnailURL,
+		image.ContentType,
+		image.Size,
+		image.Width,
+		image.Height,
+		image.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var count int
+	err := tx.QueryRowContext(ctx, `
+    SELECT (SELECT COUNT(*) FROM product_images WHERE product_id = p.id)
+    FROM products p
+    WHERE p.id = $1 AND p.deleted_at IS NULL
+    FOR UPDATE
+    `, image.ProductID).Scan(&count)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	if count >= maxImages {
+		return ErrTooManyImages
+	}
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&image.ID, &image.Position); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if err := syncPicture(ctx, tx, image.ProductID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+const imageColumns = `
+    id, product_id, position, object_key, thumbnail_key, url, thumbnail_url,
+    content_type, size, width, height, created_at
+    `
+
+func (image *ProductImages) GetAll(productId int64, db *sql.DB) ([]ProductImages, error) {
+	query := `
+    SELECT` + imageColumns + `FROM product_images
+    WHERE product_id = $1
+    ORDER BY position, id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, productId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []ProductImages{}
+	for rows.Next() {
+		var each = ProductImages{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.ProductID,
+			&each.Position,
+			&each.ObjectKey,
+			&each.ThumbnailKey,
+			&each.URL,
+			&each.ThumbnailURL,
+			&each.ContentType,
+			&each.Size,
+			&each.Width,
+			&each.Height,
+			&each.CreatedAt,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// Delete removes an image of the product and returns it, so its objects can
+// be removed from the store.
+func (image *ProductImages) Delete(id, productId int64, db *sql.DB) (*ProductImages, error) {
+	query := `
+    DELETE FROM product_images
+    WHERE id = $1 AND product_id = $2
+    RETURNING object_key, thumbnail_key
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, id, productId).Scan(&image.ObjectKey, &image.ThumbnailKey)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	if err := syncPicture(ctx, tx, productId); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	image.ID = id
+	image.ProductID = productId
+	return image, nil
+}
+
+// Reorder sets the position of every image of the product to its index in
+// ids, which must list each image once.
+func (image *ProductImages) Reorder(productId int64, ids []int64, db *sql.DB) error {
+	query := `
+    UPDATE product_images i
+    SET position = o.position - 1
+    FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+    WHERE i.id = o.id AND i.product_id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM product_images WHERE product_id = $1`, productId).Scan(&count); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	if len(ids) != count || len(seen) != count {
+		return ErrImageOrder
+	}
+
+	result, err := tx.ExecContext(ctx, query, productId, pq.Array(ids))
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	if int(rowsAffected) != count {
+		return ErrImageOrder
+	}
+
+	if err := syncPicture(ctx, tx, productId); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
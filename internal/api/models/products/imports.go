@@ -0,0 +1,611 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+// statuses of import jobs
+const (
+	ImportPending = "pending"
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed"
+)
+
+// ProductColumns are the columns of a product import or export, in order.
+// Category and size type are referenced by name.
+var ProductColumns = []string{
+	"product_code",
+	"product_name",
+	"category",
+	"size_type",
+	"buy_price",
+	"min_retail_price",
+	"quantity",
+	"position",
+	"active",
+}
+
+// optionalColumns may be left out of an import, quantity defaults to 0 and
+// active to true.
+var optionalColumns = map[string]bool{
+	"quantity": true,
+	"position": true,
+	"active":   true,
+}
+
+// importProgressEvery is how many rows are written between progress updates.
+const importProgressEvery = 25
+
+var ErrImportMapping = errors.New("mapping hanya boleh berisi kolom product_code, product_name, category, size_type, buy_price, min_retail_price, quantity, position dan active")
+
+// ImportError is a problem of one row of an import, Row counts from 1 at the
+// header like a spreadsheet does.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRow is a product read from a row of an import.
+type ImportRow struct {
+	Row      int
+	Product  Product
+	Category string
+	SizeType string
+}
+
+// ImportJobs imports the products of a grocery from a file in the
+// background, upserting them by product_code. A dry run only validates the
+// rows and counts what would be created and updated. A job with invalid rows
+// writes nothing.
+type ImportJobs struct {
+	ID            int64         `json:"id"`
+	GroceryID     string        `json:"grocery_id"`
+	Filename      string        `json:"filename"`
+	Format        string        `json:"format"`
+	DryRun        bool          `json:"dry_run"`
+	Status        string        `json:"status"`
+	TotalRows     int           `json:"total_rows"`
+	ProcessedRows int           `json:"processed_rows"`
+	CreatedRows   int           `json:"created_rows"`
+	UpdatedRows   int           `json:"updated_rows"`
+	Errors        []ImportError `json:"errors"`
+	CreatedAt     int64         `json:"created_at"`
+	FinishedAt    *int64        `json:"finished_at,omitempty"`
+}
+
+// Parse reads the products of rows, the first row being the header, and
+// counts them in the job. mapping names the header of a column when it
+// differs from the column, such as {"product_code": "Kode"}. Rows with
+// unreadable values are left out and reported in the errors of the job.
+func (job *ImportJobs) Parse(rows [][]string, mapping map[string]string) ([]ImportRow, error) {
+	for column := range mapping {
+		if !isProductColumn(column) {
+			return nil, ErrImportMapping
+		}
+	}
+
+	header := map[string]int{}
+	for i, name := range rows[0] {
+		header[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	index := map[string]int{}
+	for _, column := range ProductColumns {
+		name := column
+		if mapped, ok := mapping[column]; ok {
+			name = mapped
+		}
+		i, ok := header[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if optionalColumns[column] {
+				continue
+			}
+			return nil, fmt.Errorf("kolom %s tidak ditemukan pada header", name)
+		}
+		index[column] = i
+	}
+
+	var result []ImportRow
+	job.TotalRows = 0
+	for n, row := range rows[1:] {
+		if blank(row) {
+			continue
+		}
+		job.TotalRows++
+
+		value := func(column string) string {
+			if i, ok := index[column]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		each := ImportRow{
+			Row:      n + 2,
+			Category: value("category"),
+			SizeType: value("size_type"),
+			Product: Product{
+				ProductCode: value("product_code"),
+				ProductName: value("product_name"),
+				Position:    value("position"),
+				Active:      true,
+			},
+		}
+
+		var rowErrs []ImportError
+		fail := func(column, message string) {
+			rowErrs = append(rowErrs, ImportError{Row: each.Row, Column: column, Message: message})
+		}
+
+		for _, column := range []string{"product_code", "product_name", "category", "size_type"} {
+			if value(column) == "" {
+				fail(column, "wajib diisi")
+			}
+		}
+
+		if _, err := CheckCode(each.Product.ProductCode); err != nil {
+			fail("product_code", err.Error())
+		}
+
+		if price, err := wholeNumber(value("buy_price"), math.MaxInt32); err != nil {
+			fail("buy_price", err.Error())
+		} else {
+			each.Product.BuyPrice = int32(price)
+		}
+
+		if price, err := wholeNumber(value("min_retail_price"), math.MaxInt32); err != nil {
+			fail("min_retail_price", err.Error())
+		} else {
+			each.Product.MRP = int32(price)
+		}
+
+		if quantity := value("quantity"); quantity != "" {
+			if n, err := wholeNumber(quantity, MaxOpeningQuantity); err != nil {
+				fail("quantity", err.Error())
+			} else {
+				each.Product.Quantity = n
+			}
+		}
+
+		if active := value("active"); active != "" {
+			switch strings.ToLower(active) {
+			case "true", "1", "ya", "yes", "aktif":
+				each.Product.Active = true
+			case "false", "0", "tidak", "no", "nonaktif":
+				each.Product.Active = false
+			default:
+				fail("active", "harus true atau false")
+			}
+		}
+
+		if len(rowErrs) > 0 {
+			job.Errors = append(job.Errors, rowErrs...)
+			continue
+		}
+		result = append(result, each)
+	}
+
+	return result, nil
+}
+
+func isProductColumn(column string) bool {
+	for _, each := range ProductColumns {
+		if each == column {
+			return true
+		}
+	}
+	return false
+}
+
+func blank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// wholeNumber reads a number from 0 to max, spreadsheets may write whole
+// numbers as 1500.0.
+func wholeNumber(value string, max float64) (int64, error) {
+	if value == "" {
+		return 0, errors.New("wajib diisi")
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n != math.Trunc(n) {
+		return 0, errors.New("harus bilangan bulat")
+	}
+	if n < 0 || n > max {
+		return 0, fmt.Errorf("harus antara 0 dan %.0f", max)
+	}
+	return int64(n), nil
+}
+
+func (job *ImportJobs) Insert(db *sql.DB) error {
+	query := `
+    INSERT INTO import_jobs(grocery_id, filename, format, dry_run, status, total_rows, errors, created_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING id
+    `
+
+	job.Status = ImportPending
+	job.CreatedAt = time.Now().Unix()
+	if job.Errors == nil {
+		job.Errors = []ImportError{}
+	}
+
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{
+		job.GroceryID,
+		job.Filename,
+		job.Format,
+		job.DryRun,
+		job.Status,
+		job.TotalRows,
+		string(errs),
+		job.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&job.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (job *ImportJobs) Get(id int64, groceryId string, db *sql.DB) (*ImportJobs, error) {
+	query := `
+    SELECT id, grocery_id, filename, format, dry_run, status, total_rows,
+    processed_rows, created_rows, updated_rows, errors, created_at, finished_at
+    FROM import_jobs
+    WHERE id = $1 AND grocery_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var errs []byte
+	err := db.QueryRowContext(ctx, query, id, groceryId).Scan(
+		&job.ID,
+		&job.GroceryID,
+		&job.Filename,
+		&job.Format,
+		&job.DryRun,
+		&job.Status,
+		&job.TotalRows,
+		&job.ProcessedRows,
+		&job.CreatedRows,
+		&job.UpdatedRows,
+		&errs,
+		&job.CreatedAt,
+		&job.FinishedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	if err := json.Unmarshal(errs, &job.Errors); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// save stores the progress of the job, finishing it unless it is running.
+func (job *ImportJobs) save(db *sql.DB) error {
+	query := `
+    UPDATE import_jobs
+    SET status = $1,
+    processed_rows = $2,
+    created_rows = $3,
+    updated_rows = $4,
+    errors = $5,
+    finished_at = $6
+    WHERE id = $7
+    `
+
+	if job.Status != ImportRunning {
+		finished := time.Now().Unix()
+		job.FinishedAt = &finished
+	}
+
+	errs, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{
+		job.Status,
+		job.ProcessedRows,
+		job.CreatedRows,
+		job.UpdatedRows,
+		string(errs),
+		job.FinishedAt,
+		job.ID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// importLookups resolves the names and codes of the rows of a grocery.
+type importLookups struct {
+	categories map[string]int
+	sizeTypes  map[string]int
+	products   map[string][]int64
+}
+
+func loadImportLookups(groceryId string, db *sql.DB) (*importLookups, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lookups := &importLookups{
+		categories: map[string]int{},
+		sizeTypes:  map[string]int{},
+		products:   map[string][]int64{},
+	}
+
+	queries := []struct {
+		query string
+		args  []interface{}
+		add   func(rows *sql.Rows) error
+	}{
+		// private categories come last to win over a global one of the same name
+		{`SELECT id, name FROM category_products WHERE (global OR user_id = $1) AND deleted_at IS NULL ORDER BY global DESC`, []interface{}{groceryId}, func(rows *sql.Rows) error {
+			var id int
+			var name string
+			err := rows.Scan(&id, &name)
+			lookups.categories[strings.ToLower(name)] = id
+			return err
+		}},
+		// size type names are unique across groceries
+		{`SELECT id, name FROM size_type WHERE deleted_at IS NULL`, nil, func(rows *sql.Rows) error {
+			var id int
+			var name string
+			err := rows.Scan(&id, &name)
+			lookups.sizeTypes[strings.ToLower(name)] = id
+			return err
+		}},
+		{`SELECT id, product_code FROM products WHERE user_id = $1 AND deleted_at IS NULL`, []interface{}{groceryId}, func(rows *sql.Rows) error {
+			var id int64
+			var code string
+			err := rows.Scan(&id, &code)
+			lookups.products[code] = append(lookups.products[code], id)
+			return err
+		}},
+	}
+
+	for _, each := range queries {
+		rows, err := db.QueryContext(ctx, each.query, each.args...)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+		for rows.Next() {
+			if err := each.add(rows); err != nil {
+				rows.Close()
+				log.Println(err.Error())
+				return nil, err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+	}
+
+	return lookups, nil
+}
+
+// validate resolves the category and size type of every row and finds the
+// product each row updates, 0 for a new one.
+func (lookups *importLookups) validate(rows []ImportRow) ([]int64, []ImportError) {
+	var errs []ImportError
+	targets := make([]int64, len(rows))
+	seen := map[string]int{}
+
+	for i := range rows {
+		row := &rows[i]
+
+		id, ok := lookups.categories[strings.ToLower(row.Category)]
+		if !ok {
+			errs = append(errs, ImportError{Row: row.Row, Column: "category", Message: fmt.Sprintf("category %s tidak ditemukan", row.Category)})
+		}
+		row.Product.CategoryId = id
+
+		id, ok = lookups.sizeTypes[strings.ToLower(row.SizeType)]
+		if !ok {
+			errs = append(errs, ImportError{Row: row.Row, Column: "size_type", Message: fmt.Sprintf("size type %s tidak ditemukan", row.SizeType)})
+		}
+		row.Product.SizeTypeId = id
+
+		code := row.Product.ProductCode
+		if first, ok := seen[code]; ok {
+			errs = append(errs, ImportError{Row: row.Row, Column: "product_code", Message: fmt.Sprintf("product_code %s duplikat dengan baris %d", code, first)})
+		}
+		seen[code] = row.Row
+
+		switch existing := lookups.products[code]; len(existing) {
+		case 0:
+		case 1:
+			targets[i] = existing[0]
+		default:
+			errs = append(errs, ImportError{Row: row.Row, Column: "product_code", Message: fmt.Sprintf("product_code %s dipakai oleh %d produk", code, len(existing))})
+		}
+	}
+
+	return targets, errs
+}
+
+// Run validates and writes the rows, recording its progress in the job. An
+// existing product keeps its picture, defective count and stock, the quantity
+// of a row is the opening stock of new products only.
+func (job *ImportJobs) Run(rows []ImportRow, db *sql.DB) {
+	job.Status = ImportRunning
+	if err := job.save(db); err != nil {
+		return
+	}
+
+	lookups, err := loadImportLookups(job.GroceryID, db)
+	if err != nil {
+		job.Status = ImportFailed
+		job.Errors = append(job.Errors, ImportError{Message: err.Error()})
+		job.save(db)
+		return
+	}
+
+	targets, errs := lookups.validate(rows)
+	job.Errors = append(job.Errors, errs...)
+	sort.SliceStable(job.Errors, func(i, j int) bool {
+		return job.Errors[i].Row < job.Errors[j].Row
+	})
+
+	// invalid rows fail the whole job before anything is written
+	if len(job.Errors) > 0 && !job.DryRun {
+		job.ProcessedRows = job.TotalRows
+		job.Status = ImportFailed
+		job.save(db)
+		return
+	}
+
+	if job.DryRun {
+		for _, target := range targets {
+			if target == 0 {
+				job.CreatedRows++
+			} else {
+				job.UpdatedRows++
+			}
+		}
+		job.ProcessedRows = job.TotalRows
+		job.Status = ImportDone
+		job.save(db)
+		return
+	}
+
+	for i, row := range rows {
+		if err := importRow(row, targets[i], job.GroceryID, db); err != nil {
+			job.Errors = append(job.Errors, ImportError{Row: row.Row, Message: err.Error()})
+		} else if targets[i] == 0 {
+			job.CreatedRows++
+		} else {
+			job.UpdatedRows++
+		}
+
+		job.ProcessedRows++
+		if job.ProcessedRows%importProgressEvery == 0 {
+			job.save(db)
+		}
+	}
+
+	job.Status = ImportDone
+	job.save(db)
+}
+
+func importRow(row ImportRow, target int64, groceryId string, db *sql.DB) error {
+	product := row.Product
+	if target == 0 {
+		return product.Insert(db, product.SizeTypeId, product.CategoryId, groceryId)
+	}
+
+	existing, err := Product{}.Get(target, db)
+	if err != nil {
+		return err
+	}
+
+	existing.ProductName = product.ProductName
+	existing.Position = product.Position
+	existing.SizeTypeId = product.SizeTypeId
+	existing.CategoryId = product.CategoryId
+	existing.BuyPrice = product.BuyPrice
+	existing.MRP = product.MRP
+	existing.Active = product.Active
+	return existing.Update(db, target, groceryId)
+}
+
+// ExportRows returns the products of the grocery as the rows of an import,
+// header first. The quantity of a product is its stock in its size type.
+func ExportRows(groceryId string, db *sql.DB) ([][]string, error) {
+	query := `
+    SELECT p.product_code, p.product_name, c.name, s.name, p.buy_price, p.mrp,
+    p.base_stock / s.factor, p.position, p.active
+    FROM products p
+    INNER JOIN category_products c ON p.category_id = c.id
+    INNER JOIN size_type s ON p.size_type_id = s.id
+    WHERE p.user_id = $1 AND p.deleted_at IS NULL
+    ORDER BY p.product_code, p.id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, groceryId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := [][]string{ProductColumns}
+	for rows.Next() {
+		var code, name, category, sizeType string
+		var position sql.NullString
+		var buyPrice, mrp sql.NullInt32
+		var quantity int64
+		var active bool
+		err := rows.Scan(&code, &name, &category, &sizeType, &buyPrice, &mrp, &quantity, &position, &active)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, []string{
+			code,
+			name,
+			category,
+			sizeType,
+			strconv.Itoa(int(buyPrice.Int32)),
+			strconv.Itoa(int(mrp.Int32)),
+			strconv.FormatInt(quantity, 10),
+			position.String,
+			strconv.FormatBool(active),
+		})
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,386 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"payuoge.com/internal/api/models"
+)
+
+var (
+	ErrCustomerGroupExists = errors.New("grosir sudah memiliki customer group dengan nama tersebut")
+	ErrPriceTierExists     = errors.New("varian sudah memiliki tier dengan min_quantity dan group tersebut")
+	ErrInvalidGroup        = errors.New("customer group tidak ditemukan pada grosir pemilik produk")
+)
+
+func uniqueError(err, exists error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return exists
+	}
+	log.Println(err.Error())
+	return err
+}
+
+// CustomerGroups is a price list of a grocery, such as loyal retailers, its
+// members get the price tiers of the group.
+type CustomerGroups struct {
+	ID        int64  `json:"id"`
+	GroceryID string `json:"grocery_id"`
+	Name      string `json:"name"`
+	Members   int    `json:"members"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type CustomerGroupMembers struct {
+	GroupID    int64  `json:"group_id"`
+	CustomerID string `json:"customer_id"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func (group *CustomerGroups) Insert(db *sql.DB) error {
+	query := `
+    INSERT INTO customer_groups(grocery_id, name, created_at)
+    VALUES ($1, $2, $3)
+    RETURNING id
+    `
+
+	group.CreatedAt = time.Now().Unix()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, query, group.GroceryID, group.Name, group.CreatedAt).Scan(&group.ID)
+	if err != nil {
+		return uniqueError(err, ErrCustomerGroupExists)
+	}
+
+	return nil
+}
+
+func (group *CustomerGroups) GetAll(groceryId string, db *sql.DB) ([]CustomerGroups, error) {
+	query := `
+    SELECT g.id, g.grocery_id, g.name, COUNT(m.customer_id), g.created_at
+    FROM customer_groups g
+    LEFT JOIN customer_group_members m ON m.group_id = g.id
+    WHERE g.grocery_id = $1
+    GROUP BY g.id
+    ORDER BY g.name
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, groceryId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []CustomerGroups{}
+	for rows.Next() {
+		var each = CustomerGroups{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.GroceryID,
+			&each.Name,
+			&each.Members,
+			&each.CreatedAt,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// Delete removes the group of the grocery with its members and price tiers.
+func (group *CustomerGroups) Delete(id int64, groceryId string, db *sql.DB) error {
+	query := `
+    DELETE FROM customer_groups
+    WHERE id = $1 AND grocery_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, id, groceryId)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// AddMember puts the customer in the group of the grocery, adding a member
+// twice is not an error.
+func (group *CustomerGroups) AddMember(id int64, groceryId, customerId string, db *sql.DB) error {
+	query := `
+    WITH g AS (
+    SELECT id FROM customer_groups WHERE id = $1 AND grocery_id = $2
+    ), inserted AS (
+    INSERT INTO customer_group_members(group_id, customer_id, created_at)
+    SELECT id, $3, $4 FROM g
+    ON CONFLICT (group_id, customer_id) DO NOTHING
+    )
+    SELECT COUNT(*) FROM g
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var found int
+	if err := db.QueryRowContext(ctx, query, id, groceryId, customerId, time.Now().Unix()).Scan(&found); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if found == 0 {
+		return models.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (group *CustomerGroups) RemoveMember(id int64, groceryId, customerId string, db *sql.DB) error {
+	query := `
+    DELETE FROM customer_group_members m
+    USING customer_groups g
+    WHERE m.group_id = g.id AND g.id = $1 AND g.grocery_id = $2 AND m.customer_id = $3
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, id, groceryId, customerId)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return models.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (group *CustomerGroups) GetMembers(id int64, groceryId string, db *sql.DB) ([]CustomerGroupMembers, error) {
+	query := `
+    SELECT m.group_id, m.customer_id, m.created_at
+    FROM customer_group_members m
+    INNER JOIN customer_groups g ON m.group_id = g.id
+    WHERE g.id = $1 AND g.grocery_id = $2
+    ORDER BY m.customer_id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, id, groceryId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []CustomerGroupMembers{}
+	for rows.Next() {
+		var each = CustomerGroupMembers{}
+		if err := rows.Scan(&each.GroupID, &each.CustomerID, &each.CreatedAt); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// PriceTiers prices a variant at UnitPrice from MinQuantity units, for every
+// customer or, with GroupID, for the members of a customer group only.
+type PriceTiers struct {
+	ID          int64  `json:"id"`
+	VariantID   int64  `json:"variant_id"`
+	GroupID     *int64 `json:"group_id,omitempty"`
+	GroupName   string `json:"group_name,omitempty"`
+	MinQuantity int32  `json:"min_quantity"`
+	UnitPrice   int32  `json:"unit_price"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// Insert adds the tier, its group must belong to the grocery of the product.
+// The tier is recorded as a revision of the product by actor.
+func (tier *PriceTiers) Insert(actor string, db *sql.DB) error {
+	query := `
+    INSERT INTO price_tiers(variant_id, group_id, min_quantity, unit_price, created_at)
+    SELECT $1, $2, $3, $4, $5
+    WHERE $2::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM customer_groups g
+    INNER JOIN products p ON p.user_id = g.grocery_id
+    INNER JOIN product_variants v ON v.product_id = p.id
+    WHERE g.id = $2 AND v.id = $1
+    )
+    RETURNING id, (SELECT product_id FROM product_variants WHERE id = $1)
+    `
+
+	tier.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		tier.VariantID,
+		tier.GroupID,
+		tier.MinQuantity,
+		tier.UnitPrice,
+		tier.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int64
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&tier.ID, &productId)
+	if err == sql.ErrNoRows {
+		return ErrInvalidGroup
+	}
+	if err != nil {
+		return uniqueError(err, ErrPriceTierExists)
+	}
+
+	field, change := tierChange(tier, true)
+	if err := insertRevision(ctx, tx, productId, actor, map[string]RevisionChange{field: change}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAll returns the tiers of the variant, general tiers first.
+func (tier *PriceTiers) GetAll(variantId int64, db *sql.DB) ([]PriceTiers, error) {
+	query := `
+    SELECT t.id, t.variant_id, t.group_id, COALESCE(g.name, ''), t.min_quantity, t.unit_price, t.created_at
+    FROM price_tiers t
+    LEFT JOIN customer_groups g ON t.group_id = g.id
+    WHERE t.variant_id = $1
+    ORDER BY t.group_id NULLS FIRST, t.min_quantity
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, variantId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []PriceTiers{}
+	for rows.Next() {
+		var each = PriceTiers{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.VariantID,
+			&each.GroupID,
+			&each.GroupName,
+			&each.MinQuantity,
+			&each.UnitPrice,
+			&each.CreatedAt,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// Delete removes the tier, recorded as a revision of the product by actor.
+func (tier *PriceTiers) Delete(id, variantId int64, actor string, db *sql.DB) error {
+	query := `
+    DELETE FROM price_tiers t
+    USING product_variants v
+    WHERE t.id = $1 AND t.variant_id = $2 AND v.id = t.variant_id
+    RETURNING t.id, t.variant_id, t.group_id, t.min_quantity, t.unit_price, v.product_id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int64
+	err = tx.QueryRowContext(ctx, query, id, variantId).Scan(
+		&tier.ID,
+		&tier.VariantID,
+		&tier.GroupID,
+		&tier.MinQuantity,
+		&tier.UnitPrice,
+		&productId,
+	)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	field, change := tierChange(tier, false)
+	if err := insertRevision(ctx, tx, productId, actor, map[string]RevisionChange{field: change}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
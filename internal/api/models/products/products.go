@@ -3,36 +3,51 @@ package products
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
+	"math"
 	"time"
 
 	_ "github.com/lib/pq"
 	"payuoge.com/internal/api/models"
 )
 
+// MaxOpeningQuantity is the most a product may start with in its size type,
+// so the stock in base units stays within bigint for any factor.
+const MaxOpeningQuantity = math.MaxInt32
+
+var ErrOpeningQuantity = errors.New("quantity harus antara 0 dan 2147483647")
+
 type Product struct {
-	ID           int64  `json:"id"`
-	UserID       string `json:"user_id"`
-	ProductCode  string `json:"product_code,omitempty"`
-	ProductName  string `json:"product_name,omitempty"`
-	Picture      string `json:"picture,omitempty"`
-	Quantity     int16  `json:"quantity,omitempty"`
-	Position     string `json:"position,omitempty"`
-	SizeTypeId   int    `json:"size_type_id,omitempty"`
-	SizeTypeName string `json:"size_type_name,omitempty"`
-	CategoryId   int    `json:"category_id,omitempty"`
-	CategoryName string `json:"category_name,omitempty"`
-	BuyPrice     int32  `json:"buy_price,omitempty"`
-	MRP          int32  `json:"min_retail_price,omitempty"`
-	Total        int32  `json:"total_price"`
-	Defective    int32  `json:"defective,omitempty"`
-	Active       bool   `json:"active"`
-	Created      int64  `json:"created,omitempty"`
-	Updated      int64  `json:"updated,omitempty"`
+	ID            int64   `json:"id"`
+	UserID        string  `json:"user_id"`
+	ProductCode   string  `json:"product_code,omitempty"`
+	ProductName   string  `json:"product_name,omitempty"`
+	Picture       string  `json:"picture,omitempty"`
+	Quantity      int64   `json:"quantity,omitempty"`
+	BaseStock     int64   `json:"base_stock"`
+	ReservedStock int64   `json:"reserved_stock"`
+	Position      string  `json:"position,omitempty"`
+	SizeTypeId    int     `json:"size_type_id,omitempty"`
+	SizeTypeName  string  `json:"size_type_name,omitempty"`
+	CategoryId    int     `json:"category_id,omitempty"`
+	CategoryName  string  `json:"category_name,omitempty"`
+	BuyPrice      int32   `json:"buy_price,omitempty"`
+	MRP           int32   `json:"min_retail_price,omitempty"`
+	Total         int32   `json:"total_price"`
+	Defective     int32   `json:"defective,omitempty"`
+	Active        bool    `json:"active"`
+	Created       int64   `json:"created,omitempty"`
+	Updated       int64   `json:"updated,omitempty"`
+	Rank          float64 `json:"rank,omitempty"`
+	Highlight     string  `json:"highlight,omitempty"`
 }
 
+// Insert adds the product with a variant of its own size type and price,
+// quantity is received into the stock ledger in base units.
 func (product *Product) Insert(db *sql.DB, sizeTypeId, categoryId int, userId string) error {
 	query := `
+    WITH p AS (
     INSERT INTO products(
     user_id,
 	product_code,
@@ -46,9 +61,25 @@ func (product *Product) Insert(db *sql.DB, sizeTypeId, categoryId int, userId st
 	mrp, 
 	defective, 
 	active, 
+	base_stock,
 	created,
 	updated)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+	COALESCE($5 * (SELECT factor FROM size_type WHERE id = $7), 0), $13, $14)
+	RETURNING id, user_id, size_type_id, product_code, buy_price, mrp, active, base_stock, created
+    ), v AS (
+    INSERT INTO product_variants(
+    product_id, size_type_id, sku, buy_price, mrp, conversion, active, created, updated
+    )
+    SELECT p.id, p.size_type_id, p.product_code, COALESCE(p.buy_price, 0), COALESCE(p.mrp, 0),
+    s.factor, p.active, p.created, p.created
+    FROM p
+    INNER JOIN size_type s ON p.size_type_id = s.id
+    )
+    INSERT INTO stock_movements(product_id, kind, quantity, balance, reason, actor, created_at)
+    SELECT id, 'receipt', base_stock, base_stock, 'stok awal', user_id, created / 1000
+    FROM p
+    WHERE base_stock > 0
     `
 
 	times := time.Now().UnixMilli()
@@ -74,22 +105,44 @@ func (product *Product) Insert(db *sql.DB, sizeTypeId, categoryId int, userId st
 
 	_, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
-		log.Println(err.Error())
-		return err
+		return uniqueError(err, ErrProductCodeExists)
 	}
 
 	return nil
 }
 
-func (product *Product) GetAll(db *sql.DB) ([]Product, error) {
-	query := `
-    SELECT 
+// ProductFilter narrows a product listing, zero values do not filter.
+type ProductFilter struct {
+	// CategoryID matches its subcategories too
+	CategoryID int
+	SizeTypeID int
+	GroceryID  string
+	Active     *bool
+	MinPrice   int
+	MaxPrice   int
+	InStock    bool
+}
+
+var productSorting = models.Sorting{
+	Keys: map[string]string{
+		"name":    "p.product_name",
+		"price":   "COALESCE(p.mrp, 0)",
+		"created": "COALESCE(p.created, 0)",
+		"updated": "COALESCE(p.updated, 0)",
+	},
+	Default: "name",
+	ID:      "p.id",
+}
+
+const productColumns = `
     p.id,
 	p.user_id,
     p.product_code,
     p.product_name,
     p.picture,
     p.quantity,
+    p.base_stock,
+    p.reserved_stock,
 	p.position,
     s.name,
     c.name,
@@ -98,90 +151,68 @@ func (product *Product) GetAll(db *sql.DB) ([]Product, error) {
 	p.defective,
 	p.active,
 	p.created,
-	p.updated
-    FROM products p
+	p.updated`
+
+const productFrom = `FROM products p
 	INNER JOIN category_products c ON p.category_id = c.id
-	INNER JOIN size_type s ON p.size_type_id = s.id
-    `
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	INNER JOIN size_type s ON p.size_type_id = s.id`
 
-	rows, err := db.QueryContext(ctx, query)
-	if err != nil {
-		log.Println(err.Error())
-		return nil, err
+func (filter ProductFilter) query() *models.Query {
+	q := models.NewQuery().Where("p.deleted_at IS NULL")
+	if filter.CategoryID != 0 {
+		q.Where("p.category_id IN ("+categorySubtree+")", filter.CategoryID)
 	}
+	if filter.SizeTypeID != 0 {
+		q.Where("p.size_type_id = ?", filter.SizeTypeID)
+	}
+	if filter.GroceryID != "" {
+		q.Where("p.user_id = ?", filter.GroceryID)
+	}
+	if filter.Active != nil {
+		q.Where("p.active = ?", *filter.Active)
+	}
+	if filter.MinPrice > 0 {
+		q.Where("p.mrp >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		q.Where("p.mrp <= ?", filter.MaxPrice)
+	}
+	if filter.InStock {
+		q.Where("p.base_stock > p.reserved_stock")
+	}
+	return q
+}
 
-	defer rows.Close()
-
-	var result []Product
-	for rows.Next() {
-		var each = Product{}
-		var err = rows.Scan(
-			&each.ID,
-			&each.UserID,
-			&each.ProductCode,
-			&each.ProductName,
-			&each.Picture,
-			&each.Quantity,
-			&each.Position,
-			&each.SizeTypeName,
-			&each.CategoryName,
-			&each.MRP,
-			&each.BuyPrice,
-			&each.Defective,
-			&each.Active,
-			&each.Created,
-			&each.Updated,
-		)
-		if err != nil {
-			log.Println(models.ErrRecordNotFound)
-			return nil, err
-		}
+// GetAll returns one page of the products matching filter.
+func (product *Product) GetAll(filter ProductFilter, params models.ListParams, db *sql.DB) ([]Product, *models.PageInfo, error) {
+	q := filter.query()
 
-		result = append(result, each)
+	page, err := q.Page(params, productSorting)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return result, nil
-}
-
-func (product Product) GetProductsGroceries(userID string, db *sql.DB) ([]Product, error) {
-	query := `
-    SELECT 
-    p.id,
-	p.user_id,
-    p.product_code,
-    p.product_name,
-    p.picture,
-    p.quantity,
-	p.position,
-    s.name,
-    c.name,
-	p.mrp,
-	p.buy_price,
-	p.defective,
-	p.active,
-	p.created,
-	p.updated
-    FROM products p
-	INNER JOIN category_products c ON p.category_id = c.id
-	INNER JOIN size_type s ON p.size_type_id = s.id
-    WHERE p.user_id = $1
-    `
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query, userID)
+	total, err := q.Count(ctx, db, productFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL(productColumns, productFrom)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Println(err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer rows.Close()
 
-	var result []Product
+	result := []Product{}
 	for rows.Next() {
 		var each = Product{}
+		var sortKey interface{}
 		var err = rows.Scan(
 			&each.ID,
 			&each.UserID,
@@ -189,6 +220,8 @@ func (product Product) GetProductsGroceries(userID string, db *sql.DB) ([]Produc
 			&each.ProductName,
 			&each.Picture,
 			&each.Quantity,
+			&each.BaseStock,
+			&each.ReservedStock,
 			&each.Position,
 			&each.SizeTypeName,
 			&each.CategoryName,
@@ -198,17 +231,25 @@ func (product Product) GetProductsGroceries(userID string, db *sql.DB) ([]Produc
 			&each.Active,
 			&each.Created,
 			&each.Updated,
+			&sortKey,
 		)
 		if err != nil {
-			log.Println(models.ErrRecordNotFound)
-			return nil, err
+			log.Println(err.Error())
+			return nil, nil, err
 		}
 
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
 		result = append(result, each)
 	}
 
-	return result, nil
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
 
+	return result, page.Info(total), nil
 }
 
 func (product Product) Get(id int64, db *sql.DB) (*Product, error) {
@@ -220,8 +261,12 @@ func (product Product) Get(id int64, db *sql.DB) (*Product, error) {
     p.product_name,
     p.picture,
     p.quantity,
+    p.base_stock,
+    p.reserved_stock,
     p.position,
+    p.size_type_id,
     s.name,
+    p.category_id,
     c.name,
 	p.mrp,
 	p.buy_price,
@@ -232,7 +277,7 @@ func (product Product) Get(id int64, db *sql.DB) (*Product, error) {
     FROM products p
 	INNER JOIN category_products c ON p.category_id = c.id
 	INNER JOIN size_type s ON p.size_type_id = s.id
-	WHERE p.id = $1
+	WHERE p.id = $1 AND p.deleted_at IS NULL
 	LIMIT 1
     `
 
@@ -247,16 +292,22 @@ func (product Product) Get(id int64, db *sql.DB) (*Product, error) {
 		&product.ProductName,
 		&product.Picture,
 		&product.Quantity,
+		&product.BaseStock,
+		&product.ReservedStock,
 		&product.Position,
+		&product.SizeTypeId,
 		&product.SizeTypeName,
+		&product.CategoryId,
 		&product.CategoryName,
-		&product.BuyPrice,
 		&product.MRP,
+		&product.BuyPrice,
 		&product.Defective,
 		&product.Active,
 		&product.Created,
 		&product.Updated,
-	); err != nil {
+	); err == sql.ErrNoRows {
+		return nil, models.ErrRecordNotFound
+	} else if err != nil {
 		log.Println(err.Error())
 		return nil, err
 	}
@@ -264,28 +315,78 @@ func (product Product) Get(id int64, db *sql.DB) (*Product, error) {
 	return &product, nil
 }
 
+// Update changes the details of the product, its stock only changes through
+// stock movements. The prices go to the variant of the size type of the
+// product too. The changed fields are recorded as a revision by userId.
 func (product *Product) Update(db *sql.DB, id int64, userId string) error {
-	tx, err := db.Begin()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
+	defer tx.Rollback()
+
+	var before Product
+	err = tx.QueryRowContext(ctx, `
+    SELECT product_code, product_name, COALESCE(picture, ''), COALESCE(position, ''),
+    size_type_id, category_id, COALESCE(mrp, 0), COALESCE(buy_price, 0), COALESCE(defective, 0), active
+    FROM products
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    FOR UPDATE
+    `, id, userId).Scan(
+		&before.ProductCode,
+		&before.ProductName,
+		&before.Picture,
+		&before.Position,
+		&before.SizeTypeId,
+		&before.CategoryId,
+		&before.MRP,
+		&before.BuyPrice,
+		&before.Defective,
+		&before.Active,
+	)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	// the stock is counted in base units, a new size type keeps the base unit
+	if product.SizeTypeId != before.SizeTypeId {
+		var sameBase bool
+		err := tx.QueryRowContext(ctx, `
+    SELECT COALESCE(n.base_unit_id, n.id) = COALESCE(o.base_unit_id, o.id)
+    FROM size_type o, size_type n
+    WHERE o.id = $1 AND n.id = $2
+    `, before.SizeTypeId, product.SizeTypeId).Scan(&sameBase)
+		if err != nil && err != sql.ErrNoRows {
+			log.Println(err.Error())
+			return err
+		}
+		if !sameBase {
+			return ErrIncompatibleUnits
+		}
+	}
 
 	query := `
     UPDATE products
     SET product_code = $1, 
     product_name = $2,
     picture = $3,
-    quantity = $4,
-    position = $5,
-	size_type_id = $6,
-	category_id = $7,
-    mrp = $8,
-    buy_price = $9,
-    defective = $10,
-	active = $11,
-    updated = $12
-    WHERE id = $13 AND user_id =$14
+    position = $4,
+	size_type_id = $5,
+	category_id = $6,
+    mrp = $7,
+    buy_price = $8,
+    defective = $9,
+	active = $10,
+    updated = $11
+    WHERE id = $12 AND user_id =$13
     `
 
 	timeUpdate := time.Now().UnixMilli()
@@ -294,7 +395,6 @@ func (product *Product) Update(db *sql.DB, id int64, userId string) error {
 		product.ProductCode,
 		product.ProductName,
 		product.Picture,
-		product.Quantity,
 		product.Position,
 		product.SizeTypeId,
 		product.CategoryId,
@@ -307,13 +407,31 @@ func (product *Product) Update(db *sql.DB, id int64, userId string) error {
 		userId,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		tx.Rollback()
-		log.Println(err.Error())
+		return uniqueError(err, ErrProductCodeExists)
+	}
+
+	// checkout prices the variants, the variant of the size type of the
+	// product carries the prices of the product
+	if product.MRP != before.MRP || product.BuyPrice != before.BuyPrice || product.SizeTypeId != before.SizeTypeId {
+		_, err = tx.ExecContext(ctx, `
+    INSERT INTO product_variants(
+    product_id, size_type_id, sku, buy_price, mrp, conversion, active, created, updated
+    )
+    SELECT $1, s.id, $3, $4, $5, s.factor, $6, $7, $7
+    FROM size_type s
+    WHERE s.id = $2
+    ON CONFLICT (product_id, size_type_id) DO UPDATE
+    SET buy_price = EXCLUDED.buy_price, mrp = EXCLUDED.mrp, updated = EXCLUDED.updated
+    `, id, product.SizeTypeId, product.ProductCode, product.BuyPrice, product.MRP, product.Active, timeUpdate)
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+	}
+
+	if err := insertRevision(ctx, tx, id, userId, diffRevision(&before, product)); err != nil {
 		return err
 	}
 
@@ -326,31 +444,35 @@ func (product *Product) Update(db *sql.DB, id int64, userId string) error {
 	return nil
 }
 
+// Delete moves the product to the trash and takes it out of the carts, its
+// variants, images and ledger stay for a restore and past orders.
 func (product *Product) Delete(id int64, userId string, db *sql.DB) error {
 	query := `
-	DELETE FROM products
-	WHERE id = $1 AND user_id = $2
-	`
+    WITH p AS (
+    UPDATE products
+    SET deleted_at = $3
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    RETURNING id
+    ), c AS (
+    DELETE FROM carts WHERE product_id IN (SELECT id FROM p)
+    )
+    SELECT COUNT(*) FROM p
+    `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	args := []interface{}{
-		id, userId,
-	}
-	result, err := db.ExecContext(ctx, query, args...)
-	if err != nil {
-		log.Println(err.Error())
-		return err
+		id, userId, time.Now().Unix(),
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	var deleted int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&deleted); err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
-	if rowsAffected == 0 {
+	if deleted == 0 {
 		return models.ErrRecordNotFound
 	}
 	return nil
@@ -0,0 +1,293 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statuses of stock reservations
+const (
+	ReservationReserved  = "reserved"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+)
+
+var ErrReservationExpired = errors.New("reservasi stok order sudah kadaluarsa")
+
+// StockReservations holds stock of a product for an order line until the
+// grocery confirms the order, when it becomes a sale, or until the order is
+// cancelled or expires, when it is released. Quantity is in base units,
+// VariantQuantity in the unit of the variant ordered.
+type StockReservations struct {
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"order_id"`
+	ProductID       int64  `json:"product_id"`
+	VariantID       *int64 `json:"variant_id,omitempty"`
+	GroceryID       string `json:"grocery_id"`
+	Quantity        int64  `json:"quantity"`
+	VariantQuantity int64  `json:"variant_quantity"`
+	Status          string `json:"status"`
+	ExpiresAt       int64  `json:"expires_at"`
+	CreatedAt       int64  `json:"created_at"`
+}
+
+// StockShortage is an order line the available stock cannot fill, quantities
+// are in the unit of the variant ordered.
+type StockShortage struct {
+	ProductID   int64  `json:"product_id"`
+	ProductName string `json:"product_name"`
+	VariantID   int64  `json:"variant_id"`
+	Requested   int64  `json:"requested"`
+	Available   int64  `json:"available"`
+}
+
+// InsufficientStockError lists every line of an order the stock cannot fill,
+// it matches ErrInsufficientStock with errors.Is.
+type InsufficientStockError struct {
+	Items []StockShortage
+}
+
+func (e *InsufficientStockError) Error() string {
+	names := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		names = append(names, fmt.Sprintf("%s (tersedia %d, diminta %d)", item.ProductName, item.Available, item.Requested))
+	}
+	return ErrInsufficientStock.Error() + ": " + strings.Join(names, ", ")
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
+
+// Reserve holds the stock of every reservation within tx, locking their
+// products in order of id. The reservations need OrderID, ProductID,
+// VariantID, VariantQuantity and ExpiresAt. When some lines cannot be filled
+// nothing should be committed and an *InsufficientStockError lists them all.
+func Reserve(tx *sql.Tx, reservations []*StockReservations) error {
+	sorted := make([]*StockReservations, len(reservations))
+	copy(sorted, reservations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ProductID < sorted[j].ProductID
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var shortages []StockShortage
+	for _, reservation := range sorted {
+		var available, conversion int64
+		var shortage = StockShortage{
+			ProductID: reservation.ProductID,
+			Requested: reservation.VariantQuantity,
+		}
+		if reservation.VariantID != nil {
+			shortage.VariantID = *reservation.VariantID
+		}
+
+		err := tx.QueryRowContext(ctx, `
+    SELECT p.product_name, p.user_id, p.base_stock - p.reserved_stock, v.conversion
+    FROM products p
+    INNER JOIN product_variants v ON v.product_id = p.id
+    WHERE p.id = $1 AND v.id = $2 AND p.deleted_at IS NULL
+    FOR UPDATE OF p
+    `, reservation.ProductID, reservation.VariantID).Scan(&shortage.ProductName, &reservation.GroceryID, &available, &conversion)
+		if err == sql.ErrNoRows {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+
+		reservation.Quantity = reservation.VariantQuantity * conversion
+		if reservation.Quantity > available {
+			shortage.Available = available / conversion
+			shortages = append(shortages, shortage)
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+    UPDATE products SET reserved_stock = reserved_stock + $1 WHERE id = $2
+    `, reservation.Quantity, reservation.ProductID); err != nil {
+			log.Println(err.Error())
+			return err
+		}
+
+		query := `
+    INSERT INTO stock_reservations(
+    order_id,
+    product_id,
+    variant_id,
+    grocery_id,
+    quantity,
+    variant_quantity,
+    status,
+    expires_at,
+    created_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING id
+    `
+
+		reservation.Status = ReservationReserved
+		reservation.CreatedAt = time.Now().Unix()
+
+		args := []interface{}{
+			reservation.OrderID,
+			reservation.ProductID,
+			reservation.VariantID,
+			reservation.GroceryID,
+			reservation.Quantity,
+			reservation.VariantQuantity,
+			reservation.Status,
+			reservation.ExpiresAt,
+			reservation.CreatedAt,
+		}
+
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&reservation.ID); err != nil {
+			log.Println(err.Error())
+			return err
+		}
+	}
+
+	if len(shortages) > 0 {
+		return &InsufficientStockError{Items: shortages}
+	}
+	return nil
+}
+
+// heldReservations returns the reservations of the order still holding stock,
+// of one grocery when groceryId is not empty, in order of their products.
+func heldReservations(ctx context.Context, tx *sql.Tx, orderId int64, groceryId string) ([]*StockReservations, error) {
+	query := `
+    SELECT id, order_id, product_id, variant_id, grocery_id, quantity,
+    variant_quantity, status, expires_at, created_at
+    FROM stock_reservations
+    WHERE order_id = $1 AND status = $2 AND ($3::text = '' OR grocery_id = $3)
+    ORDER BY product_id, id
+    FOR UPDATE
+    `
+
+	rows, err := tx.QueryContext(ctx, query, orderId, ReservationReserved, groceryId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*StockReservations
+	for rows.Next() {
+		var each = &StockReservations{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.OrderID,
+			&each.ProductID,
+			&each.VariantID,
+			&each.GroceryID,
+			&each.Quantity,
+			&each.VariantQuantity,
+			&each.Status,
+			&each.ExpiresAt,
+			&each.CreatedAt,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+// resolve gives the stock held by the reservation back and closes it with
+// status.
+func (reservation *StockReservations) resolve(ctx context.Context, tx *sql.Tx, status string) error {
+	if _, err := tx.ExecContext(ctx, `
+    UPDATE products SET reserved_stock = reserved_stock - $1 WHERE id = $2
+    `, reservation.Quantity, reservation.ProductID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+    UPDATE stock_reservations SET status = $1, resolved_at = $2 WHERE id = $3
+    `, status, time.Now().Unix(), reservation.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	reservation.Status = status
+	return nil
+}
+
+// CommitReservations turns the stock the order holds at the grocery into sales
+// in the stock ledger within tx, it returns how many lines were committed. It
+// returns ErrReservationExpired when the reservations expired, even when the
+// sweep did not release them yet.
+func CommitReservations(tx *sql.Tx, orderId int64, groceryId, actor string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reservations, err := heldReservations(ctx, tx, orderId, groceryId)
+	if err != nil {
+		return 0, err
+	}
+
+	// the reservations are locked, the sweep can not release them meanwhile
+	now := time.Now().Unix()
+	for _, reservation := range reservations {
+		if reservation.ExpiresAt <= now {
+			return 0, ErrReservationExpired
+		}
+	}
+
+	for _, reservation := range reservations {
+		if err := reservation.resolve(ctx, tx, ReservationCommitted); err != nil {
+			return 0, err
+		}
+
+		// the reserved base quantity is sold even when the conversion of the
+		// variant changed since the order was placed
+		movement := &StockMovements{
+			ProductID: reservation.ProductID,
+			VariantID: reservation.VariantID,
+			Kind:      StockSale,
+			Quantity:  -reservation.Quantity,
+			Reason:    "penjualan",
+			Actor:     actor,
+			Reference: fmt.Sprintf("order:%d", orderId),
+		}
+		if err := movement.Apply(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(reservations), nil
+}
+
+// ReleaseReservations gives back within tx all the stock the order still
+// holds, it returns how many lines were released.
+func ReleaseReservations(tx *sql.Tx, orderId int64) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reservations, err := heldReservations(ctx, tx, orderId, "")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reservation := range reservations {
+		if err := reservation.resolve(ctx, tx, ReservationReleased); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(reservations), nil
+}
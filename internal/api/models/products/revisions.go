@@ -0,0 +1,204 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+// RevisionChange is the value of a field before and after a revision.
+type RevisionChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ProductRevisions is one change of a product, Changes holds the changed
+// fields by their json name, CreatedAt is in unix seconds.
+type ProductRevisions struct {
+	ID        int64                     `json:"id"`
+	ProductID int64                     `json:"product_id"`
+	Actor     string                    `json:"actor"`
+	Changes   map[string]RevisionChange `json:"changes"`
+	CreatedAt int64                     `json:"created_at"`
+}
+
+// revisionFields are the fields of the product a revision tracks.
+func revisionFields(product *Product) map[string]interface{} {
+	return map[string]interface{}{
+		"product_code":     product.ProductCode,
+		"product_name":     product.ProductName,
+		"picture":          product.Picture,
+		"position":         product.Position,
+		"size_type_id":     product.SizeTypeId,
+		"category_id":      product.CategoryId,
+		"min_retail_price": product.MRP,
+		"buy_price":        product.BuyPrice,
+		"defective":        product.Defective,
+		"active":           product.Active,
+	}
+}
+
+// variantField names a field of a variant in a revision of its product, such
+// as variants.12.min_retail_price.
+func variantField(variantId int64, field string) string {
+	return fmt.Sprintf("variants.%d.%s", variantId, field)
+}
+
+// tierChange records a price tier of a variant, Old is nil when the tier was
+// created and New is nil when it was deleted.
+func tierChange(tier *PriceTiers, created bool) (string, RevisionChange) {
+	value := map[string]interface{}{
+		"group_id":     tier.GroupID,
+		"min_quantity": tier.MinQuantity,
+		"unit_price":   tier.UnitPrice,
+	}
+	field := variantField(tier.VariantID, fmt.Sprintf("tiers.%d", tier.ID))
+	if created {
+		return field, RevisionChange{New: value}
+	}
+	return field, RevisionChange{Old: value}
+}
+
+// variantRemoval records a deleted variant under variants.<id>, New is nil.
+func variantRemoval(variant *ProductVariants) (string, RevisionChange) {
+	value := map[string]interface{}{
+		"size_type_id":     variant.SizeTypeID,
+		"sku":              variant.SKU,
+		"barcode":          variant.Barcode,
+		"buy_price":        variant.BuyPrice,
+		"min_retail_price": variant.MRP,
+		"conversion":       variant.Conversion,
+		"active":           variant.Active,
+	}
+	return fmt.Sprintf("variants.%d", variant.ID), RevisionChange{Old: value}
+}
+
+// diffRevision returns the fields that differ between before and after.
+func diffRevision(before, after *Product) map[string]RevisionChange {
+	changes := map[string]RevisionChange{}
+	old := revisionFields(before)
+	for field, value := range revisionFields(after) {
+		if old[field] != value {
+			changes[field] = RevisionChange{Old: old[field], New: value}
+		}
+	}
+	return changes
+}
+
+// insertRevision records the changes of the product within tx, nothing when
+// no field changed.
+func insertRevision(ctx context.Context, tx *sql.Tx, productId int64, actor string, changes map[string]RevisionChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+    INSERT INTO product_revisions(product_id, actor, changes, created_at)
+    VALUES ($1, $2, $3, $4)
+    `
+
+	// lib/pq sends []byte as bytea
+	if _, err := tx.ExecContext(ctx, query, productId, actor, string(data), time.Now().Unix()); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	return nil
+}
+
+// RevisionFilter narrows the history of a product, zero values do not
+// filter. From and To are unix seconds.
+type RevisionFilter struct {
+	Field string
+	From  int64
+	To    int64
+}
+
+var revisionSorting = models.Sorting{
+	Keys: map[string]string{
+		"created": "created_at",
+	},
+	Default: "created",
+	ID:      "id",
+}
+
+// GetAll returns one page of the revisions of the product matching filter.
+func (revision *ProductRevisions) GetAll(productId int64, filter RevisionFilter, params models.ListParams, db *sql.DB) ([]ProductRevisions, *models.PageInfo, error) {
+	q := models.NewQuery().Where("product_id = ?", productId)
+	if filter.Field != "" {
+		q.Where("changes -> ? IS NOT NULL", filter.Field)
+	}
+	if filter.From > 0 {
+		q.Where("created_at >= ?", filter.From)
+	}
+	if filter.To > 0 {
+		q.Where("created_at < ?", filter.To)
+	}
+
+	page, err := q.Page(params, revisionSorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := "FROM product_revisions"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL("id, product_id, actor, changes, created_at", from)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	result := []ProductRevisions{}
+	for rows.Next() {
+		var each = ProductRevisions{}
+		var changes []byte
+		var sortKey interface{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.ProductID,
+			&each.Actor,
+			&changes,
+			&each.CreatedAt,
+			&sortKey,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		if err := json.Unmarshal(changes, &each.Changes); err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
+}
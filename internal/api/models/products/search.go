@@ -0,0 +1,140 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"payuoge.com/internal/api/models"
+)
+
+// maxSearchLength bounds the text searched, longer input is cut.
+const maxSearchLength = 100
+
+var ErrEmptySearch = errors.New("kata kunci pencarian kosong")
+
+// searchWords splits text into lower case words of letters and digits.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// prefixQuery matches every word, the last ones typed may be unfinished.
+func prefixQuery(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Search returns one page of the products matching text, best match first.
+// Words match in any order and as prefixes, typos and missing spaces are
+// matched by trigram similarity of the name, code and category name.
+// Highlight marks the matched words of the HTML escaped name.
+func (product *Product) Search(text string, filter ProductFilter, params models.ListParams, db *sql.DB) ([]Product, *models.PageInfo, error) {
+	if len(text) > maxSearchLength {
+		text = text[:maxSearchLength]
+	}
+
+	words := searchWords(text)
+	if len(words) == 0 {
+		return nil, nil, ErrEmptySearch
+	}
+
+	q := filter.query()
+	term := q.Arg(strings.Join(words, " "))
+	tsQuery := q.Arg(prefixQuery(words))
+	compact := q.Arg(strings.Join(words, ""))
+
+	tsq := fmt.Sprintf("to_tsquery('simple', %s)", tsQuery)
+	compactName := "replace(lower(p.product_name), ' ', '')"
+
+	q.Where(fmt.Sprintf(`(p.search_vector @@ %s
+    OR p.product_name %% %s
+    OR %s <%% p.product_name
+    OR %s %% %s
+    OR p.product_code %% %s
+    OR c.name %% %s)`,
+		tsq, term, term, compactName, compact, term, term))
+
+	rank := fmt.Sprintf(`ts_rank(p.search_vector, %s) + GREATEST(
+    similarity(p.product_name, %s),
+    word_similarity(%s, p.product_name),
+    similarity(%s, %s),
+    similarity(p.product_code, %s),
+    similarity(c.name, %s) / 2
+    )`, tsq, term, term, compactName, compact, term, term)
+
+	// the name is escaped first so <mark> is the only markup of the highlight
+	escapedName := `replace(replace(replace(replace(replace(p.product_name,
+    '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+	highlight := fmt.Sprintf(
+		"ts_headline('simple', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')", escapedName, tsq)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	total, err := q.Count(ctx, db, productFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args, page := q.Ranked(
+		productColumns+",\n    "+rank+" AS rank,\n    "+highlight,
+		productFrom, "rank DESC, p.id", params)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	result := []Product{}
+	for rows.Next() {
+		var each = Product{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.UserID,
+			&each.ProductCode,
+			&each.ProductName,
+			&each.Picture,
+			&each.Quantity,
+			&each.BaseStock,
+			&each.ReservedStock,
+			&each.Position,
+			&each.SizeTypeName,
+			&each.CategoryName,
+			&each.MRP,
+			&each.BuyPrice,
+			&each.Defective,
+			&each.Active,
+			&each.Created,
+			&each.Updated,
+			&each.Rank,
+			&each.Highlight,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	page.Total = total
+	return result, page, nil
+}
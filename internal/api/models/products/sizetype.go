@@ -3,36 +3,77 @@ package products
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"time"
 
 	"payuoge.com/internal/api/models"
 )
 
+var (
+	ErrInvalidBaseUnit   = errors.New("base unit harus size type dasar milik grosir yang sama")
+	ErrIncompatibleUnits = errors.New("size type tidak memiliki base unit yang sama")
+	ErrBaseUnitInUse     = errors.New("size type masih menjadi base unit size type lain")
+	ErrSizeTypeInUse     = errors.New("size type masih digunakan produk, varian atau size type lain")
+)
+
+// SizeType is a unit products are sold in. A base unit, such as pcs, has no
+// BaseUnitID and a Factor of 1, other units hold Factor of their base unit,
+// such as 1 dus = 24 pcs.
 type SizeType struct {
-	ID       int       `json:"id"`
-	UserID   string    `json:"user_id,omitempty"`
-	Name     string    `json:"name"`
-	Products []Product `json:"product,omitempty"`
+	ID           int       `json:"id"`
+	UserID       string    `json:"user_id,omitempty"`
+	Name         string    `json:"name"`
+	BaseUnitID   *int      `json:"base_unit_id,omitempty"`
+	BaseUnitName string    `json:"base_unit_name,omitempty"`
+	Factor       int32     `json:"factor"`
+	Products     []Product `json:"product,omitempty"`
+}
+
+// normalize makes a unit without a base unit a base unit.
+func (size *SizeType) normalize() {
+	if size.BaseUnitID == nil || *size.BaseUnitID == 0 {
+		size.BaseUnitID = nil
+		size.Factor = 1
+	}
 }
 
+// validBase only matches a base unit of the same grocery.
+const validBase = `
+    ($3::integer IS NULL OR EXISTS (
+    SELECT 1 FROM size_type b
+    WHERE b.id = $3 AND b.user_id = $1 AND b.base_unit_id IS NULL AND b.deleted_at IS NULL
+    ))
+    `
+
 func (size *SizeType) Insert(db *sql.DB, userId string) error {
+	size.normalize()
+
 	query := `
     INSERT INTO size_type(
     user_id,
-    name
+    name,
+    base_unit_id,
+    factor
     )
-    VALUES ($1, $2)
+    SELECT $1, $2, $3, $4
+    WHERE` + validBase + `
+    RETURNING id
     `
 	args := []interface{}{
 		userId,
 		size.Name,
+		size.BaseUnitID,
+		size.Factor,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := db.ExecContext(ctx, query, args...)
+	err := db.QueryRowContext(ctx, query, args...).Scan(&size.ID)
+	if err == sql.ErrNoRows {
+		return ErrInvalidBaseUnit
+	}
 	if err != nil {
 		log.Println(err.Error())
 		return err
@@ -41,43 +82,81 @@ func (size *SizeType) Insert(db *sql.DB, userId string) error {
 	return nil
 }
 
-func (size *SizeType) GetAll(db *sql.DB) ([]SizeType, error) {
-	query := `
-    SELECT id, name FROM size_type
-    `
+var sizeSorting = models.Sorting{
+	Keys: map[string]string{
+		"name": "name",
+		"id":   "id",
+	},
+	Default: "name",
+	ID:      "id",
+}
+
+// GetAll returns one page of the size types, of every grocery when
+// groceryId is empty.
+func (size *SizeType) GetAll(groceryId string, params models.ListParams, db *sql.DB) ([]SizeType, *models.PageInfo, error) {
+	q := models.NewQuery().Where("deleted_at IS NULL")
+	if groceryId != "" {
+		q.Where("user_id = ?", groceryId)
+	}
+
+	page, err := q.Page(params, sizeSorting)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query)
+	from := "FROM size_type"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL("id, name, base_unit_id, factor", from)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Println(err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var result []SizeType
+	result := []SizeType{}
 	for rows.Next() {
 		var each = SizeType{}
+		var sortKey interface{}
 		var err = rows.Scan(
 			&each.ID,
 			&each.Name,
+			&each.BaseUnitID,
+			&each.Factor,
+			&sortKey,
 		)
 		if err != nil {
 			log.Println(err.Error())
-			return nil, err
+			return nil, nil, err
 		}
 
+		if !page.Keep(sortKey, int64(each.ID)) {
+			break
+		}
 		result = append(result, each)
 	}
 
-	return result, nil
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
 }
 
 func (size *SizeType) Get(id int, db *sql.DB) (*SizeType, error) {
 	query := `
-    SELECT id, name FROM size_type
-    WHERE id = $1
+    SELECT s.id, s.name, s.base_unit_id, COALESCE(b.name, ''), s.factor
+    FROM size_type s
+    LEFT JOIN size_type b ON s.base_unit_id = b.id
+    WHERE s.id = $1 AND s.deleted_at IS NULL
     `
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -89,6 +168,9 @@ func (size *SizeType) Get(id int, db *sql.DB) (*SizeType, error) {
 	if err := db.QueryRowContext(ctx, query, args...).Scan(
 		&size.ID,
 		&size.Name,
+		&size.BaseUnitID,
+		&size.BaseUnitName,
+		&size.Factor,
 	); err != nil {
 		log.Println(err.Error())
 		return nil, err
@@ -97,46 +179,118 @@ func (size *SizeType) Get(id int, db *sql.DB) (*SizeType, error) {
 	return size, nil
 }
 
+// unitChanged holds when $3 and $4 change the base unit or the factor of the
+// size type.
+const unitChanged = `(base_unit_id IS DISTINCT FROM $3::integer OR factor <> $4)`
+
+// unitUsed holds when products or variants not in the trash count their
+// stock in the size type $2.
+const unitUsed = `(EXISTS (SELECT 1 FROM products p WHERE p.size_type_id = $2 AND p.deleted_at IS NULL)
+    OR EXISTS (
+    SELECT 1 FROM product_variants v INNER JOIN products p ON v.product_id = p.id
+    WHERE v.size_type_id = $2 AND p.deleted_at IS NULL
+    ))`
+
+// Update renames the size type and sets its base unit, a base unit of other
+// size types stays a base unit. The base unit and factor of a size type of
+// products or variants stay as they are, their stock is counted in them.
 func (size *SizeType) Update(id int, userId string, db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		log.Fatal(err)
-	}
+	size.normalize()
 
 	query := `
     UPDATE size_type
-    SET name = $1
-    WHERE id = $2 AND user_id = $3
-    RETURNING id
+    SET name = $5,
+    base_unit_id = $3,
+    factor = $4
+    WHERE id = $2 AND user_id = $1 AND deleted_at IS NULL AND` + validBase + `
+    AND ($3::integer IS NULL OR $3 <> $2 AND NOT EXISTS (
+    SELECT 1 FROM size_type d WHERE d.base_unit_id = $2 AND d.deleted_at IS NULL
+    ))
+    AND NOT (` + unitChanged + ` AND ` + unitUsed + `)
     `
 
 	args := []interface{}{
-		size.Name,
-		id,
 		userId,
+		id,
+		size.BaseUnitID,
+		size.Factor,
+		size.Name,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
-		tx.Rollback()
 		log.Println(err.Error())
 		return err
 	}
-	err = tx.Commit()
+
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return size.updateError(id, userId, db)
 	}
 
 	return nil
 }
 
+// updateError tells why Update matched no size type.
+func (size *SizeType) updateError(id int, userId string, db *sql.DB) error {
+	query := `
+    SELECT
+    $3::integer IS NOT NULL AND EXISTS (SELECT 1 FROM size_type d WHERE d.base_unit_id = $2 AND d.deleted_at IS NULL),
+    ` + unitChanged + ` AND ` + unitUsed + `
+    FROM size_type
+    WHERE id = $2 AND user_id = $1 AND deleted_at IS NULL
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := []interface{}{
+		userId,
+		id,
+		size.BaseUnitID,
+		size.Factor,
+	}
+
+	var isBase, inUse bool
+	err := db.QueryRowContext(ctx, query, args...).Scan(&isBase, &inUse)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if isBase {
+		return ErrBaseUnitInUse
+	}
+	if inUse {
+		return ErrSizeTypeInUse
+	}
+	return ErrInvalidBaseUnit
+}
+
+// Delete moves the size type to the trash, a size type of products,
+// variants or size types not in the trash can not be deleted.
 func (size *SizeType) Delete(id int, userId string, db *sql.DB) error {
 	query := `
-	DELETE FROM size_type
-	WHERE id = $1 AND user_id = $2
+	UPDATE size_type s
+	SET deleted_at = $3
+	WHERE s.id = $1 AND s.user_id = $2 AND s.deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM products p WHERE p.size_type_id = $1 AND p.deleted_at IS NULL)
+	AND NOT EXISTS (
+	SELECT 1 FROM product_variants v INNER JOIN products p ON v.product_id = p.id
+	WHERE v.size_type_id = $1 AND p.deleted_at IS NULL
+	)
+	AND NOT EXISTS (SELECT 1 FROM size_type d WHERE d.base_unit_id = $1 AND d.deleted_at IS NULL)
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -145,6 +299,7 @@ func (size *SizeType) Delete(id int, userId string, db *sql.DB) error {
 	args := []interface{}{
 		id,
 		userId,
+		time.Now().Unix(),
 	}
 
 	result, err := db.ExecContext(ctx, query, args...)
@@ -160,8 +315,59 @@ func (size *SizeType) Delete(id int, userId string, db *sql.DB) error {
 	}
 
 	if rowsAffected == 0 {
-		return models.ErrRecordNotFound
+		var exists bool
+		err := db.QueryRowContext(ctx, `
+	SELECT EXISTS(SELECT 1 FROM size_type WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)
+	`, id, userId).Scan(&exists)
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+		if !exists {
+			return models.ErrRecordNotFound
+		}
+		return ErrSizeTypeInUse
 	}
 
 	return nil
 }
+
+// Conversion is Quantity of From expressed in To, through their base unit.
+type Conversion struct {
+	From         SizeType `json:"from"`
+	To           SizeType `json:"to"`
+	Quantity     int64    `json:"quantity"`
+	BaseQuantity int64    `json:"base_quantity"`
+	Result       float64  `json:"result"`
+	Exact        bool     `json:"exact"`
+}
+
+// Convert expresses quantity of the size type from in the size type to, both
+// must share a base unit.
+func Convert(from, to int, quantity int64, db *sql.DB) (*Conversion, error) {
+	var conversion = Conversion{Quantity: quantity}
+
+	if _, err := conversion.From.Get(from, db); err != nil {
+		return nil, models.ErrRecordNotFound
+	}
+	if _, err := conversion.To.Get(to, db); err != nil {
+		return nil, models.ErrRecordNotFound
+	}
+
+	if conversion.From.base() != conversion.To.base() {
+		return nil, ErrIncompatibleUnits
+	}
+
+	conversion.BaseQuantity = quantity * int64(conversion.From.Factor)
+	conversion.Result = float64(conversion.BaseQuantity) / float64(conversion.To.Factor)
+	conversion.Exact = conversion.BaseQuantity%int64(conversion.To.Factor) == 0
+	return &conversion, nil
+}
+
+// base is the id of the base unit of the size type.
+func (size *SizeType) base() int {
+	if size.BaseUnitID != nil {
+		return *size.BaseUnitID
+	}
+	return size.ID
+}
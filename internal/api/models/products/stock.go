@@ -0,0 +1,282 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+// kinds of stock movements
+const (
+	StockReceipt    = "receipt"
+	StockSale       = "sale"
+	StockReturn     = "return"
+	StockAdjustment = "adjustment"
+	StockDefective  = "defective"
+	StockTransfer   = "transfer"
+)
+
+// stockSigns is the sign a movement of each kind must have, 0 allows both.
+var stockSigns = map[string]int{
+	StockReceipt:    1,
+	StockSale:       -1,
+	StockReturn:     1,
+	StockAdjustment: 0,
+	StockDefective:  -1,
+	StockTransfer:   0,
+}
+
+var (
+	ErrInsufficientStock = errors.New("stock tidak mencukupi")
+	ErrStockKind         = errors.New("kind harus receipt, sale, return, adjustment, defective atau transfer")
+	ErrStockSign         = errors.New("quantity tidak sesuai dengan kind")
+)
+
+// StockMovements is one entry of the stock ledger of a product. Quantity is
+// signed and in base units, VariantQuantity is the same quantity in the unit
+// of the variant it was recorded in, Balance is the base stock after it.
+type StockMovements struct {
+	ID              int64  `json:"id"`
+	ProductID       int64  `json:"product_id"`
+	VariantID       *int64 `json:"variant_id,omitempty"`
+	Kind            string `json:"kind"`
+	Quantity        int64  `json:"quantity"`
+	VariantQuantity *int64 `json:"variant_quantity,omitempty"`
+	Balance         int64  `json:"balance"`
+	Reason          string `json:"reason"`
+	Actor           string `json:"actor"`
+	Reference       string `json:"reference"`
+	CreatedAt       int64  `json:"created_at"`
+}
+
+// Check validates the kind and the sign of the movement.
+func (movement *StockMovements) Check() error {
+	sign, ok := stockSigns[movement.Kind]
+	if !ok {
+		return ErrStockKind
+	}
+
+	quantity := movement.Quantity
+	if movement.VariantQuantity != nil {
+		if movement.VariantID == nil {
+			return ErrVariantNotFound
+		}
+		quantity = *movement.VariantQuantity
+	}
+	if quantity == 0 || sign > 0 && quantity < 0 || sign < 0 && quantity > 0 {
+		return ErrStockSign
+	}
+	return nil
+}
+
+// Apply records the movement within tx. The product row is locked until tx
+// ends, so concurrent movements of a product are applied one after another
+// and the base stock never goes below the stock reserved by orders. A
+// movement in a variant is converted to base units first.
+func (movement *StockMovements) Apply(tx *sql.Tx) error {
+	if err := movement.Check(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if movement.VariantID != nil {
+		var conversion int64
+		err := tx.QueryRowContext(ctx, `
+    SELECT conversion FROM product_variants
+    WHERE id = $1 AND product_id = $2
+    `, *movement.VariantID, movement.ProductID).Scan(&conversion)
+		if err == sql.ErrNoRows {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+
+		if movement.VariantQuantity != nil {
+			movement.Quantity = *movement.VariantQuantity * conversion
+		}
+	}
+
+	var stock, reserved int64
+	err := tx.QueryRowContext(ctx, `
+    SELECT base_stock, reserved_stock FROM products
+    WHERE id = $1
+    FOR UPDATE
+    `, movement.ProductID).Scan(&stock, &reserved)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	movement.Balance = stock + movement.Quantity
+	if movement.Balance < reserved {
+		return ErrInsufficientStock
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+    UPDATE products SET base_stock = $1 WHERE id = $2
+    `, movement.Balance, movement.ProductID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	query := `
+    INSERT INTO stock_movements(
+    product_id,
+    variant_id,
+    kind,
+    quantity,
+    variant_quantity,
+    balance,
+    reason,
+    actor,
+    reference,
+    created_at
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING id
+    `
+
+	movement.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		movement.ProductID,
+		movement.VariantID,
+		movement.Kind,
+		movement.Quantity,
+		movement.VariantQuantity,
+		movement.Balance,
+		movement.Reason,
+		movement.Actor,
+		movement.Reference,
+		movement.CreatedAt,
+	}
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&movement.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Record applies the movements in one transaction, in order of their
+// products so concurrent callers lock rows in the same order.
+func Record(movements []*StockMovements, db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := ApplyAll(tx, movements); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ApplyAll applies the movements within tx, locking their products in order
+// of id to keep concurrent transactions from deadlocking.
+func ApplyAll(tx *sql.Tx, movements []*StockMovements) error {
+	sorted := make([]*StockMovements, len(movements))
+	copy(sorted, movements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ProductID < sorted[j].ProductID
+	})
+
+	for _, movement := range sorted {
+		if err := movement.Apply(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var stockSorting = models.Sorting{
+	Keys: map[string]string{
+		"created": "created_at",
+	},
+	Default: "created",
+	ID:      "id",
+}
+
+// GetAll returns one page of the ledger of the product, of one kind when kind
+// is not empty.
+func (movement *StockMovements) GetAll(productId int64, kind string, params models.ListParams, db *sql.DB) ([]StockMovements, *models.PageInfo, error) {
+	q := models.NewQuery().Where("product_id = ?", productId)
+	if kind != "" {
+		q.Where("kind = ?", kind)
+	}
+
+	page, err := q.Page(params, stockSorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := "FROM stock_movements"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL(`
+    id, product_id, variant_id, kind, quantity, variant_quantity, balance,
+    reason, actor, reference, created_at`, from)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	result := []StockMovements{}
+	for rows.Next() {
+		var each = StockMovements{}
+		var sortKey interface{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.ProductID,
+			&each.VariantID,
+			&each.Kind,
+			&each.Quantity,
+			&each.VariantQuantity,
+			&each.Balance,
+			&each.Reason,
+			&each.Actor,
+			&each.Reference,
+			&each.CreatedAt,
+			&sortKey,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
+}
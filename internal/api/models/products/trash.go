@@ -0,0 +1,354 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+	"payuoge.com/internal/api/models"
+)
+
+// kinds of catalog rows kept in the trash
+const (
+	TrashProduct  = "product"
+	TrashCategory = "category"
+	TrashSizeType = "size_type"
+)
+
+var (
+	ErrTrashKind       = errors.New("type harus product, category atau size_type")
+	ErrRestoreRequires = errors.New("pulihkan category, parent atau size type yang dibutuhkan terlebih dahulu")
+)
+
+// TrashItems is a soft deleted product, category or size type, DeletedAt is
+// in unix seconds.
+type TrashItems struct {
+	Type      string `json:"type"`
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Global    bool   `json:"global,omitempty"`
+	DeletedAt int64  `json:"deleted_at"`
+}
+
+// trashTables are the table and the name column of each kind.
+var trashTables = map[string][2]string{
+	TrashProduct:  {"products", "product_name"},
+	TrashCategory: {"category_products", "name"},
+	TrashSizeType: {"size_type", "name"},
+}
+
+var trashSorting = models.Sorting{
+	Keys: map[string]string{
+		"deleted": "deleted_at",
+		"name":    "name",
+	},
+	Default: "deleted",
+	ID:      "id",
+}
+
+// Trash returns one page of the rows of one kind the grocery deleted, the
+// global categories too when taxonomy is true.
+func Trash(kind, groceryId string, taxonomy bool, params models.ListParams, db *sql.DB) ([]TrashItems, *models.PageInfo, error) {
+	table, ok := trashTables[kind]
+	if !ok {
+		return nil, nil, ErrTrashKind
+	}
+
+	q := models.NewQuery().Where("deleted_at IS NOT NULL")
+	global := "false"
+	if kind == TrashCategory {
+		global = "global"
+		q.Where("(CASE WHEN global THEN ? ELSE user_id = ? END)", taxonomy, groceryId)
+	} else {
+		q.Where("user_id = ?", groceryId)
+	}
+
+	page, err := q.Page(params, trashSorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := "FROM (SELECT id, user_id, " + table[1] + " AS name, " + global + " AS global, deleted_at FROM " + table[0] + ") t"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL("id, name, global, deleted_at", from)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	result := []TrashItems{}
+	for rows.Next() {
+		var each = TrashItems{Type: kind}
+		var sortKey interface{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.Name,
+			&each.Global,
+			&each.DeletedAt,
+			&sortKey,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, nil, err
+		}
+
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
+}
+
+// Restore takes a row of one kind the grocery deleted out of the trash, a
+// global category when taxonomy is true. A row only comes back once the
+// rows it depends on are out of the trash.
+func Restore(kind string, id int64, groceryId string, taxonomy bool, db *sql.DB) error {
+	var query string
+	var args []interface{}
+	switch kind {
+	case TrashProduct:
+		query = `
+    UPDATE products p
+    SET deleted_at = NULL, updated = $3
+    WHERE p.id = $1 AND p.user_id = $2 AND p.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM category_products c WHERE c.id = p.category_id AND c.deleted_at IS NOT NULL)
+    AND NOT EXISTS (SELECT 1 FROM size_type s WHERE s.id = p.size_type_id AND s.deleted_at IS NOT NULL)
+    `
+		args = []interface{}{id, groceryId, time.Now().UnixMilli()}
+	case TrashCategory:
+		query = `
+    UPDATE category_products c
+    SET deleted_at = NULL
+    WHERE c.id = $1 AND (CASE WHEN c.global THEN $3 ELSE c.user_id = $2 END)
+    AND c.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM category_products p WHERE p.id = c.parent_id AND p.deleted_at IS NOT NULL)
+    `
+		args = []interface{}{id, groceryId, taxonomy}
+	case TrashSizeType:
+		query = `
+    UPDATE size_type s
+    SET deleted_at = NULL
+    WHERE s.id = $1 AND s.user_id = $2 AND s.deleted_at IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM size_type b WHERE b.id = s.base_unit_id AND b.deleted_at IS NOT NULL)
+    `
+		args = []interface{}{id, groceryId}
+	default:
+		return ErrTrashKind
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		// a product with the same code may have been added meanwhile
+		return uniqueError(err, ErrProductCodeExists)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return restoreError(ctx, kind, id, groceryId, taxonomy, db)
+	}
+	return nil
+}
+
+// restoreError tells why Restore matched no row.
+func restoreError(ctx context.Context, kind string, id int64, groceryId string, taxonomy bool, db *sql.DB) error {
+	query := `
+    SELECT EXISTS(SELECT 1 FROM ` + trashTables[kind][0] + `
+    WHERE id = $1 AND deleted_at IS NOT NULL AND user_id = $2)
+    `
+	args := []interface{}{id, groceryId}
+	if kind == TrashCategory {
+		query = `
+    SELECT EXISTS(SELECT 1 FROM category_products
+    WHERE id = $1 AND deleted_at IS NOT NULL AND (CASE WHEN global THEN $3 ELSE user_id = $2 END))
+    `
+		args = append(args, taxonomy)
+	}
+
+	var trashed bool
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&trashed); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if !trashed {
+		return models.ErrRecordNotFound
+	}
+	return ErrRestoreRequires
+}
+
+// PurgeResult counts the rows a purge removed for good, Keys are the objects
+// of the images of the removed products.
+type PurgeResult struct {
+	Products   int64
+	Categories int64
+	SizeTypes  int64
+	Keys       []string
+}
+
+// Purge removes up to limit rows of each kind deleted before the unix second
+// before. Products ordered or still reserved by an order stay in the trash
+// so the order keeps its names, a category or size type stays as long as
+// anything refers to it.
+func Purge(before int64, limit int, db *sql.DB) (*PurgeResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+    SELECT p.id FROM products p
+    WHERE p.deleted_at < $1
+    AND NOT EXISTS (SELECT 1 FROM order_items i WHERE i.product_id = p.id)
+    AND NOT EXISTS (SELECT 1 FROM stock_reservations r WHERE r.product_id = p.id)
+    ORDER BY p.deleted_at, p.id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+    `, before, limit)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			log.Println(err.Error())
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	result := &PurgeResult{}
+	if len(ids) > 0 {
+		if result.Keys, err = purgeKeys(ctx, tx, ids); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE product_id = ANY($1)`, pq.Array(ids)); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+		if result.Products, err = purgeExec(ctx, tx, `DELETE FROM products WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Categories, err = purgeExec(ctx, tx, `
+    DELETE FROM category_products
+    WHERE id IN (
+    SELECT c.id FROM category_products c
+    WHERE c.deleted_at < $1
+    AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)
+    AND NOT EXISTS (SELECT 1 FROM category_products d WHERE d.parent_id = c.id)
+    ORDER BY c.deleted_at, c.id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+    )
+    `, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result.SizeTypes, err = purgeExec(ctx, tx, `
+    DELETE FROM size_type
+    WHERE id IN (
+    SELECT s.id FROM size_type s
+    WHERE s.deleted_at < $1
+    AND NOT EXISTS (SELECT 1 FROM products p WHERE p.size_type_id = s.id)
+    AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.size_type_id = s.id)
+    AND NOT EXISTS (SELECT 1 FROM carts c WHERE c.size_type_id = s.id)
+    AND NOT EXISTS (SELECT 1 FROM size_type d WHERE d.base_unit_id = s.id)
+    ORDER BY s.deleted_at, s.id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+    )
+    `, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	return result, nil
+}
+
+func purgeKeys(ctx context.Context, tx *sql.Tx, ids []int64) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+    SELECT object_key, thumbnail_key FROM product_images WHERE product_id = ANY($1)
+    `, pq.Array(ids))
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key, thumbnail string
+		if err := rows.Scan(&key, &thumbnail); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+		keys = append(keys, key, thumbnail)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	return keys, nil
+}
+
+func purgeExec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+	return purged, nil
+}
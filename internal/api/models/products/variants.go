@@ -0,0 +1,363 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+var (
+	ErrVariantExists   = errors.New("produk sudah memiliki varian dengan size type tersebut")
+	ErrVariantNotFound = errors.New("varian produk tidak ditemukan atau tidak aktif")
+	ErrDefaultVariant  = errors.New("varian dengan size type produk hanya dapat diubah melalui produknya")
+)
+
+// ProductVariants is a unit a product is sold in, such as pcs, pack or dus,
+// with its own price. Conversion is how many base units of the product one
+// unit of the variant holds, Stock is how many units the base stock of the
+// product not reserved by orders fills.
+type ProductVariants struct {
+	ID           int64  `json:"id"`
+	ProductID    int64  `json:"product_id"`
+	SizeTypeID   int    `json:"size_type_id"`
+	SizeTypeName string `json:"size_type_name,omitempty"`
+	SKU          string `json:"sku"`
+	Barcode      string `json:"barcode"`
+	BuyPrice     int32  `json:"buy_price"`
+	MRP          int32  `json:"min_retail_price"`
+	Stock        int64  `json:"stock"`
+	Conversion   int32  `json:"conversion"`
+	Active       bool   `json:"active"`
+	Created      int64  `json:"created"`
+	Updated      int64  `json:"updated"`
+}
+
+// defaultConversion takes the factor of the size type when the conversion in
+// $7 is 0.
+const defaultConversion = `COALESCE(NULLIF($7::integer, 0), (SELECT factor FROM size_type WHERE id = $2))`
+
+// sameBase holds when the size type in $2 shares the base unit of the size
+// type of the product in $1, so the conversion counts the same base units.
+const sameBase = ` EXISTS (
+    SELECT 1 FROM products p
+    INNER JOIN size_type ps ON p.size_type_id = ps.id
+    INNER JOIN size_type vs ON vs.id = $2
+    WHERE p.id = $1 AND COALESCE(vs.base_unit_id, vs.id) = COALESCE(ps.base_unit_id, ps.id)
+    )`
+
+// Insert adds the variant, its size type must share the base unit of the
+// size type of the product.
+func (variant *ProductVariants) Insert(db *sql.DB) error {
+	query := `
+    INSERT INTO product_variants(
+    product_id,
+    size_type_id,
+    sku,
+    barcode,
+    buy_price,
+    mrp,
+    conversion,
+    active,
+    created,
+    updated
+    )
+    SELECT $1, $2, $3, $4, $5, $6, ` + defaultConversion + `, $8, $9, $9
+    WHERE` + sameBase + `
+    RETURNING id, conversion
+    `
+
+	variant.Created = time.Now().UnixMilli()
+	variant.Updated = variant.Created
+
+	args := []interface{}{
+		variant.ProductID,
+		variant.SizeTypeID,
+		variant.SKU,
+		variant.Barcode,
+		variant.BuyPrice,
+		variant.MRP,
+		variant.Conversion,
+		variant.Active,
+		variant.Created,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, query, args...).Scan(&variant.ID, &variant.Conversion)
+	if err == sql.ErrNoRows {
+		return ErrIncompatibleUnits
+	}
+	if err != nil {
+		return uniqueError(err, ErrVariantExists)
+	}
+
+	return nil
+}
+
+const variantColumns = `
+    v.id, v.product_id, v.size_type_id, s.name, v.sku, v.barcode, v.buy_price,
+    v.mrp, (p.base_stock - p.reserved_stock) / v.conversion, v.conversion, v.active, v.created, v.updated
+    `
+
+const variantFrom = `FROM product_variants v
+    INNER JOIN products p ON v.product_id = p.id
+    INNER JOIN size_type s ON v.size_type_id = s.id`
+
+func scanVariant(row interface{ Scan(...interface{}) error }, variant *ProductVariants) error {
+	return row.Scan(
+		&variant.ID,
+		&variant.ProductID,
+		&variant.SizeTypeID,
+		&variant.SizeTypeName,
+		&variant.SKU,
+		&variant.Barcode,
+		&variant.BuyPrice,
+		&variant.MRP,
+		&variant.Stock,
+		&variant.Conversion,
+		&variant.Active,
+		&variant.Created,
+		&variant.Updated,
+	)
+}
+
+// GetAll returns the variants of the product, smallest unit first.
+func (variant *ProductVariants) GetAll(productId int64, db *sql.DB) ([]ProductVariants, error) {
+	query := `
+    SELECT` + variantColumns + variantFrom + `
+    WHERE v.product_id = $1
+    ORDER BY v.conversion, v.id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, productId)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []ProductVariants{}
+	for rows.Next() {
+		var each = ProductVariants{}
+		if err := scanVariant(rows, &each); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+func (variant *ProductVariants) Get(id, productId int64, db *sql.DB) (*ProductVariants, error) {
+	query := `
+    SELECT` + variantColumns + variantFrom + `
+    WHERE v.id = $1 AND v.product_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := scanVariant(db.QueryRowContext(ctx, query, id, productId), variant)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// Update changes the variant, its size type must share the base unit of the
+// size type of the product. The variant of the size type of the product is
+// the default variant, it keeps its size type and its prices are the prices
+// of the product. Changed prices are recorded as a revision of the product
+// by actor.
+func (variant *ProductVariants) Update(id, productId int64, actor string, db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var before ProductVariants
+	var product Product
+	err = tx.QueryRowContext(ctx, `
+    SELECT v.size_type_id, v.buy_price, v.mrp, p.size_type_id, p.buy_price, p.mrp
+    FROM product_variants v
+    INNER JOIN products p ON v.product_id = p.id
+    WHERE v.id = $1 AND v.product_id = $2
+    FOR UPDATE
+    `, id, productId).Scan(&before.SizeTypeID, &before.BuyPrice, &before.MRP, &product.SizeTypeId, &product.BuyPrice, &product.MRP)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	isDefault := before.SizeTypeID == product.SizeTypeId
+	if isDefault && variant.SizeTypeID != product.SizeTypeId {
+		return ErrDefaultVariant
+	}
+
+	query := `
+    UPDATE product_variants
+    SET size_type_id = $2,
+    sku = $3,
+    barcode = $4,
+    buy_price = $5,
+    mrp = $6,
+    conversion = ` + defaultConversion + `,
+    active = $8,
+    updated = $9
+    WHERE id = $10 AND product_id = $1 AND` + sameBase + `
+    `
+
+	variant.Updated = time.Now().UnixMilli()
+
+	args := []interface{}{
+		productId,
+		variant.SizeTypeID,
+		variant.SKU,
+		variant.Barcode,
+		variant.BuyPrice,
+		variant.MRP,
+		variant.Conversion,
+		variant.Active,
+		variant.Updated,
+		id,
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return uniqueError(err, ErrVariantExists)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrIncompatibleUnits
+	}
+
+	changes := map[string]RevisionChange{}
+	if before.MRP != variant.MRP {
+		changes[variantField(id, "min_retail_price")] = RevisionChange{Old: before.MRP, New: variant.MRP}
+	}
+	if before.BuyPrice != variant.BuyPrice {
+		changes[variantField(id, "buy_price")] = RevisionChange{Old: before.BuyPrice, New: variant.BuyPrice}
+	}
+
+	// listings read the prices of the product and checkout the prices of
+	// the variant, both have to match
+	if isDefault && (product.MRP != variant.MRP || product.BuyPrice != variant.BuyPrice) {
+		_, err = tx.ExecContext(ctx, `
+    UPDATE products SET mrp = $2, buy_price = $3, updated = $4
+    WHERE id = $1
+    `, productId, variant.MRP, variant.BuyPrice, variant.Updated)
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+
+		if product.MRP != variant.MRP {
+			changes["min_retail_price"] = RevisionChange{Old: product.MRP, New: variant.MRP}
+		}
+		if product.BuyPrice != variant.BuyPrice {
+			changes["buy_price"] = RevisionChange{Old: product.BuyPrice, New: variant.BuyPrice}
+		}
+	}
+
+	if err := insertRevision(ctx, tx, productId, actor, changes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the variant, carts holding it are removed with it. The
+// default variant is removed only with its product. The removal is recorded
+// as a revision of the product by actor.
+func (variant *ProductVariants) Delete(id, productId int64, actor string, db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	var productSizeType int
+	err = tx.QueryRowContext(ctx, `
+    SELECT v.id, v.size_type_id, v.sku, v.barcode, v.buy_price, v.mrp, v.conversion, v.active, p.size_type_id
+    FROM product_variants v
+    INNER JOIN products p ON v.product_id = p.id
+    WHERE v.id = $1 AND v.product_id = $2
+    FOR UPDATE
+    `, id, productId).Scan(
+		&variant.ID,
+		&variant.SizeTypeID,
+		&variant.SKU,
+		&variant.Barcode,
+		&variant.BuyPrice,
+		&variant.MRP,
+		&variant.Conversion,
+		&variant.Active,
+		&productSizeType,
+	)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if variant.SizeTypeID == productSizeType {
+		return ErrDefaultVariant
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE id = $1`, id); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	field, change := variantRemoval(variant)
+	if err := insertRevision(ctx, tx, productId, actor, map[string]RevisionChange{field: change}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
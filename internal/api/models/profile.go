@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Profiles holds what the API knows about a user besides Cognito, UserID is
+// the cognito username used as user_id, customer_id and groceries_id.
+type Profiles struct {
+	ID             int64  `json:"id"`
+	UserID         string `json:"user_id"`
+	Email          string `json:"email"`
+	DisplayName    string `json:"display_name"`
+	Phone          string `json:"phone"`
+	BusinessName   string `json:"business_name"`
+	StoreName      string `json:"store_name"`
+	Avatar         string `json:"avatar"`
+	DefaultAddress string `json:"default_address"`
+	CreatedAt      int64  `json:"created_at"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+// Ensure creates the profile of userId when it does not exist yet and fills
+// in the email when it was unknown.
+func (profile *Profiles) Ensure(userId, email string, db *sql.DB) error {
+	query := `
+    INSERT INTO profiles(
+    user_id,
+    email,
+    created_at,
+    updated_at
+    ) VALUES (
+    $1, $2, $3, $3
+    )
+    ON CONFLICT (user_id) DO UPDATE
+    SET email = EXCLUDED.email
+    WHERE profiles.email = '' AND EXCLUDED.email <> ''
+    `
+
+	args := []interface{}{
+		userId,
+		email,
+		time.Now().Unix(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (profile *Profiles) Get(userId string, db *sql.DB) (*Profiles, error) {
+	query := `
+    SELECT id, user_id, email, display_name, phone, business_name,
+    store_name, avatar, default_address, created_at, updated_at
+    FROM profiles
+    WHERE user_id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	row := db.QueryRowContext(ctx, query, userId)
+	err := row.Scan(
+		&profile.ID,
+		&profile.UserID,
+		&profile.Email,
+		&profile.DisplayName,
+		&profile.Phone,
+		&profile.BusinessName,
+		&profile.StoreName,
+		&profile.Avatar,
+		&profile.DefaultAddress,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (profile *Profiles) Update(userId string, db *sql.DB) error {
+	query := `
+    UPDATE profiles
+    SET display_name = $1,
+    phone = $2,
+    business_name = $3,
+    store_name = $4,
+    avatar = $5,
+    default_address = $6,
+    updated_at = $7
+    WHERE user_id = $8
+    `
+
+	profile.UpdatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		profile.DisplayName,
+		profile.Phone,
+		profile.BusinessName,
+		profile.StoreName,
+		profile.Avatar,
+		profile.DefaultAddress,
+		profile.UpdatedAt,
+		userId,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
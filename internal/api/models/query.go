@@ -0,0 +1,280 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort key")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ListParams is what a client asks of a listing. Cursor wins over Offset when
+// both are sent.
+type ListParams struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   string
+	Desc   bool
+}
+
+// Sorting lists the sort keys a listing accepts and the column expression each
+// one orders by. ID is the unique column breaking ties, which keeps cursors
+// stable. Columns must not be null, wrap nullable ones in COALESCE.
+type Sorting struct {
+	Keys    map[string]string
+	Default string
+	ID      string
+}
+
+// PageInfo is returned next to the items of a listing.
+type PageInfo struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Query collects the conditions of a listing, "?" placeholders become
+// numbered parameters in the order the conditions are added.
+type Query struct {
+	where []string
+	args  []interface{}
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+func (q *Query) Where(condition string, args ...interface{}) *Query {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(q.args)), 1)
+	}
+	q.where = append(q.where, condition)
+	return q
+}
+
+// Arg adds a parameter used outside of the conditions, such as in a ranking
+// expression, and returns its placeholder.
+func (q *Query) Arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *Query) clause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return "\n    WHERE " + strings.Join(q.where, " AND ")
+}
+
+// Count returns the number of rows matching the conditions, from is the FROM
+// and JOIN part of the listing.
+func (q *Query) Count(ctx context.Context, db *sql.DB, from string) (int64, error) {
+	var total int64
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) "+from+q.clause(), q.args...).Scan(&total); err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+	return total, nil
+}
+
+func (params ListParams) bounded() ListParams {
+	if params.Limit <= 0 {
+		params.Limit = DefaultLimit
+	}
+	if params.Limit > MaxLimit {
+		params.Limit = MaxLimit
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+	return params
+}
+
+// Ranked returns the query of one page in the order of orderBy, a relevance
+// ranking computed by the query. A ranking can not be resumed from a row, so
+// it is paged with limit and offset only.
+func (q *Query) Ranked(columns, from, orderBy string, params ListParams) (string, []interface{}, *PageInfo) {
+	params = params.bounded()
+
+	query := fmt.Sprintf("SELECT %s\n    %s%s\n    ORDER BY %s\n    LIMIT %d OFFSET %d",
+		columns, from, q.clause(), orderBy, params.Limit, params.Offset)
+
+	return query, q.args, &PageInfo{
+		Limit:  params.Limit,
+		Offset: params.Offset,
+		Sort:   "relevance",
+		Order:  "desc",
+	}
+}
+
+type cursor struct {
+	Sort  string      `json:"s"`
+	Desc  bool        `json:"d"`
+	Value interface{} `json:"v"`
+	ID    int64       `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	value, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+func decodeCursor(value string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// numbers stay strings so bigint columns are compared exactly
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var c cursor
+	if err := decoder.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if number, ok := c.Value.(json.Number); ok {
+		c.Value = number.String()
+	}
+	return &c, nil
+}
+
+// Page is one page of a listing. Rows are fetched one past the limit to know
+// whether another page follows.
+type Page struct {
+	query   *Query
+	params  ListParams
+	sort    string
+	column  string
+	id      string
+	after   *cursor
+	rows    int
+	lastKey interface{}
+	lastID  int64
+	more    bool
+}
+
+// Page checks params against sorting and prepares the page to fetch.
+func (q *Query) Page(params ListParams, sorting Sorting) (*Page, error) {
+	if params.Sort == "" {
+		params.Sort = sorting.Default
+	}
+	column, ok := sorting.Keys[params.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	params = params.bounded()
+
+	page := &Page{
+		query:  q,
+		params: params,
+		sort:   params.Sort,
+		column: column,
+		id:     sorting.ID,
+	}
+
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// a cursor only makes sense in the order it was made for
+		if after.Sort != params.Sort || after.Desc != params.Desc {
+			return nil, ErrInvalidCursor
+		}
+		page.after = after
+		page.params.Offset = 0
+	}
+
+	return page, nil
+}
+
+// SQL returns the query of the page. The sort column is selected last, scan it
+// and hand it to Keep with the row id.
+func (p *Page) SQL(columns, from string) (string, []interface{}) {
+	q := &Query{
+		where: append([]string{}, p.query.where...),
+		args:  append([]interface{}{}, p.query.args...),
+	}
+
+	direction, compare := "ASC", ">"
+	if p.params.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if p.after != nil {
+		q.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", p.column, p.id, compare), p.after.Value, p.after.ID)
+	}
+
+	query := fmt.Sprintf("SELECT %s,\n    %s\n    %s%s\n    ORDER BY %s %s, %s %s\n    LIMIT %d OFFSET %d",
+		columns, p.column, from, q.clause(),
+		p.column, direction, p.id, direction,
+		p.params.Limit+1, p.params.Offset)
+
+	return query, q.args
+}
+
+// Keep records a scanned row, it reports false for the row past the limit,
+// which is not part of the page.
+func (p *Page) Keep(sortKey interface{}, id int64) bool {
+	if p.rows == p.params.Limit {
+		p.more = true
+		return false
+	}
+	p.rows++
+	// numeric columns are scanned as bytes
+	if raw, ok := sortKey.([]byte); ok {
+		sortKey = string(raw)
+	}
+	p.lastKey = sortKey
+	p.lastID = id
+	return true
+}
+
+// Info describes the page once its rows are scanned.
+func (p *Page) Info(total int64) *PageInfo {
+	info := &PageInfo{
+		Total:  total,
+		Limit:  p.params.Limit,
+		Offset: p.params.Offset,
+		Sort:   p.sort,
+		Order:  "asc",
+	}
+	if p.params.Desc {
+		info.Order = "desc"
+	}
+
+	if p.more {
+		info.NextCursor = encodeCursor(cursor{
+			Sort:  p.sort,
+			Desc:  p.params.Desc,
+			Value: p.lastKey,
+			ID:    p.lastID,
+		})
+	}
+
+	return info
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	RoleRequestPending  = "pending"
+	RoleRequestApproved = "approved"
+	RoleRequestRejected = "rejected"
+)
+
+var ErrRoleRequestExists = errors.New("permintaan role yang sama masih menunggu persetujuan")
+
+// RoleRequests is a user's application for a cognito group, reviewed by an
+// admin.
+type RoleRequests struct {
+	ID         int64  `json:"id"`
+	UserID     string `json:"user_id"`
+	Role       string `json:"role"`
+	Reason     string `json:"reason"`
+	Status     string `json:"status"`
+	Reviewer   string `json:"reviewer"`
+	ReviewNote string `json:"review_note"`
+	CreatedAt  int64  `json:"created_at"`
+	ReviewedAt *int64 `json:"reviewed_at,omitempty"`
+}
+
+const roleRequestColumns = `
+    id, user_id, role, reason, status, reviewer, review_note, created_at, reviewed_at
+    `
+
+func scanRoleRequest(row interface{ Scan(...interface{}) error }, request *RoleRequests) error {
+	return row.Scan(
+		&request.ID,
+		&request.UserID,
+		&request.Role,
+		&request.Reason,
+		&request.Status,
+		&request.Reviewer,
+		&request.ReviewNote,
+		&request.CreatedAt,
+		&request.ReviewedAt,
+	)
+}
+
+func (request *RoleRequests) Insert(db *sql.DB) error {
+	query := `
+    INSERT INTO role_requests(
+    user_id,
+    role,
+    reason,
+    status,
+    reviewer,
+    created_at,
+    reviewed_at
+    ) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+    )
+    RETURNING id
+    `
+
+	request.CreatedAt = time.Now().Unix()
+	if request.Status == "" {
+		request.Status = RoleRequestPending
+	}
+
+	args := []interface{}{
+		request.UserID,
+		request.Role,
+		request.Reason,
+		request.Status,
+		request.Reviewer,
+		request.CreatedAt,
+		request.ReviewedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, query, args...).Scan(&request.ID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrRoleRequestExists
+		}
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAll lists role requests, newest first. Empty userId or status match
+// every request.
+func (request *RoleRequests) GetAll(userId, status string, db *sql.DB) ([]RoleRequests, error) {
+	query := `
+    SELECT` + roleRequestColumns + `FROM role_requests
+    WHERE ($1 = '' OR user_id = $1) AND ($2 = '' OR status = $2)
+    ORDER BY created_at DESC
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, userId, status)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RoleRequests
+	for rows.Next() {
+		var each RoleRequests
+		if err := scanRoleRequest(rows, &each); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
+
+func (request *RoleRequests) Get(id int64, db *sql.DB) (*RoleRequests, error) {
+	query := `
+    SELECT` + roleRequestColumns + `FROM role_requests
+    WHERE id = $1
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := scanRoleRequest(db.QueryRowContext(ctx, query, id), request)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// Review closes a pending request with status approved or rejected.
+func (request *RoleRequests) Review(id int64, status, reviewer, note string, db *sql.DB) (*RoleRequests, error) {
+	query := `
+    UPDATE role_requests
+    SET status = $1,
+    reviewer = $2,
+    review_note = $3,
+    reviewed_at = $4
+    WHERE id = $5 AND status = 'pending'
+    RETURNING` + roleRequestColumns
+
+	args := []interface{}{
+		status,
+		reviewer,
+		note,
+		time.Now().Unix(),
+		id,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := scanRoleRequest(db.QueryRowContext(ctx, query, args...), request)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return request, nil
+}
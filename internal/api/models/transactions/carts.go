@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/net/context"
 	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
 )
 
 type Carts struct {
@@ -14,31 +15,48 @@ type Carts struct {
 	CustomerID   string `json:"customer_id"`
 	ProductID    int64  `json:"product_id,omitempty"`
 	ProductName  string `json:"product_name"`
+	VariantID    int64  `json:"variant_id,omitempty"`
 	SizeTypeID   int8   `json:"size_type_id,omitempty"`
 	SizeTypeName string `json:"size_type_name"`
+	Price        int32  `json:"price"`
 	Quantity     int32  `json:"quantity"`
 	Comments     string `json:"comments"`
 	CreatedAt    int64  `json:"created_at"`
 }
 
+// chosenVariant matches the active variant of the cart, the one in
+// variant_id or else the one of size_type_id, of a product not in the trash.
+const chosenVariant = `
+    v.product_id = $2 AND v.active
+    AND (v.id = $3 OR ($3 = 0 AND v.size_type_id = $4))
+    AND EXISTS (SELECT 1 FROM products p WHERE p.id = v.product_id AND p.deleted_at IS NULL)
+    `
+
+// Insert adds the chosen variant of the product to the cart of userID.
 func (cart *Carts) Insert(userID string, db *sql.DB) error {
 	query := `
     INSERT INTO carts(
     customer_id,
     product_id,
+    variant_id,
+    size_type_id,
     quantity,
-	size_type_id,
     comments,
     created_at
-    ) VALUES($1, $2, $3, $4, $5,$6)
+    )
+    SELECT $1, v.product_id, v.id, v.size_type_id, $5, $6, $7
+    FROM product_variants v
+    WHERE` + chosenVariant + `
+    RETURNING id
     `
 
 	timeNow := time.Now().UnixMilli()
 	args := []interface{}{
 		userID,
 		cart.ProductID,
-		cart.Quantity,
+		cart.VariantID,
 		cart.SizeTypeID,
+		cart.Quantity,
 		cart.Comments,
 		timeNow,
 	}
@@ -46,7 +64,10 @@ func (cart *Carts) Insert(userID string, db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err := db.ExecContext(ctx, query, args...)
+	err := db.QueryRowContext(ctx, query, args...).Scan(&cart.ID)
+	if err == sql.ErrNoRows {
+		return products.ErrVariantNotFound
+	}
 	if err != nil {
 		log.Println(err.Error())
 		return err
@@ -55,52 +76,90 @@ func (cart *Carts) Insert(userID string, db *sql.DB) error {
 	return nil
 }
 
-func (carts *Carts) GetAll(userID string, db *sql.DB) ([]Carts, error) {
-	query := `
-    SELECT
+var cartSorting = models.Sorting{
+	Keys: map[string]string{
+		"created": "c.created_at",
+		"name":    "p.product_name",
+	},
+	Default: "created",
+	ID:      "c.id",
+}
+
+// GetAll returns one page of the carts of userID.
+func (carts *Carts) GetAll(userID string, params models.ListParams, db *sql.DB) ([]Carts, *models.PageInfo, error) {
+	q := models.NewQuery().Where("c.customer_id = ?", userID)
+
+	page, err := q.Page(params, cartSorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := `FROM carts c
+    INNER JOIN products p ON c.product_id = p.id
+    INNER JOIN product_variants v ON c.variant_id = v.id
+    INNER JOIN size_type s ON v.size_type_id = s.id`
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL(`
     c.id,
     c.customer_id,
+    c.product_id,
     p.product_name,
-    c.quantity,
+    c.variant_id,
+    v.size_type_id,
     s.name,
+    v.mrp,
+    c.quantity,
     c.comments,
-    c.created_at
-    FROM carts c
-    INNER JOIN products p ON c.product_id = p.id
-    INNER JOIN size_type s ON c.size_type_id = s.id
-    WHERE c.customer_id = $1
-    `
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	rows, err := db.QueryContext(ctx, query, userID)
+    c.created_at`, from)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		log.Println(err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var result []Carts
+	result := []Carts{}
 	for rows.Next() {
 		var each = Carts{}
+		var sortKey interface{}
 		var err = rows.Scan(
 			&each.ID,
 			&each.CustomerID,
+			&each.ProductID,
 			&each.ProductName,
-			&each.Quantity,
+			&each.VariantID,
+			&each.SizeTypeID,
 			&each.SizeTypeName,
+			&each.Price,
+			&each.Quantity,
 			&each.Comments,
 			&each.CreatedAt,
+			&sortKey,
 		)
 		if err != nil {
 			log.Println(err.Error())
-			return nil, err
+			return nil, nil, err
 		}
 
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
 		result = append(result, each)
 	}
 
-	return result, nil
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
 }
 
 func (cart *Carts) GetID(id int64, userID string, db *sql.DB) (*Carts, error) {
@@ -108,14 +167,19 @@ func (cart *Carts) GetID(id int64, userID string, db *sql.DB) (*Carts, error) {
     SELECT
     c.id,
     c.customer_id,
+    c.product_id,
     p.product_name,
-    c.quantity,
+    c.variant_id,
+    v.size_type_id,
     s.name,
+    v.mrp,
+    c.quantity,
     c.comments,
     c.created_at
     FROM carts c
     INNER JOIN products p ON c.product_id = p.id
-    INNER JOIN size_type s ON c.size_type_id = s.id
+    INNER JOIN product_variants v ON c.variant_id = v.id
+    INNER JOIN size_type s ON v.size_type_id = s.id
     WHERE c.id = $1 AND c.customer_id = $2
     LIMIT 1
     `
@@ -131,9 +195,13 @@ func (cart *Carts) GetID(id int64, userID string, db *sql.DB) (*Carts, error) {
 	if err := row.Scan(
 		&cart.ID,
 		&cart.CustomerID,
+		&cart.ProductID,
 		&cart.ProductName,
-		&cart.Quantity,
+		&cart.VariantID,
+		&cart.SizeTypeID,
 		&cart.SizeTypeName,
+		&cart.Price,
+		&cart.Quantity,
 		&cart.Comments,
 		&cart.CreatedAt,
 	); err != nil {
@@ -144,46 +212,47 @@ func (cart *Carts) GetID(id int64, userID string, db *sql.DB) (*Carts, error) {
 	return cart, nil
 }
 
+// Update replaces the variant, quantity and comments of the cart.
 func (cart *Carts) Update(id int64, userID string, db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-
 	query := `
-    UPDATE carts
-	SET product_id = $1,
-	quantity = $2,
-    size_type_id = $3,
-	comments = $4
-	WHERE id = $5 AND customer_id $6
-    `
+    UPDATE carts c
+    SET product_id = v.product_id,
+    variant_id = v.id,
+    size_type_id = v.size_type_id,
+    quantity = $5,
+    comments = $6
+    FROM product_variants v
+    WHERE c.id = $1 AND c.customer_id = $7 AND` + chosenVariant
 
 	args := []interface{}{
+		id,
 		cart.ProductID,
-		cart.Quantity,
+		cart.VariantID,
 		cart.SizeTypeID,
+		cart.Quantity,
 		cart.Comments,
-		id, userID,
+		userID,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
-		tx.Rollback()
 		log.Println(err.Error())
 		return err
 	}
 
-	err = tx.Commit()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return models.ErrRecordNotFound
+	}
+
 	return nil
 }
 
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"payuoge.com/internal/api/models"
-	"payuoge.com/internal/api/models/products"
 )
 
 type Checkouts struct {
@@ -17,44 +16,121 @@ type Checkouts struct {
 	CreatedAt   int64   `json:"created_at"`
 }
 
-func (checkout *Checkouts) CalculateTotalAmount(userID string, db *sql.DB) float64 {
-	var carts Carts
-	var product products.Product
+// PriceLine is the price of one cart, UnitPrice is the cheapest tier the
+// customer reaches or else the price of the variant.
+type PriceLine struct {
+	CartID       int64  `json:"cart_id"`
+	ProductID    int64  `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	VariantID    int64  `json:"variant_id"`
+	SizeTypeName string `json:"size_type_name"`
+	Quantity     int32  `json:"quantity"`
+	BasePrice    int32  `json:"base_price"`
+	UnitPrice    int32  `json:"unit_price"`
+	TierID       *int64 `json:"tier_id,omitempty"`
+	GroupID      *int64 `json:"group_id,omitempty"`
+	Discount     int64  `json:"discount"`
+	Subtotal     int64  `json:"subtotal"`
+}
+
+type PriceBreakdown struct {
+	Lines       []PriceLine `json:"lines"`
+	Discount    int64       `json:"discount"`
+	TotalAmount float64     `json:"total_amount"`
+}
+
+// CalculateTotalAmount prices every cart of userID at the tier of its
+// variant for its quantity, tiers of the customer groups of userID included.
+func (checkout *Checkouts) CalculateTotalAmount(userID string, db *sql.DB) (*PriceBreakdown, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	return priceCarts(ctx, db, userID)
+}
+
+// queryer runs a query on a *sql.DB or within a *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// priceCarts is CalculateTotalAmount on q, so an order can price its carts
+// within its transaction.
+func priceCarts(ctx context.Context, q queryer, userID string) (*PriceBreakdown, error) {
 	query := `
     SELECT
-    c.product_id,
+    c.id,
+    v.product_id,
+    p.product_name,
+    c.variant_id,
+    s.name,
     c.quantity,
-    p.mrp
+    v.mrp,
+    t.id,
+    t.group_id,
+    COALESCE(t.unit_price, v.mrp)
     FROM carts c
-    INNER JOIN products p ON c.product_id = p.id
+    INNER JOIN product_variants v ON c.variant_id = v.id
+    INNER JOIN products p ON v.product_id = p.id
+    INNER JOIN size_type s ON v.size_type_id = s.id
+    LEFT JOIN LATERAL (
+    SELECT pt.id, pt.group_id, pt.unit_price
+    FROM price_tiers pt
+    WHERE pt.variant_id = v.id
+    AND pt.min_quantity <= c.quantity
+    AND pt.unit_price < v.mrp
+    AND (pt.group_id IS NULL OR EXISTS (
+    SELECT 1 FROM customer_group_members m
+    WHERE m.group_id = pt.group_id AND m.customer_id = c.customer_id
+    ))
+    ORDER BY pt.unit_price, pt.min_quantity DESC, pt.id
+    LIMIT 1
+    ) t ON true
     WHERE c.customer_id = $1
+    ORDER BY c.id
     `
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query, userID)
+	rows, err := q.QueryContext(ctx, query, userID)
 	if err != nil {
 		log.Printf("Error querying cart items: %v", err)
-		return 0.0
+		return nil, err
 	}
-
 	defer rows.Close()
-	totalAmount := 0.0
+
+	breakdown := PriceBreakdown{Lines: []PriceLine{}}
+	var total int64
 	for rows.Next() {
+		var line PriceLine
 		if err := rows.Scan(
-			&carts.ProductID,
-			&carts.Quantity,
-			&product.MRP,
+			&line.CartID,
+			&line.ProductID,
+			&line.ProductName,
+			&line.VariantID,
+			&line.SizeTypeName,
+			&line.Quantity,
+			&line.BasePrice,
+			&line.TierID,
+			&line.GroupID,
+			&line.UnitPrice,
 		); err != nil {
 			log.Printf("Error scanning row: %v", err)
-			continue
+			return nil, err
 		}
-		totalAmount += float64(carts.Quantity) * float64(product.MRP)
 
+		line.Subtotal = int64(line.Quantity) * int64(line.UnitPrice)
+		line.Discount = int64(line.Quantity)*int64(line.BasePrice) - line.Subtotal
+
+		breakdown.Lines = append(breakdown.Lines, line)
+		breakdown.Discount += line.Discount
+		total += line.Subtotal
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
 	}
 
-	return totalAmount
+	breakdown.TotalAmount = float64(total)
+	return &breakdown, nil
 }
 
 func (checkout *Checkouts) Insert(userID string, totalAmount float64, db *sql.DB) error {
@@ -0,0 +1,97 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+// OrderConfirmations records a grocery accepting its lines of an order.
+type OrderConfirmations struct {
+	ID               int64  `json:"id"`
+	OrderID          int64  `json:"order_id"`
+	GroceriesID      string `json:"groceries_id"`
+	ConfirmationDate int64  `json:"confirmation_date"`
+	Lines            int    `json:"lines"`
+	OrderStatus      string `json:"order_status"`
+}
+
+// Insert turns the stock the placed order holds at the grocery into sales,
+// the order is confirmed once no grocery holds stock for it anymore. It
+// returns ErrRecordNotFound when the order has no lines left to confirm at the
+// grocery and ErrOrderClosed when the order is no longer placed or its
+// reservations expired.
+func (confirmation *OrderConfirmations) Insert(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOrder(ctx, tx, confirmation.OrderID, ""); err != nil {
+		return err
+	}
+
+	lines, err := products.CommitReservations(tx, confirmation.OrderID, confirmation.GroceriesID, confirmation.GroceriesID)
+	if errors.Is(err, products.ErrReservationExpired) {
+		return ErrOrderClosed
+	}
+	if err != nil {
+		return err
+	}
+	if lines == 0 {
+		return models.ErrRecordNotFound
+	}
+
+	confirmation.Lines = lines
+	confirmation.ConfirmationDate = time.Now().UnixMilli()
+
+	query := `
+    INSERT INTO order_confirmation (
+    order_id,
+    groceries_id,
+    confirmation_date
+    ) VALUES ($1, $2, $3)
+    RETURNING id
+    `
+
+	args := []interface{}{
+		confirmation.OrderID,
+		confirmation.GroceriesID,
+		confirmation.ConfirmationDate,
+	}
+
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&confirmation.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	query = `
+    UPDATE orders SET status = $1
+    WHERE id = $2 AND NOT EXISTS (
+    SELECT 1 FROM stock_reservations WHERE order_id = $2 AND status = $3
+    )
+    RETURNING status
+    `
+
+	err = tx.QueryRowContext(ctx, query, OrderConfirmed, confirmation.OrderID, products.ReservationReserved).Scan(&confirmation.OrderStatus)
+	switch {
+	case err == sql.ErrNoRows:
+		// other groceries still hold stock for the order
+		confirmation.OrderStatus = OrderPlaced
+	case err != nil:
+		log.Println(err.Error())
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,130 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// OrderItems is a line of an order as it was ordered, its name and prices
+// stay when the product changes or goes to the trash. Price is the subtotal
+// of the line.
+type OrderItems struct {
+	ID           int64   `json:"id"`
+	OrderID      int64   `json:"order_id"`
+	ProductID    int64   `json:"product_id"`
+	VariantID    *int64  `json:"variant_id,omitempty"`
+	ProductName  string  `json:"product_name"`
+	SizeTypeName string  `json:"size_type_name"`
+	Quantity     int32   `json:"quantity"`
+	BasePrice    int32   `json:"base_price"`
+	UnitPrice    int32   `json:"unit_price"`
+	Price        float64 `json:"price"`
+}
+
+// insertOrderItems records the priced carts as the items of the order
+// within tx.
+func insertOrderItems(ctx context.Context, tx *sql.Tx, orderID int64, lines []PriceLine) ([]OrderItems, error) {
+	query := `
+    INSERT INTO order_items(
+    order_id,
+    product_id,
+    variant_id,
+    product_name,
+    size_type_name,
+    quantity,
+    base_price,
+    unit_price,
+    price
+    ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING id
+    `
+
+	items := make([]OrderItems, 0, len(lines))
+	for _, line := range lines {
+		variantId := line.VariantID
+		item := OrderItems{
+			OrderID:      orderID,
+			ProductID:    line.ProductID,
+			VariantID:    &variantId,
+			ProductName:  line.ProductName,
+			SizeTypeName: line.SizeTypeName,
+			Quantity:     line.Quantity,
+			BasePrice:    line.BasePrice,
+			UnitPrice:    line.UnitPrice,
+			Price:        float64(line.Subtotal),
+		}
+
+		args := []interface{}{
+			item.OrderID,
+			item.ProductID,
+			item.VariantID,
+			item.ProductName,
+			item.SizeTypeName,
+			item.Quantity,
+			item.BasePrice,
+			item.UnitPrice,
+			item.Price,
+		}
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&item.ID); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// GetAll returns the items of the order.
+func (item *OrderItems) GetAll(orderID int64, db *sql.DB) ([]OrderItems, error) {
+	query := `
+    SELECT id, order_id, product_id, variant_id, product_name, size_type_name,
+    quantity, base_price, unit_price, COALESCE(price, 0)
+    FROM order_items
+    WHERE order_id = $1
+    ORDER BY id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []OrderItems{}
+	for rows.Next() {
+		var each = OrderItems{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.OrderID,
+			&each.ProductID,
+			&each.VariantID,
+			&each.ProductName,
+			&each.SizeTypeName,
+			&each.Quantity,
+			&each.BasePrice,
+			&each.UnitPrice,
+			&each.Price,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
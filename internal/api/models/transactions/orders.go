@@ -3,87 +3,377 @@ package transactions
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"time"
 
 	"payuoge.com/internal/api/models"
+	"payuoge.com/internal/api/models/products"
+)
+
+// statuses of orders
+const (
+	OrderPlaced    = "placed"
+	OrderConfirmed = "confirmed"
+	OrderCancelled = "cancelled"
+	OrderExpired   = "expired"
+)
+
+var (
+	ErrOrderClosed = errors.New("order sudah tidak berstatus placed")
+	ErrEmptyCart   = errors.New("keranjang kosong")
 )
 
 type Orders struct {
-	ID          int64  `json:"id"`
-	CustomerID  string `json:"customer_id"`
-	TotalAmount int32  `json:"total_amount"`
-	OrderDate   int64  `json:"order_date"`
+	ID          int64        `json:"id"`
+	CustomerID  string       `json:"customer_id"`
+	TotalAmount int32        `json:"total_amount"`
+	OrderDate   int64        `json:"order_date"`
+	Status      string       `json:"status"`
+	ExpiresAt   int64        `json:"expires_at,omitempty"`
+	Items       []OrderItems `json:"items,omitempty"`
 }
 
-func (order *Orders) Insert(userID string, totalAmount int32, db *sql.DB) error {
+// Insert places the order and reserves the stock of every cart of userID
+// until the groceries confirm it or ttl passes. The carts are priced as at
+// checkout and kept as the items of the order. When the stock cannot fill
+// some carts nothing is placed and the error is an
+// *products.InsufficientStockError listing them. The carts are emptied by
+// the order, ErrEmptyCart is returned when there are none.
+func (order *Orders) Insert(userID string, ttl time.Duration, db *sql.DB) error {
 	query := `
     INSERT INTO orders (
     customer_id,
     total_amount,
-    order_date
-    ) VALUES ($1, $2, $3)
+    order_date,
+    status
+    ) VALUES ($1, $2, $3, $4)
+    RETURNING id
     `
-	timeNow := time.Now().UnixMilli()
+	timeNow := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	defer tx.Rollback()
+
+	// a second submit waits here and then finds the carts gone
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM carts WHERE customer_id = $1 FOR UPDATE`, userID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	breakdown, err := priceCarts(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	if len(breakdown.Lines) == 0 {
+		return ErrEmptyCart
+	}
+	totalAmount := int32(breakdown.TotalAmount)
 
 	args := []interface{}{
 		userID,
 		totalAmount,
-		timeNow,
+		timeNow.UnixMilli(),
+		OrderPlaced,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&order.ID); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if order.Items, err = insertOrderItems(ctx, tx, order.ID, breakdown.Lines); err != nil {
+		return err
+	}
 
-	_, err := db.ExecContext(ctx, query, args...)
+	reservations, err := cartReservations(ctx, tx, userID, order.ID, timeNow.Add(ttl).Unix())
 	if err != nil {
+		return err
+	}
+
+	if err := products.Reserve(tx, reservations); err != nil {
+		return err
+	}
+
+	// the carts became the order, submitting again must not order them twice
+	if _, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE customer_id = $1`, userID); err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
-	return nil
+	order.CustomerID = userID
+	order.TotalAmount = totalAmount
+	order.OrderDate = timeNow.UnixMilli()
+	order.Status = OrderPlaced
+	order.ExpiresAt = timeNow.Add(ttl).Unix()
+	return tx.Commit()
 }
 
-func (order *Orders) GetAll(userID string, db *sql.DB) ([]Orders, error) {
+// cartReservations returns a reservation of the order for every cart of
+// userID, in the unit of its variant.
+func cartReservations(ctx context.Context, tx *sql.Tx, userID string, orderID, expiresAt int64) ([]*products.StockReservations, error) {
 	query := `
-    SELECT * FROM orders WHERE customer_id = $1 
+    SELECT c.product_id, c.variant_id, c.quantity
+    FROM carts c
+    WHERE c.customer_id = $1
+    ORDER BY c.id
     `
 
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reservations []*products.StockReservations
+	for rows.Next() {
+		var variantId int64
+		reservation := &products.StockReservations{
+			OrderID:   orderID,
+			VariantID: &variantId,
+			ExpiresAt: expiresAt,
+		}
+		if err := rows.Scan(&reservation.ProductID, &variantId, &reservation.VariantQuantity); err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+// lockOrder locks the order until tx ends, of customerID when it is not
+// empty. It returns ErrOrderClosed unless the order is still placed.
+func lockOrder(ctx context.Context, tx *sql.Tx, id int64, customerID string) error {
+	query := `
+    SELECT status FROM orders
+    WHERE id = $1 AND ($2::text = '' OR customer_id = $2) AND deleted_at IS NULL
+    FOR UPDATE
+    `
+
+	var status string
+	err := tx.QueryRowContext(ctx, query, id, customerID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if status != OrderPlaced {
+		return ErrOrderClosed
+	}
+	return nil
+}
+
+// closeOrder releases the stock the order still holds and sets its status
+// within tx, the order must be locked. An order a grocery already confirmed
+// part of keeps those sales and becomes confirmed instead.
+func closeOrder(ctx context.Context, tx *sql.Tx, id int64, status string) error {
+	if _, err := products.ReleaseReservations(tx, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+    UPDATE orders SET status = CASE WHEN EXISTS (
+    SELECT 1 FROM stock_reservations WHERE order_id = $2 AND status = $3
+    ) THEN $4 ELSE $1 END
+    WHERE id = $2
+    `, status, id, products.ReservationCommitted, OrderConfirmed); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Cancel releases the stock of the placed order of userID.
+func (order *Orders) Cancel(id int64, userID string, db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query, userID)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Println(err.Error())
-		return nil, err
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOrder(ctx, tx, id, userID); err != nil {
+		return err
+	}
+
+	if err := closeOrder(ctx, tx, id, OrderCancelled); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ExpireOrders releases the stock of up to limit placed orders whose
+// reservations expired, one transaction per order. Orders being confirmed or
+// cancelled at the same time are skipped and picked up by a later call.
+func ExpireOrders(limit int, db *sql.DB) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+    SELECT DISTINCT order_id FROM stock_reservations
+    WHERE status = $1 AND expires_at <= $2
+    ORDER BY order_id
+    LIMIT $3
+    `, products.ReservationReserved, time.Now().Unix(), limit)
+	if err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			log.Println(err.Error())
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return 0, err
+	}
+
+	expired := 0
+	for _, id := range ids {
+		ok, err := expireOrder(id, db)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func expireOrder(id int64, db *sql.DB) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Println(err.Error())
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `
+    SELECT status FROM orders WHERE id = $1 FOR UPDATE SKIP LOCKED
+    `, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return false, err
+	}
+
+	if status != OrderPlaced {
+		return false, nil
+	}
+	if err := closeOrder(ctx, tx, id, OrderExpired); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+var orderSorting = models.Sorting{
+	Keys: map[string]string{
+		"date":  "COALESCE(order_date, 0)",
+		"total": "COALESCE(total_amount, 0)",
+	},
+	Default: "date",
+	ID:      "id",
+}
+
+// GetAll returns one page of the orders of userID.
+func (order *Orders) GetAll(userID string, params models.ListParams, db *sql.DB) ([]Orders, *models.PageInfo, error) {
+	q := models.NewQuery().Where("customer_id = ?", userID).Where("deleted_at IS NULL")
+
+	page, err := q.Page(params, orderSorting)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	from := "FROM orders"
+	total, err := q.Count(ctx, db, from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query, args := page.SQL("id, customer_id, total_amount, order_date, status", from)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
 	}
 
 	defer rows.Close()
 
-	var result []Orders
+	result := []Orders{}
 	for rows.Next() {
 		var each = Orders{}
+		var sortKey interface{}
 		var err = rows.Scan(
 			&each.ID,
 			&each.CustomerID,
 			&each.TotalAmount,
 			&each.OrderDate,
+			&each.Status,
+			&sortKey,
 		)
 
 		if err != nil {
 			log.Println(err.Error())
-			return nil, err
+			return nil, nil, err
 		}
 
+		if !page.Keep(sortKey, each.ID) {
+			break
+		}
 		result = append(result, each)
 	}
 
-	return result, nil
+	if err := rows.Err(); err != nil {
+		log.Println(err.Error())
+		return nil, nil, err
+	}
+
+	return result, page.Info(total), nil
 }
 
+// GetID returns the order of userID with its items.
 func (order *Orders) GetID(id int64, userID string, db *sql.DB) (*Orders, error) {
 	query := `
-    SELECT * FROM checkouts WHERE id = $1 AND customer_id = $2 LIMIT 1
+    SELECT id, customer_id, total_amount, order_date, status
+    FROM orders WHERE id = $1 AND customer_id = $2 AND deleted_at IS NULL LIMIT 1
     `
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -98,76 +388,106 @@ func (order *Orders) GetID(id int64, userID string, db *sql.DB) (*Orders, error)
 		&order.CustomerID,
 		&order.TotalAmount,
 		&order.OrderDate,
+		&order.Status,
 	); err != nil {
 		log.Println(err.Error())
 		return nil, err
 	}
 
+	var item OrderItems
+	items, err := item.GetAll(order.ID, db)
+	if err != nil {
+		return nil, err
+	}
+	order.Items = items
+
 	return order, nil
 }
 
+// Update sets the total of the order of userID again from its items as they
+// were ordered, the total is never taken from the client.
 func (order *Orders) Update(id int64, userID string, db *sql.DB) error {
-	tx, err := db.Begin()
-	if err != nil {
-		log.Println(err.Error())
-		return err
-	}
-
 	query := `
     UPDATE orders
-    SET total_amount = $1
-    WHERE id = $2 AND customer_id = $3
+    SET total_amount = (SELECT COALESCE(SUM(price), 0) FROM order_items WHERE order_id = $1)
+    WHERE id = $1 AND customer_id = $2 AND deleted_at IS NULL
     `
 
-	args := []interface{}{
-		order.TotalAmount,
-		id, userID,
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	_, err = tx.ExecContext(ctx, query, args...)
+	result, err := db.ExecContext(ctx, query, id, userID)
 	if err != nil {
-		tx.Rollback()
 		log.Println(err.Error())
 		return err
 	}
 
-	err = tx.Commit()
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return models.ErrRecordNotFound
+	}
+
 	return nil
 }
 
+// Delete hides the order of userID, a placed order is cancelled first and
+// gives back the stock it holds. The order and its items are kept to
+// reconstruct its invoice.
 func (order *Orders) Delete(id int64, userID string, db *sql.DB) error {
-	query := `
-    DELETE FROM orders
-    WHERE id = $1 AND customer_id = $2
-    `
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	args := []interface{}{
-		id, userID,
-	}
 
-	result, err := db.ExecContext(ctx, query, args...)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	switch err := lockOrder(ctx, tx, id, userID); err {
+	case nil:
+		if err := closeOrder(ctx, tx, id, OrderCancelled); err != nil {
+			return err
+		}
+	case ErrOrderClosed:
+	default:
+		return err
+	}
+
+	query := `
+    UPDATE orders SET deleted_at = $3
+    WHERE id = $1 AND customer_id = $2 AND deleted_at IS NULL
+    `
+
+	if _, err := tx.ExecContext(ctx, query, id, userID, time.Now().Unix()); err != nil {
 		log.Println(err.Error())
 		return err
 	}
 
-	if rowsAffected == 0 {
+	return tx.Commit()
+}
+
+// Owned returns ErrRecordNotFound unless the order belongs to userID.
+func (order *Orders) Owned(id int64, userID string, db *sql.DB) error {
+	query := `
+    SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1 AND customer_id = $2 AND deleted_at IS NULL)
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, query, id, userID).Scan(&exists); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if !exists {
 		return models.ErrRecordNotFound
 	}
 
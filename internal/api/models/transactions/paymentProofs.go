@@ -0,0 +1,116 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"payuoge.com/internal/api/models"
+)
+
+// PaymentProofs is a picture of a transfer receipt sent by the customer of
+// an order. The object is private, URL is a presigned download URL set when
+// the proof is served.
+type PaymentProofs struct {
+	ID          int64  `json:"id"`
+	OrderID     int64  `json:"order_id"`
+	CustomerID  string `json:"customer_id"`
+	ObjectKey   string `json:"-"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// InsertTx records the proof within tx, the order must belong to the
+// customer.
+func (proof *PaymentProofs) InsertTx(tx *sql.Tx) error {
+	query := `
+    INSERT INTO order_payment_proofs(
+    order_id,
+    customer_id,
+    object_key,
+    url,
+    content_type,
+    size,
+    created_at
+    )
+    SELECT id, customer_id, $3, $4, $5, $6, $7
+    FROM orders
+    WHERE id = $1 AND customer_id = $2 AND deleted_at IS NULL
+    RETURNING id
+    `
+
+	proof.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		proof.OrderID,
+		proof.CustomerID,
+		proof.ObjectKey,
+		proof.URL,
+		proof.ContentType,
+		proof.Size,
+		proof.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&proof.ID)
+	if err == sql.ErrNoRows {
+		return models.ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// GetAll returns the proofs of the order to its customer or to a grocery
+// holding lines of it.
+func (proof *PaymentProofs) GetAll(orderID int64, userID string, db *sql.DB) ([]PaymentProofs, error) {
+	query := `
+    SELECT id, order_id, customer_id, object_key, url, content_type, size, created_at
+    FROM order_payment_proofs
+    WHERE order_id = $1 AND (customer_id = $2 OR EXISTS (
+    SELECT 1 FROM stock_reservations WHERE order_id = $1 AND grocery_id = $2
+    ))
+    ORDER BY created_at, id
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, orderID, userID)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []PaymentProofs{}
+	for rows.Next() {
+		var each = PaymentProofs{}
+		var err = rows.Scan(
+			&each.ID,
+			&each.OrderID,
+			&each.CustomerID,
+			&each.ObjectKey,
+			&each.URL,
+			&each.ContentType,
+			&each.Size,
+			&each.CreatedAt,
+		)
+		if err != nil {
+			log.Println(err.Error())
+			return nil, err
+		}
+
+		result = append(result, each)
+	}
+
+	return result, rows.Err()
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// purposes of an upload, each is attached to a different target
+const (
+	UploadProductImage = "product_image"
+	UploadPaymentProof = "payment_proof"
+)
+
+const (
+	UploadPending   = "pending"
+	UploadCompleted = "completed"
+)
+
+var ErrUploadClosed = errors.New("upload sudah selesai atau kadaluarsa")
+
+// PendingUploads is an object a client was allowed to upload straight to the
+// storage. It is attached to its target, a product or an order, once the
+// client reports the upload done and the object is found.
+type PendingUploads struct {
+	ID          string `json:"id"`
+	OwnerID     string `json:"owner_id"`
+	Purpose     string `json:"purpose"`
+	TargetID    int64  `json:"target_id"`
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Status      string `json:"status"`
+	ExpiresAt   int64  `json:"expires_at"`
+	CreatedAt   int64  `json:"created_at"`
+	CompletedAt *int64 `json:"completed_at,omitempty"`
+}
+
+func (upload *PendingUploads) Insert(db *sql.DB) error {
+	query := `
+    INSERT INTO pending_uploads(
+    id,
+    owner_id,
+    purpose,
+    target_id,
+    object_key,
+    content_type,
+    size,
+    status,
+    expires_at,
+    created_at
+    ) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+    )
+    `
+
+	upload.Status = UploadPending
+	upload.CreatedAt = time.Now().Unix()
+
+	args := []interface{}{
+		upload.ID,
+		upload.OwnerID,
+		upload.Purpose,
+		upload.TargetID,
+		upload.ObjectKey,
+		upload.ContentType,
+		upload.Size,
+		upload.Status,
+		upload.ExpiresAt,
+		upload.CreatedAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (upload *PendingUploads) Get(id, ownerId string, db *sql.DB) (*PendingUploads, error) {
+	query := `
+    SELECT id, owner_id, purpose, target_id, object_key, content_type, size,
+    status, expires_at, created_at, completed_at
+    FROM pending_uploads
+    WHERE id = $1 AND owner_id = $2
+    `
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := db.QueryRowContext(ctx, query, id, ownerId).Scan(
+		&upload.ID,
+		&upload.OwnerID,
+		&upload.Purpose,
+		&upload.TargetID,
+		&upload.ObjectKey,
+		&upload.ContentType,
+		&upload.Size,
+		&upload.Status,
+		&upload.ExpiresAt,
+		&upload.CreatedAt,
+		&upload.CompletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// Complete closes a pending upload that has not expired, only one of
+// concurrent completions succeeds.
+func (upload *PendingUploads) Complete(tx *sql.Tx) error {
+	query := `
+    UPDATE pending_uploads
+    SET status = $1, completed_at = $2
+    WHERE id = $3 AND status = $4 AND expires_at >= $2
+    `
+
+	now := time.Now().Unix()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := tx.ExecContext(ctx, query, UploadCompleted, now, upload.ID, UploadPending)
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Println(err.Error())
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrUploadClosed
+	}
+
+	upload.Status = UploadCompleted
+	upload.CompletedAt = &now
+	return nil
+}
@@ -15,15 +15,24 @@ import (
 	"payuoge.com/configs"
 	"payuoge.com/docs"
 	"payuoge.com/internal/api/handlers"
+	"payuoge.com/internal/api/handlers/admin"
 	"payuoge.com/internal/api/handlers/category"
 	"payuoge.com/internal/api/handlers/operationals"
 	"payuoge.com/internal/api/handlers/products"
+	"payuoge.com/internal/api/handlers/profile"
 	"payuoge.com/internal/api/handlers/size"
 	"payuoge.com/internal/api/handlers/transactions"
+	"payuoge.com/internal/api/handlers/trash"
+	"payuoge.com/internal/api/handlers/uploads"
 	"payuoge.com/internal/api/middleware"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/cache"
+	"payuoge.com/pkg/storage"
 )
 
-func NewRoutes(db *sql.DB, caches *redis.Client) *gin.Engine {
+// NewRoutes builds the router on the shared identity provider connection and
+// object store, the servers use the same store for the trash purge.
+func NewRoutes(db *sql.DB, caches *redis.Client, conn *aws.AwsConnect, store storage.ObjectStore) *gin.Engine {
 	var config configs.AppConfiguration
 	if err := envconfig.Process(context.Background(), &config); err != nil {
 		log.Fatal(err.Error())
@@ -50,6 +59,24 @@ func NewRoutes(db *sql.DB, caches *redis.Client) *gin.Engine {
 
 	router.HandleMethodNotAllowed = true
 
+	// shared identity provider and clients, the token verifier caches the JWKS
+	sessions := cache.NewSessionStore(caches, config.Cache.SessionTTL)
+	states := cache.NewOAuthStateStore(caches, config.GoogleAuth.StateTTL)
+	limiter := cache.NewRateLimiter(caches)
+	limits := config.RateLimit
+	if local, ok := store.(*storage.LocalStore); ok {
+		router.StaticFS("/media", local.PublicFS())
+	}
+	throttles := &middleware.Throttles{
+		Login: cache.NewThrottle(caches, "login", config.Throttle),
+		Code:  cache.NewThrottle(caches, "code", config.Throttle),
+	}
+
+	// bearer tokens and API keys, account endpoints add RequireSession
+	authed := middleware.Auth(db, sessions, conn)
+	sessionOnly := middleware.RequireSession()
+	productRead := middleware.RequirePermissions(middleware.PermProductRead)
+
 	v1 := router.Group("/v1")
 	{
 		v1.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -57,83 +84,174 @@ func NewRoutes(db *sql.DB, caches *redis.Client) *gin.Engine {
 
 		// auth group
 		auth := v1.Group("/auth")
+		auth.Use(middleware.RateLimit(limiter, "auth", limits.Auth, limits.Window))
 		{
-			auth.POST("/login", middleware.Login(caches))
-			auth.POST("/register", middleware.Register)
-			auth.POST("/confirm", middleware.Confirmation)
-			auth.POST("/resend", middleware.ResendCode)
-			auth.POST("/forgot", middleware.ForgotPassword)
-			auth.POST("/reset", middleware.ResetPassword)
-			auth.GET("/logout", middleware.Logout(caches))
-			auth.POST("/add-user-groups", middleware.AddUserToGroup(caches))
-			auth.GET("/list-groups", middleware.GetGroups())
-			auth.GET("/google", middleware.LoginGA)
-			auth.GET("/callback", middleware.CallbackCognito(caches))
+			auth.POST("/login", middleware.Login(db, sessions, throttles, conn))
+			auth.POST("/refresh", middleware.Refresh(sessions, conn))
+			auth.POST("/register", middleware.Register(conn))
+			auth.POST("/confirm", middleware.Confirmation(conn))
+			auth.POST("/resend", middleware.ResendCode(throttles, conn))
+			auth.POST("/forgot", middleware.ForgotPassword(throttles, conn))
+			auth.POST("/reset", middleware.ResetPassword(conn))
+			auth.GET("/logout", middleware.Logout(sessions, conn))
+			auth.POST("/logout-all", authed, sessionOnly, middleware.LogoutAll(sessions, conn))
+			auth.POST("/users/:username/logout", authed, sessionOnly, middleware.RequirePermissions(middleware.PermSessionRevoke), middleware.ForceLogout(sessions, conn))
+			auth.GET("/users/:username/lockout", authed, sessionOnly, middleware.RequirePermissions(middleware.PermLockoutManage), middleware.LockoutStatus(throttles))
+			auth.DELETE("/users/:username/lockout", authed, sessionOnly, middleware.RequirePermissions(middleware.PermLockoutManage), middleware.Unlock(throttles))
+			auth.POST("/role-requests", authed, sessionOnly, middleware.RequestRole(db, conn))
+			auth.GET("/role-requests", authed, sessionOnly, middleware.MyRoleRequests(db))
+			auth.GET("/sessions", authed, sessionOnly, middleware.ListSessions(sessions))
+			auth.DELETE("/sessions/:id", authed, sessionOnly, middleware.RevokeSession(sessions))
+			auth.GET("/list-groups", authed, sessionOnly, middleware.RequirePermissions(middleware.PermGroupRead), middleware.GetGroups(conn))
+			auth.POST("/api-keys", authed, sessionOnly, middleware.RequirePermissions(middleware.PermAPIKeyManage), middleware.CreateAPIKey(db))
+			auth.GET("/api-keys", authed, sessionOnly, middleware.RequirePermissions(middleware.PermAPIKeyManage), middleware.ListAPIKeys(db))
+			auth.DELETE("/api-keys/:id", authed, sessionOnly, middleware.RequirePermissions(middleware.PermAPIKeyManage), middleware.RevokeAPIKey(db))
+			auth.GET("/google", middleware.LoginGA(states, conn))
+			auth.GET("/callback", middleware.CallbackCognito(db, sessions, states, conn, config.GoogleAuth.PostLoginURL))
+		}
+
+		// admin group
+		adminHand := v1.Group("/admin")
+		adminHand.Use(authed, sessionOnly, middleware.RequirePermissions(middleware.PermUserManage))
+		{
+			adminHand.GET("/users", admin.SearchUsers(conn))
+			adminHand.GET("/users/:username", admin.GetUser(conn))
+			adminHand.POST("/users/:username/disable", admin.DisableUser(db, sessions, conn))
+			adminHand.POST("/users/:username/enable", admin.EnableUser(conn))
+			adminHand.DELETE("/users/:username/groups/:group", admin.RemoveUserFromGroup(db, conn))
+			adminHand.GET("/role-requests", admin.GetRoleRequests(db))
+			adminHand.POST("/role-requests/:id/approve", admin.ApproveRoleRequest(db, conn))
+			adminHand.POST("/role-requests/:id/reject", admin.RejectRoleRequest(db))
+		}
+
+		// profile
+		profileHand := v1.Group("/profile")
+		profileHand.Use(authed, sessionOnly, middleware.RateLimit(limiter, "profile", limits.Profile, limits.Window))
+		{
+			profileHand.GET("", profile.Get(db))
+			profileHand.PUT("", profile.Update(db, conn))
 		}
 
 		// all product
 		productAllHand := v1.Group("/products")
-		productAllHand.Use(middleware.Auth(caches))
+		productAllHand.Use(authed, middleware.RateLimit(limiter, "products", limits.Products, limits.Window))
 		{
-			productAllHand.GET("", products.GetAll(db))
+			productAllHand.GET("", productRead, products.GetAll(db))
+			productAllHand.GET("/search", productRead, products.Search(db))
 
 			// category
 			categoryHand := productAllHand.Group("/category")
-			categoryHand.Use(middleware.Auth(caches))
 			{
-				categoryHand.POST("", category.Create(db))
-				categoryHand.GET("", category.GetAll(db))
-				categoryHand.GET("/:id", category.GetID(db))
-				categoryHand.PUT("/:id", category.Update(db))
-				categoryHand.DELETE("/:id", category.Delete(db))
+				categoryWrite := middleware.RequirePermissions(middleware.PermCategoryWrite)
+				categoryHand.POST("", categoryWrite, category.Create(db))
+				categoryHand.GET("", productRead, category.GetAll(db))
+				categoryHand.GET("/tree", productRead, category.Tree(db))
+				categoryHand.GET("/:id/breadcrumb", productRead, category.Breadcrumb(db))
+				categoryHand.GET("/:id", productRead, category.GetID(db))
+				categoryHand.PUT("/:id", categoryWrite, category.Update(db))
+				categoryHand.DELETE("/:id", categoryWrite, category.Delete(db))
 			}
 
 			// size type
 			sizeTypeHand := productAllHand.Group("/size")
-			sizeTypeHand.Use(middleware.Auth(caches))
 			{
-				sizeTypeHand.POST("", size.Create(db))
-				sizeTypeHand.GET("", size.GetAll(db))
-				sizeTypeHand.GET("/:id", size.GetID(db))
-				sizeTypeHand.PUT("/:id", size.Update(db))
-				sizeTypeHand.DELETE("/:id", size.Delete(db))
+				sizeWrite := middleware.RequirePermissions(middleware.PermSizeWrite)
+				sizeTypeHand.POST("", sizeWrite, size.Create(db))
+				sizeTypeHand.GET("", productRead, size.GetAll(db))
+				sizeTypeHand.GET("/convert", productRead, size.Convert(db))
+				sizeTypeHand.GET("/:id", productRead, size.GetID(db))
+				sizeTypeHand.PUT("/:id", sizeWrite, size.Update(db))
+				sizeTypeHand.DELETE("/:id", sizeWrite, size.Delete(db))
 			}
 		}
 
 		// product group
 		productHand := v1.Group("/product")
-		productHand.Use(middleware.Auth(caches))
+		productHand.Use(authed, middleware.RateLimit(limiter, "products", limits.Products, limits.Window))
 		{
-			productHand.GET("/:id", products.GetID(db))
+			productHand.GET("/:id", productRead, products.GetID(db))
+			productHand.GET("/:id/images", productRead, products.ListImages(db))
+			productHand.GET("/:id/variants", productRead, products.ListVariants(db))
 		}
 
 		// groceries group
 		groceriesHand := v1.Group("/groceries")
-		groceriesHand.Use(middleware.Auth(caches))
+		groceriesHand.Use(authed, middleware.RateLimit(limiter, "groceries", limits.Groceries, limits.Window))
 		{
 			operateHand := groceriesHand.Group("/operational")
 			{
+				operateWrite := middleware.RequirePermissions(middleware.PermOperationalWrite)
 				operateHand.GET("", operationals.GetAll(db))
-				operateHand.POST("", operationals.Create(db))
-				operateHand.PUT("/:id", operationals.Update(db))
+				operateHand.POST("", operateWrite, operationals.Create(db))
+				operateHand.PUT("/:id", operateWrite, operationals.Update(db))
 				operateHand.GET("/:id", operationals.GetId(db))
-				operateHand.DELETE("/:id", operationals.DeleteID(db))
+				operateHand.DELETE("/:id", operateWrite, operationals.DeleteID(db))
 			}
+			// reads are open to keys with the products:read scope, writes
+			// need products:write one by one
 			productGroceriesHand := groceriesHand.Group("/products")
+			productGroceriesHand.Use(productRead)
 			{
-				productGroceriesHand.POST("", products.Create(db))
+				productWrite := middleware.RequirePermissions(middleware.PermProductWrite)
+				productGroceriesHand.POST("", productWrite, products.Create(db))
 				productGroceriesHand.GET("", products.GetProductsGrocery(db)) // nanti pakai id grosir
-				productGroceriesHand.PUT("/:id", products.Update(db))
-				productGroceriesHand.DELETE("/:id", products.DeleteID(db))
+				productGroceriesHand.POST("/import", productWrite, products.ImportProducts(db, config.Import))
+				productGroceriesHand.GET("/import/:jobId", productWrite, products.GetImportJob(db))
+				productGroceriesHand.GET("/export", products.ExportProducts(db))
+				productGroceriesHand.GET("/by-code/:code", products.GetByCode(db))
+				productGroceriesHand.PUT("/:id", productWrite, products.Update(db))
+				productGroceriesHand.DELETE("/:id", productWrite, products.DeleteID(db))
+				productGroceriesHand.GET("/:id/history", products.ProductHistory(db))
+				productGroceriesHand.POST("/:id/images", productWrite, products.UploadImages(db, store, config.Storage))
+				productGroceriesHand.PUT("/:id/images/order", productWrite, products.ReorderImages(db))
+				productGroceriesHand.DELETE("/:id/images/:imageId", productWrite, products.DeleteImage(db, store))
+				productGroceriesHand.POST("/:id/variants", productWrite, products.CreateVariant(db))
+				productGroceriesHand.GET("/:id/variants", products.ListOwnVariants(db))
+				productGroceriesHand.PUT("/:id/variants/:variantId", productWrite, products.UpdateVariant(db))
+				productGroceriesHand.DELETE("/:id/variants/:variantId", productWrite, products.DeleteVariant(db))
+				pricingWrite := middleware.RequirePermissions(middleware.PermPricingWrite)
+				productGroceriesHand.GET("/:id/variants/:variantId/tiers", pricingWrite, products.ListPriceTiers(db))
+				productGroceriesHand.POST("/:id/variants/:variantId/tiers", pricingWrite, products.CreatePriceTier(db))
+				productGroceriesHand.DELETE("/:id/variants/:variantId/tiers/:tierId", pricingWrite, products.DeletePriceTier(db))
+			}
+			// the handlers check the permission of each type
+			trashHand := groceriesHand.Group("/trash")
+			{
+				trashHand.GET("", trash.GetAll(db))
+				trashHand.POST("/:type/:id/restore", trash.Restore(db))
+			}
+			// stock is written by keys with the stock:write scope only, the
+			// ledger is read like the other product reads
+			stockWrite := middleware.RequirePermissions(middleware.PermStockWrite)
+			groceriesHand.POST("/products/:id/stock", stockWrite, products.RecordStock(db))
+			groceriesHand.GET("/products/:id/stock-history", productRead, products.StockHistory(db))
+
+			groceryOrderHand := groceriesHand.Group("/orders")
+			groceryOrderHand.Use(middleware.RequirePermissions(middleware.PermOrderConfirm))
+			{
+				groceryOrderHand.POST("/:id/confirm", transactions.ConfirmOrder(db))
+				groceryOrderHand.GET("/:id/payment-proofs", transactions.GetPaymentProofs(db, store, config.Storage))
+			}
+
+			customerGroupHand := groceriesHand.Group("/customer-groups")
+			customerGroupHand.Use(middleware.RequirePermissions(middleware.PermPricingWrite))
+			{
+				customerGroupHand.POST("", products.CreateCustomerGroup(db))
+				customerGroupHand.GET("", products.ListCustomerGroups(db))
+				customerGroupHand.DELETE("/:groupId", products.DeleteCustomerGroup(db))
+				customerGroupHand.GET("/:groupId/members", products.ListGroupMembers(db))
+				customerGroupHand.POST("/:groupId/members", products.AddGroupMember(db))
+				customerGroupHand.DELETE("/:groupId/members/:customerId", products.RemoveGroupMember(db))
 			}
 		}
 
 		// transaction group
 		transactionHand := v1.Group("/transactions")
-		transactionHand.Use(middleware.Auth(caches))
+		transactionHand.Use(authed, middleware.RateLimit(limiter, "transactions", limits.Transactions, limits.Window))
 		{
 			// carts
 			transactionCartHand := transactionHand.Group("/carts")
+			transactionCartHand.Use(middleware.RequirePermissions(middleware.PermCartWrite))
 			{
 				transactionCartHand.POST("", transactions.CreateCart(db))
 				transactionCartHand.GET("", transactions.GetAllCart(db))
@@ -144,9 +262,11 @@ func NewRoutes(db *sql.DB, caches *redis.Client) *gin.Engine {
 
 			// checkout
 			transactionCheckoutHand := transactionHand.Group("/checkout")
+			transactionCheckoutHand.Use(middleware.RequirePermissions(middleware.PermCheckoutWrite))
 			{
 				transactionCheckoutHand.POST("", transactions.CreateCheckout(db))
 				transactionCheckoutHand.GET("", transactions.GetCheckout(db))
+				transactionCheckoutHand.GET("/preview", transactions.PreviewCheckout(db))
 				transactionCheckoutHand.GET("/:id", transactions.GetIDCheckout(db))
 				transactionCheckoutHand.PUT("/:id", transactions.UpdateCheckout(db))
 				transactionCheckoutHand.DELETE("/:id", transactions.DeleteCheckout(db))
@@ -154,13 +274,28 @@ func NewRoutes(db *sql.DB, caches *redis.Client) *gin.Engine {
 
 			// orders
 			transactionOrderHand := transactionHand.Group("/orders")
+			transactionOrderHand.Use(middleware.RequirePermissions(middleware.PermOrderWrite))
 			{
-				transactionOrderHand.POST("", transactions.CreateOrders(db))
+				transactionOrderHand.POST("", transactions.CreateOrders(db, config.Order))
 				transactionOrderHand.GET("", transactions.GetOrders(db))
 				transactionOrderHand.GET("/:id", transactions.GetIDOrder(db))
 				transactionOrderHand.PUT("/:id", transactions.UpdateOrder(db))
 				transactionOrderHand.DELETE("/:id", transactions.DeleteOrder(db))
+				transactionOrderHand.GET("/:id/payment-proofs", transactions.GetPaymentProofs(db, store, config.Storage))
+				transactionOrderHand.POST("/:id/cancel", transactions.CancelOrder(db))
+			}
+		}
+
+		// direct uploads, each purpose checks its own permission
+		uploadHand := v1.Group("/uploads")
+		{
+			uploadLimit := middleware.RateLimit(limiter, "uploads", limits.Groceries, limits.Window)
+			if local, ok := store.(*storage.LocalStore); ok {
+				uploadHand.PUT("/local/:token", uploadLimit, uploads.LocalPut(local))
+				uploadHand.GET("/local/:token", uploadLimit, uploads.LocalGet(local))
 			}
+			uploadHand.POST("", authed, uploadLimit, uploads.Create(db, store, config.Storage))
+			uploadHand.POST("/:id/complete", authed, uploadLimit, uploads.Complete(db, store, config.Storage))
 		}
 	}
 
@@ -16,6 +16,8 @@ import (
 	"github.com/sethvargo/go-envconfig"
 	"payuoge.com/configs"
 	"payuoge.com/internal/api/routes"
+	"payuoge.com/pkg/aws"
+	"payuoge.com/pkg/storage"
 )
 
 func Run(db *sql.DB, caches *redis.Client) error {
@@ -33,15 +35,28 @@ func Run(db *sql.DB, caches *redis.Client) error {
 		log.Printf("server %s listening on port: %d", config.AppEnv, config.Port)
 	}
 
+	// one identity provider connection and object store for the router and
+	// the trash purge
+	conn := aws.NewConnect()
+	store, err := storage.New(config.Storage, conn.S3)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
 	// running server
 	srv := &http.Server{
 		Addr:         ":" + strconv.Itoa(config.Port),
-		Handler:      routes.NewRoutes(db, caches),
+		Handler:      routes.NewRoutes(db, caches, conn, store),
 		IdleTimeout:  time.Minute,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
 
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	go sweepOrders(sweepCtx, db, config.Order)
+	go purgeTrash(sweepCtx, db, store, config.Trash)
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Listen:%s\n", err)
@@ -53,6 +68,7 @@ func Run(db *sql.DB, caches *redis.Client) error {
 	sig := <-quit
 
 	log.Println("Shutting down server:", sig)
+	stopSweep()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 
 	defer cancel()
@@ -0,0 +1,83 @@
+package servers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"payuoge.com/configs"
+	"payuoge.com/internal/api/models/products"
+	"payuoge.com/internal/api/models/transactions"
+	"payuoge.com/pkg/storage"
+)
+
+// sweepOrders releases the stock of expired orders every SweepInterval until
+// ctx is done. Several instances may sweep at once, an order is only expired
+// by the one that locks it.
+func sweepOrders(ctx context.Context, db *sql.DB, config configs.OrderConfig) {
+	ticker := time.NewTicker(config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				expired, err := transactions.ExpireOrders(config.SweepBatch, db)
+				if err != nil {
+					log.Println("sweep orders:", err.Error())
+					break
+				}
+				if expired > 0 {
+					log.Printf("sweep orders: %d expired", expired)
+				}
+				// a full batch means more orders may be waiting
+				if expired < config.SweepBatch || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// purgeTrash removes the catalog rows deleted longer than Retention ago
+// every PurgeInterval until ctx is done, together with the image objects of
+// the removed products.
+func purgeTrash(ctx context.Context, db *sql.DB, store storage.ObjectStore, config configs.TrashConfig) {
+	ticker := time.NewTicker(config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				before := time.Now().Add(-config.Retention).Unix()
+				result, err := products.Purge(before, config.PurgeBatch, db)
+				if err != nil {
+					log.Println("purge trash:", err.Error())
+					break
+				}
+				for _, key := range result.Keys {
+					// a failure only leaves an orphan object behind
+					if err := store.Delete(ctx, key); err != nil {
+						log.Printf("purge trash: delete object %s: %v", key, err)
+					}
+				}
+				if purged := result.Products + result.Categories + result.SizeTypes; purged > 0 {
+					log.Printf("purge trash: %d products, %d categories, %d size types", result.Products, result.Categories, result.SizeTypes)
+				}
+				// a full batch means more rows may be waiting
+				full := result.Products == int64(config.PurgeBatch) ||
+					result.Categories == int64(config.PurgeBatch) ||
+					result.SizeTypes == int64(config.PurgeBatch)
+				if !full || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
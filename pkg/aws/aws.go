@@ -2,7 +2,9 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/sethvargo/go-envconfig"
@@ -11,8 +13,32 @@ import (
 	"payuoge.com/pkg/s3"
 )
 
+// groups created for the in-memory identity provider
+var memoryGroups = []string{"retail", "grosir", "admin"}
+
+// memoryAdmins turns the IDENTITY_ADMINS email:password pairs into confirmed
+// users of the admin group, no one can become admin in the in-memory provider
+// otherwise.
+func memoryAdmins(admins []string) ([]cognito.MemorySeed, error) {
+	seeds := make([]cognito.MemorySeed, 0, len(admins))
+	for i, admin := range admins {
+		email, password, ok := strings.Cut(admin, ":")
+		if !ok || email == "" || password == "" {
+			return nil, fmt.Errorf("IDENTITY_ADMINS entry %d is not email:password", i+1)
+		}
+
+		seeds = append(seeds, cognito.MemorySeed{
+			Email:    email,
+			Password: password,
+			Groups:   []string{"admin"},
+		})
+	}
+	return seeds, nil
+}
+
 type AwsConnect struct {
-	Cognito    *cognito.AwsCognito
+	Cognito    cognito.IdentityProvider
+	Verifier   *cognito.TokenVerifier
 	S3         *s3.AwsS3
 	GoogleAuth *cognito.GoogleAuthenticator
 }
@@ -36,9 +62,41 @@ func NewConnect() *AwsConnect {
 		return nil
 	}
 
-	return &AwsConnect{
-		Cognito:    cognito.NewCognitoClient(&cfg, configs.AwsConf.ClientId, configs.AwsConf.ClientSecret, configs.AwsConf.UserPoolId),
+	conn := &AwsConnect{
 		S3:         s3.NewS3Connect(&cfg),
-		GoogleAuth: cognito.NewGoogleAuthenticator(&cfg),
+		GoogleAuth: cognito.NewGoogleAuthenticator(configs.GoogleAuth, configs.AwsConf.ClientId, configs.AwsConf.ClientSecret),
 	}
+
+	if configs.Identity.Provider == "memory" {
+		issuer := "http://localhost/memory"
+		admins, err := memoryAdmins(configs.Identity.Admins)
+		if err != nil {
+			log.Println(err.Error())
+			return nil
+		}
+
+		provider, err := cognito.NewMemoryProvider(issuer, configs.AwsConf.ClientId, configs.Identity.TokenTTL, memoryGroups, admins...)
+		if err != nil {
+			log.Println(err.Error())
+			return nil
+		}
+
+		log.Println("using in-memory identity provider")
+		conn.Cognito = provider
+		conn.Verifier = cognito.NewTokenVerifier(issuer, configs.AwsConf.ClientId, cognito.NewStaticKeySource(provider.KeySet()), 0)
+
+		return conn
+	}
+
+	issuer := cognito.Issuer(configs.AwsConf.AwsRegion, configs.AwsConf.UserPoolId)
+
+	keySource := cognito.NewRemoteKeySource(issuer + "/.well-known/jwks.json")
+	if configs.AwsConf.JwksPath != "" {
+		keySource = cognito.NewFileKeySource(configs.AwsConf.JwksPath)
+	}
+
+	conn.Cognito = cognito.NewCognitoClient(&cfg, configs.AwsConf.ClientId, configs.AwsConf.ClientSecret, configs.AwsConf.UserPoolId)
+	conn.Verifier = cognito.NewTokenVerifier(issuer, configs.AwsConf.ClientId, keySource, configs.AwsConf.JwksRefresh)
+
+	return conn
 }
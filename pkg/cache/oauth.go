@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrStateNotFound = errors.New("oauth state not found")
+
+// OAuthStateStore keeps the PKCE verifier of a started hosted UI login under
+// its state, a state can be used only once.
+type OAuthStateStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewOAuthStateStore(client *redis.Client, ttl time.Duration) *OAuthStateStore {
+	return &OAuthStateStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func stateKey(state string) string {
+	return fmt.Sprintf("oauth:state:%s", state)
+}
+
+func (s *OAuthStateStore) Save(ctx context.Context, state, verifier string) error {
+	return s.client.Set(ctx, stateKey(state), verifier, s.ttl).Err()
+}
+
+// Take returns the verifier of state and removes it.
+func (s *OAuthStateStore) Take(ctx context.Context, state string) (string, error) {
+	verifier, err := s.client.GetDel(ctx, stateKey(state)).Result()
+	if err == redis.Nil {
+		return "", ErrStateNotFound
+	}
+	return verifier, err
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow keeps the requests of the last window in a sorted set scored
+// by time and adds the request only when it is under the limit. It returns
+// whether the request was allowed, the requests in the window and the
+// milliseconds until the oldest one leaves the window.
+var slidingWindow = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now - window)
+
+local count = redis.call("ZCARD", key)
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", key, window)
+
+local reset = window
+local oldest = redis.call("ZRANGE", key, 0, 0, "WITHSCORES")
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, count, reset}
+`)
+
+// RateLimit is the outcome of one request against a limit.
+type RateLimit struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// RateLimiter is a sliding window limiter stored in Redis. While Redis is
+// unavailable every instance falls back to its own in-process window, so the
+// effective limit is per instance until Redis is back.
+type RateLimiter struct {
+	client   *redis.Client
+	local    *localWindow
+	degraded atomic.Bool
+	retryAt  atomic.Int64
+}
+
+// redisRetry is how long the limiter stays in memory after a Redis error
+// before it tries Redis again, so requests do not wait on a dead connection.
+const redisRetry = 10 * time.Second
+
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{
+		client: client,
+		local:  newLocalWindow(),
+	}
+}
+
+func rateLimitKey(key string) string {
+	return fmt.Sprintf("ratelimit:%s", key)
+}
+
+// Allow counts a request of key against limit requests per window.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) RateLimit {
+	now := time.Now()
+
+	if l.degraded.Load() && now.UnixNano() < l.retryAt.Load() {
+		return l.local.allow(key, limit, window, now)
+	}
+
+	result, err := slidingWindow.Run(ctx, l.client, []string{rateLimitKey(key)},
+		now.UnixMilli(), window.Milliseconds(), limit, requestID(now)).Int64Slice()
+	if err != nil {
+		if !l.degraded.Swap(true) {
+			log.Printf("rate limiter falls back to memory: %v", err)
+		}
+		l.retryAt.Store(now.Add(redisRetry).UnixNano())
+		return l.local.allow(key, limit, window, now)
+	}
+
+	if l.degraded.Swap(false) {
+		log.Println("rate limiter uses redis again")
+	}
+
+	return newRateLimit(result[0] == 1, limit, int(result[1]), time.Duration(result[2])*time.Millisecond)
+}
+
+func newRateLimit(allowed bool, limit, count int, reset time.Duration) RateLimit {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return RateLimit{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     reset,
+	}
+}
+
+// requestID makes the sorted set member unique when requests share the same
+// millisecond.
+func requestID(now time.Time) string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", now.UnixNano())
+	}
+	return fmt.Sprintf("%d-%s", now.UnixNano(), hex.EncodeToString(b))
+}
+
+// localWindow is the in-process sliding window used when Redis fails.
+type localWindow struct {
+	mu        sync.Mutex
+	requests  map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newLocalWindow() *localWindow {
+	return &localWindow{
+		requests:  make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (w *localWindow) allow(key string, limit int, window time.Duration, now time.Time) RateLimit {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// drop keys that went quiet so the map does not grow without bound
+	if now.Sub(w.lastSweep) > window {
+		for k, requests := range w.requests {
+			if len(requests) == 0 || now.Sub(requests[len(requests)-1]) > window {
+				delete(w.requests, k)
+			}
+		}
+		w.lastSweep = now
+	}
+
+	requests := w.requests[key]
+	start := 0
+	for start < len(requests) && now.Sub(requests[start]) > window {
+		start++
+	}
+	requests = requests[start:]
+
+	allowed := len(requests) < limit
+	if allowed {
+		requests = append(requests, now)
+	}
+	w.requests[key] = requests
+
+	reset := window
+	if len(requests) > 0 {
+		reset = requests[0].Add(window).Sub(now)
+	}
+
+	return newRateLimit(allowed, limit, len(requests), reset)
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func revokedKey(jti string) string {
+	return fmt.Sprintf("revoked:jti:%s", jti)
+}
+
+// revokeToken queues the revocation of a token, the entry only lives as long
+// as the token itself would.
+func revokeToken(ctx context.Context, pipe redis.Pipeliner, jti string, expiry time.Time) {
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return
+	}
+	pipe.Set(ctx, revokedKey(jti), 1, ttl)
+}
+
+// RevokeToken adds the access token jti to the revocation list until expiry.
+func (s *SessionStore) RevokeToken(ctx context.Context, jti string, expiry time.Time) error {
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, revokedKey(jti), 1, ttl).Err()
+}
+
+// IsRevoked reports whether the access token jti was revoked.
+func (s *SessionStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedKey(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,284 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is one signed in device of a user. The access token currently
+// issued to the session is tracked by its jti.
+type Session struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Device      string    `json:"device"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	AccessJTI   string    `json:"access_jti"`
+	AccessExp   time.Time `json:"access_exp"`
+	RefreshHash string    `json:"refresh_hash"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SessionStore keeps the sessions of every user in Redis:
+//
+//	user:<username>:sessions   hash of session id to the session
+//	session:token:<jti>        session id of a live access token
+//	session:refresh:<sha256>   username and session id of a refresh token
+//	revoked:jti:<jti>          revoked access token, kept until it expires
+type SessionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+type refreshIndex struct {
+	Username  string `json:"username"`
+	SessionID string `json:"session_id"`
+}
+
+func NewSessionStore(client *redis.Client, ttl time.Duration) *SessionStore {
+	return &SessionStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func sessionsKey(username string) string {
+	return fmt.Sprintf("user:%s:sessions", username)
+}
+
+func tokenKey(jti string) string {
+	return fmt.Sprintf("session:token:%s", jti)
+}
+
+func refreshKey(refreshHash string) string {
+	return fmt.Sprintf("session:refresh:%s", refreshHash)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Create stores a new session for the access token jti and the refresh token
+// issued at login. The session ID is generated when empty.
+func (s *SessionStore) Create(ctx context.Context, session *Session, refreshToken, jti string, tokenExpiry time.Time) error {
+	if session.ID == "" {
+		id, err := newSessionID()
+		if err != nil {
+			return err
+		}
+		session.ID = id
+	}
+
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.ttl)
+	session.AccessJTI = jti
+	session.AccessExp = tokenExpiry
+	session.RefreshHash = ""
+	if refreshToken != "" {
+		session.RefreshHash = hashToken(refreshToken)
+	}
+
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, sessionsKey(session.Username), session.ID, value)
+	pipe.Expire(ctx, sessionsKey(session.Username), s.ttl)
+	pipe.Set(ctx, tokenKey(jti), session.ID, time.Until(tokenExpiry))
+	if refreshToken != "" {
+		index, err := json.Marshal(refreshIndex{Username: session.Username, SessionID: session.ID})
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, refreshKey(session.RefreshHash), index, s.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// Rotate moves a session to a new access token after a refresh. The previous
+// access token stops being accepted.
+func (s *SessionStore) Rotate(ctx context.Context, session *Session, jti string, tokenExpiry time.Time) error {
+	previous, previousExp := session.AccessJTI, session.AccessExp
+
+	session.AccessJTI = jti
+	session.AccessExp = tokenExpiry
+	session.LastUsedAt = time.Now()
+
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, sessionsKey(session.Username), session.ID, value)
+	pipe.Set(ctx, tokenKey(jti), session.ID, time.Until(tokenExpiry))
+	if previous != "" {
+		pipe.Del(ctx, tokenKey(previous))
+		revokeToken(ctx, pipe, previous, previousExp)
+	}
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// Get returns a session of username.
+func (s *SessionStore) Get(ctx context.Context, username, id string) (*Session, error) {
+	value, err := s.client.HGet(ctx, sessionsKey(username), id).Result()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(value), &session); err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		s.client.HDel(ctx, sessionsKey(username), id)
+		return nil, ErrSessionNotFound
+	}
+
+	return &session, nil
+}
+
+// FindByRefreshToken returns the session a refresh token was issued to.
+func (s *SessionStore) FindByRefreshToken(ctx context.Context, refreshToken string) (*Session, error) {
+	value, err := s.client.Get(ctx, refreshKey(hashToken(refreshToken))).Result()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var index refreshIndex
+	if err := json.Unmarshal([]byte(value), &index); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, index.Username, index.SessionID)
+}
+
+// Validate returns the session of username holding the access token jti, a
+// token of a revoked session is rejected even when it has not expired yet.
+func (s *SessionStore) Validate(ctx context.Context, username, jti string) (*Session, error) {
+	id, err := s.client.Get(ctx, tokenKey(jti)).Result()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.Get(ctx, username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.AccessJTI != jti {
+		return nil, ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+// List returns the live sessions of username, most recently used first.
+// Expired entries are removed on the way.
+func (s *SessionStore) List(ctx context.Context, username string) ([]Session, error) {
+	values, err := s.client.HGetAll(ctx, sessionsKey(username)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := make([]Session, 0, len(values))
+	for id, value := range values {
+		var session Session
+		if err := json.Unmarshal([]byte(value), &session); err != nil || now.After(session.ExpiresAt) {
+			s.client.HDel(ctx, sessionsKey(username), id)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// Revoke removes a session together with its access and refresh tokens.
+func (s *SessionStore) Revoke(ctx context.Context, username, id string) error {
+	session, err := s.Get(ctx, username, id)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HDel(ctx, sessionsKey(username), id)
+	deleteTokens(ctx, pipe, session)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// RevokeAll removes every session of username and revokes their access
+// tokens, it returns the number of sessions removed.
+func (s *SessionStore) RevokeAll(ctx context.Context, username string) (int, error) {
+	sessions, err := s.List(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, sessionsKey(username))
+	for i := range sessions {
+		deleteTokens(ctx, pipe, &sessions[i])
+	}
+	_, err = pipe.Exec(ctx)
+
+	return len(sessions), err
+}
+
+func deleteTokens(ctx context.Context, pipe redis.Pipeliner, session *Session) {
+	if session.AccessJTI != "" {
+		pipe.Del(ctx, tokenKey(session.AccessJTI))
+		revokeToken(ctx, pipe, session.AccessJTI, session.AccessExp)
+	}
+	if session.RefreshHash != "" {
+		pipe.Del(ctx, refreshKey(session.RefreshHash))
+	}
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"payuoge.com/configs"
+)
+
+// Throttle counts failed attempts of one action, such as "login", per email
+// and per client IP:
+//
+//	throttle:<action>:fail:<email|ip>   failures within the window
+//	throttle:<action>:wait:<email|ip>   backoff before the next attempt
+//	throttle:<action>:lock:<email|ip>   lockout after too many failures
+type Throttle struct {
+	client *redis.Client
+	action string
+	conf   configs.ThrottleConfig
+}
+
+// LockoutStatus is the throttle state of an email.
+type LockoutStatus struct {
+	Failures   int64
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func NewThrottle(client *redis.Client, action string, conf configs.ThrottleConfig) *Throttle {
+	return &Throttle{
+		client: client,
+		action: action,
+		conf:   conf,
+	}
+}
+
+func (t *Throttle) Action() string {
+	return t.action
+}
+
+func (t *Throttle) key(kind, subject string) string {
+	return fmt.Sprintf("throttle:%s:%s:%s", t.action, kind, subject)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check returns how long email and ip have to wait before the next attempt,
+// zero when the attempt is allowed.
+func (t *Throttle) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	email = normalizeEmail(email)
+
+	pipe := t.client.Pipeline()
+	ttls := []*redis.DurationCmd{
+		pipe.PTTL(ctx, t.key("lock", email)),
+		pipe.PTTL(ctx, t.key("wait", email)),
+		pipe.PTTL(ctx, t.key("lock", ip)),
+		pipe.PTTL(ctx, t.key("wait", ip)),
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var retryAfter time.Duration
+	for _, ttl := range ttls {
+		if ttl.Val() > retryAfter {
+			retryAfter = ttl.Val()
+		}
+	}
+
+	return retryAfter, nil
+}
+
+// Record counts a failed attempt and returns the wait it imposes.
+func (t *Throttle) Record(ctx context.Context, email, ip string) (time.Duration, error) {
+	email = normalizeEmail(email)
+
+	emailWait, err := t.record(ctx, email, t.conf.MaxFailures)
+	if err != nil {
+		return 0, err
+	}
+
+	ipWait, err := t.record(ctx, ip, t.conf.IPMaxFailures)
+	if err != nil {
+		return 0, err
+	}
+
+	if ipWait > emailWait {
+		return ipWait, nil
+	}
+	return emailWait, nil
+}
+
+func (t *Throttle) record(ctx context.Context, subject string, maxFailures int64) (time.Duration, error) {
+	failures, err := t.client.Incr(ctx, t.key("fail", subject)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// the window starts with the first failure
+	if failures == 1 {
+		if err := t.client.Expire(ctx, t.key("fail", subject), t.conf.FailureWindow).Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	if failures >= maxFailures {
+		return t.conf.Lockout, t.client.Set(ctx, t.key("lock", subject), failures, t.conf.Lockout).Err()
+	}
+
+	wait := t.backoff(failures)
+	return wait, t.client.Set(ctx, t.key("wait", subject), failures, wait).Err()
+}
+
+// backoff doubles the wait with every failure, up to BackoffMax.
+func (t *Throttle) backoff(failures int64) time.Duration {
+	wait := t.conf.BackoffBase
+	for i := int64(1); i < failures && wait < t.conf.BackoffMax; i++ {
+		wait *= 2
+	}
+	if wait > t.conf.BackoffMax {
+		wait = t.conf.BackoffMax
+	}
+	return wait
+}
+
+// Reset clears the failures of email after a successful attempt. The counter
+// of the IP is kept so one valid account can not hide a stuffing attack.
+func (t *Throttle) Reset(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	return t.client.Del(ctx, t.key("fail", email), t.key("wait", email)).Err()
+}
+
+// Status returns the throttle state of email.
+func (t *Throttle) Status(ctx context.Context, email string) (*LockoutStatus, error) {
+	email = normalizeEmail(email)
+
+	pipe := t.client.Pipeline()
+	failures := pipe.Get(ctx, t.key("fail", email))
+	lock := pipe.PTTL(ctx, t.key("lock", email))
+	wait := pipe.PTTL(ctx, t.key("wait", email))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	count, _ := failures.Int64()
+	status := &LockoutStatus{
+		Failures: count,
+		Locked:   lock.Val() > 0,
+	}
+	status.RetryAfter = lock.Val()
+	if wait.Val() > status.RetryAfter {
+		status.RetryAfter = wait.Val()
+	}
+	if status.RetryAfter < 0 {
+		status.RetryAfter = 0
+	}
+
+	return status, nil
+}
+
+// Unlock lifts the lockout and clears the failures of email.
+func (t *Throttle) Unlock(ctx context.Context, email string) error {
+	email = normalizeEmail(email)
+	return t.client.Del(ctx, t.key("fail", email), t.key("wait", email), t.key("lock", email)).Err()
+}
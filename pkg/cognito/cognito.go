@@ -6,13 +6,13 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"log"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
-	"github.com/gin-gonic/gin"
 )
 
 type AwsCognito struct {
@@ -22,6 +22,8 @@ type AwsCognito struct {
 	appPoolId       string
 }
 
+var _ IdentityProvider = (*AwsCognito)(nil)
+
 func NewCognitoClient(config *aws.Config, clientId, clientSecret, poolId string) *AwsCognito {
 	client := cognito.NewFromConfig(*config)
 
@@ -40,7 +42,7 @@ func computeSecretHash(clientSecret, username, clientId string) string {
 	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
 
-func (c *AwsCognito) SignUp(ctx *gin.Context, email, password string) (string, error) {
+func (c *AwsCognito) SignUp(ctx context.Context, email, password string) (string, error) {
 	input := &cognito.SignUpInput{
 		ClientId: aws.String(c.appClientId),
 		Username: aws.String(email),
@@ -54,7 +56,7 @@ func (c *AwsCognito) SignUp(ctx *gin.Context, email, password string) (string, e
 	user, err := c.cognitoClient.SignUp(ctx, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "UsernameExistsException") {
-			err = errors.New("akun dengan email yang ingin didaftarkan telah ada, jika lupa password silahkan klik lupa password")
+			err = ErrUsernameExists
 		}
 
 		if strings.Contains(err.Error(), "InvalidParameterException") {
@@ -66,7 +68,7 @@ func (c *AwsCognito) SignUp(ctx *gin.Context, email, password string) (string, e
 	return *user.CodeDeliveryDetails.Destination, nil
 }
 
-func (c *AwsCognito) ConfirmSignUp(email, code string) (*cognito.ConfirmSignUpOutput, error) {
+func (c *AwsCognito) ConfirmSignUp(ctx context.Context, email, code string) error {
 	input := &cognito.ConfirmSignUpInput{
 		ClientId:         aws.String(c.appClientId),
 		Username:         aws.String(email),
@@ -76,22 +78,22 @@ func (c *AwsCognito) ConfirmSignUp(email, code string) (*cognito.ConfirmSignUpOu
 	secretHash := computeSecretHash(c.appClientSecret, email, c.appClientId)
 	input.SecretHash = aws.String(secretHash)
 
-	result, err := c.cognitoClient.ConfirmSignUp(context.TODO(), input)
+	_, err := c.cognitoClient.ConfirmSignUp(ctx, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "ExpiredCodeException") {
-			err = errors.New("kode telah expire, silahkan request kode konfirmasi kembali")
+			err = ErrCodeExpired
 		}
 
 		if strings.Contains(err.Error(), "CodeMismatchException") {
-			err = errors.New("kode verifikasi gagal, silahkan cek kembali pesan di email anda")
+			err = ErrCodeMismatch
 		}
-		return nil, err
+		return err
 	}
 
-	return result, nil
+	return nil
 }
 
-func (c *AwsCognito) SignIn(ctx *gin.Context, email, password string) (*types.AuthenticationResultType, error) {
+func (c *AwsCognito) SignIn(ctx context.Context, email, password string) (*AuthResult, error) {
 	secretHash := computeSecretHash(c.appClientSecret, email, c.appClientId)
 
 	input := &cognito.InitiateAuthInput{
@@ -107,11 +109,11 @@ func (c *AwsCognito) SignIn(ctx *gin.Context, email, password string) (*types.Au
 	result, err := c.cognitoClient.InitiateAuth(ctx, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "NotAuthorizedException") {
-			err = errors.New("name pengguna atau password kurang tepat")
+			err = ErrNotAuthorized
 		}
 
 		if strings.Contains(err.Error(), "UserNotConfirmedException") {
-			err = errors.New("akun belum terkonfirmasi, silahkan cek kode konfirmasi didalam email")
+			err = ErrUserNotConfirmed
 		}
 
 		if strings.Contains(err.Error(), "InvalidParameterException") {
@@ -120,10 +122,45 @@ func (c *AwsCognito) SignIn(ctx *gin.Context, email, password string) (*types.Au
 		return nil, err
 	}
 
-	return result.AuthenticationResult, nil
+	return newAuthResult(result.AuthenticationResult), nil
+}
+
+// RefreshToken issues new access and id tokens with REFRESH_TOKEN_AUTH. The
+// secret hash is computed over the cognito username, not the email alias.
+func (c *AwsCognito) RefreshToken(ctx context.Context, username, refreshToken string) (*AuthResult, error) {
+	secretHash := computeSecretHash(c.appClientSecret, username, c.appClientId)
+
+	input := &cognito.InitiateAuthInput{
+		ClientId: aws.String(c.appClientId),
+		AuthFlow: types.AuthFlowTypeRefreshTokenAuth,
+		AuthParameters: map[string]string{
+			"REFRESH_TOKEN": refreshToken,
+			"SECRET_HASH":   secretHash,
+		},
+	}
+
+	result, err := c.cognitoClient.InitiateAuth(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		if strings.Contains(err.Error(), "NotAuthorizedException") {
+			err = ErrRefreshToken
+		}
+		return nil, err
+	}
+
+	return newAuthResult(result.AuthenticationResult), nil
+}
+
+func newAuthResult(result *types.AuthenticationResultType) *AuthResult {
+	return &AuthResult{
+		AccessToken:  aws.ToString(result.AccessToken),
+		IdToken:      aws.ToString(result.IdToken),
+		RefreshToken: aws.ToString(result.RefreshToken),
+		ExpiresIn:    result.ExpiresIn,
+	}
 }
 
-func (c *AwsCognito) SignOut(ctx *gin.Context, username string) error {
+func (c *AwsCognito) SignOut(ctx context.Context, username string) error {
 	input := &cognito.AdminUserGlobalSignOutInput{
 		UserPoolId: aws.String(c.appPoolId),
 		Username:   aws.String(username),
@@ -137,7 +174,7 @@ func (c *AwsCognito) SignOut(ctx *gin.Context, username string) error {
 	return nil
 }
 
-func (c *AwsCognito) ResendConfirmationCode(ctx *gin.Context, email string) (string, error) {
+func (c *AwsCognito) ResendConfirmationCode(ctx context.Context, email string) (string, error) {
 	input := &cognito.ResendConfirmationCodeInput{
 		ClientId: aws.String(c.appClientId),
 		Username: aws.String(email),
@@ -155,7 +192,7 @@ func (c *AwsCognito) ResendConfirmationCode(ctx *gin.Context, email string) (str
 	return *code.CodeDeliveryDetails.Destination, nil
 }
 
-func (c *AwsCognito) GetUsername(ctx *gin.Context, token string) (*cognito.GetUserOutput, error) {
+func (c *AwsCognito) GetUser(ctx context.Context, token string) (*User, error) {
 	input := &cognito.GetUserInput{
 		AccessToken: &token,
 	}
@@ -165,10 +202,10 @@ func (c *AwsCognito) GetUsername(ctx *gin.Context, token string) (*cognito.GetUs
 		return nil, err
 	}
 
-	return result, nil
+	return newUser(result.Username, result.UserAttributes, true, types.UserStatusTypeConfirmed), nil
 }
 
-func (c *AwsCognito) UpdateUserAttributes(ctx *gin.Context, email, attribute, value string) error {
+func (c *AwsCognito) UpdateUserAttributes(ctx context.Context, email, attribute, value string) error {
 	inputAttributes := []types.AttributeType{
 		{
 			Name:  aws.String(attribute),
@@ -187,7 +224,7 @@ func (c *AwsCognito) UpdateUserAttributes(ctx *gin.Context, email, attribute, va
 	return err
 }
 
-func (c *AwsCognito) ForgotPassword(ctx *gin.Context, email string) (string, error) {
+func (c *AwsCognito) ForgotPassword(ctx context.Context, email string) (string, error) {
 	input := &cognito.ForgotPasswordInput{
 		ClientId: aws.String(c.appClientId),
 		Username: aws.String(email),
@@ -205,7 +242,7 @@ func (c *AwsCognito) ForgotPassword(ctx *gin.Context, email string) (string, err
 	return *result.CodeDeliveryDetails.Destination, nil
 }
 
-func (c *AwsCognito) ResetPassword(ctx *gin.Context, email, password, code string) (*cognito.ConfirmForgotPasswordOutput, error) {
+func (c *AwsCognito) ResetPassword(ctx context.Context, email, password, code string) error {
 	input := &cognito.ConfirmForgotPasswordInput{
 		ClientId:         aws.String(c.appClientId),
 		Username:         aws.String(email),
@@ -216,10 +253,10 @@ func (c *AwsCognito) ResetPassword(ctx *gin.Context, email, password, code strin
 	secretHash := computeSecretHash(c.appClientSecret, email, c.appClientId)
 	input.SecretHash = aws.String(secretHash)
 
-	result, err := c.cognitoClient.ConfirmForgotPassword(ctx, input)
+	_, err := c.cognitoClient.ConfirmForgotPassword(ctx, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "CodeMismatchException") {
-			err = errors.New("kode verifikasi tidak cocok, silahkan request kode konfirmais kembali")
+			err = ErrResetCodeMismatch
 		}
 		if strings.Contains(err.Error(), "LimitExceededException") {
 			err = errors.New("maksimum pengulangan password hanya sampai 3x. Silahkan coba lagi untuk beberapa waktu")
@@ -230,13 +267,13 @@ func (c *AwsCognito) ResetPassword(ctx *gin.Context, email, password, code strin
 		if strings.Contains(err.Error(), "InvalidParameterException") {
 			err = errors.New("parameter tidak bisa dipakai, silahkan ubah paramternya")
 		}
-		return nil, err
+		return err
 	}
 
-	return result, err
+	return nil
 }
 
-func (c *AwsCognito) AddUserToGroup(ctx *gin.Context, username, groupName string) error {
+func (c *AwsCognito) AddUserToGroup(ctx context.Context, username, groupName string) error {
 	input := &cognito.AdminAddUserToGroupInput{
 		UserPoolId: aws.String(c.appPoolId),
 		Username:   aws.String(username),
@@ -246,7 +283,7 @@ func (c *AwsCognito) AddUserToGroup(ctx *gin.Context, username, groupName string
 	_, err := c.cognitoClient.AdminAddUserToGroup(ctx, input)
 	if err != nil {
 		if strings.Contains(err.Error(), "ResourceNotFoundException") {
-			err = errors.New("group tidak ada")
+			err = ErrGroupNotFound
 		}
 
 		//if strings.Contains(err.Error(), "UserNotFoundException") {
@@ -257,13 +294,13 @@ func (c *AwsCognito) AddUserToGroup(ctx *gin.Context, username, groupName string
 	return nil
 }
 
-func (c *AwsCognito) CheckUserInGroup(username string) ([]string, error) {
+func (c *AwsCognito) CheckUserInGroup(ctx context.Context, username string) ([]string, error) {
 	input := &cognito.AdminListGroupsForUserInput{
 		Username:   aws.String(username),
 		UserPoolId: aws.String(c.appPoolId),
 	}
 
-	resp, err := c.cognitoClient.AdminListGroupsForUser(context.TODO(), input)
+	resp, err := c.cognitoClient.AdminListGroupsForUser(ctx, input)
 	if err != nil {
 		log.Println(err.Error())
 		return nil, err
@@ -277,12 +314,12 @@ func (c *AwsCognito) CheckUserInGroup(username string) ([]string, error) {
 	return groupNames, nil
 }
 
-func (c *AwsCognito) ListGroup() ([]string, error) {
+func (c *AwsCognito) ListGroups(ctx context.Context) ([]string, error) {
 	input := &cognito.ListGroupsInput{
 		UserPoolId: aws.String(c.appPoolId),
 	}
 
-	resp, err := c.cognitoClient.ListGroups(context.TODO(), input)
+	resp, err := c.cognitoClient.ListGroups(ctx, input)
 	if err != nil {
 		log.Println(err.Error())
 		return nil, err
@@ -294,3 +331,115 @@ func (c *AwsCognito) ListGroup() ([]string, error) {
 	}
 	return groupNames, nil
 }
+
+func (c *AwsCognito) RemoveUserFromGroup(ctx context.Context, username, groupName string) error {
+	input := &cognito.AdminRemoveUserFromGroupInput{
+		UserPoolId: aws.String(c.appPoolId),
+		Username:   aws.String(username),
+		GroupName:  aws.String(groupName),
+	}
+
+	_, err := c.cognitoClient.AdminRemoveUserFromGroup(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		return userError(err)
+	}
+
+	return nil
+}
+
+func (c *AwsCognito) LookupUser(ctx context.Context, username string) (*User, error) {
+	input := &cognito.AdminGetUserInput{
+		UserPoolId: aws.String(c.appPoolId),
+		Username:   aws.String(username),
+	}
+
+	result, err := c.cognitoClient.AdminGetUser(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, userError(err)
+	}
+
+	return newUser(result.Username, result.UserAttributes, result.Enabled, result.UserStatus), nil
+}
+
+// ListUsers searches the user pool by email prefix, an empty prefix lists
+// every user.
+func (c *AwsCognito) ListUsers(ctx context.Context, emailPrefix string, limit int32) ([]User, error) {
+	input := &cognito.ListUsersInput{
+		UserPoolId: aws.String(c.appPoolId),
+		Limit:      aws.Int32(limit),
+	}
+
+	if emailPrefix != "" {
+		escaped := strings.ReplaceAll(emailPrefix, `"`, `\"`)
+		input.Filter = aws.String(fmt.Sprintf(`email ^= "%s"`, escaped))
+	}
+
+	result, err := c.cognitoClient.ListUsers(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, err
+	}
+
+	users := make([]User, len(result.Users))
+	for i, user := range result.Users {
+		users[i] = *newUser(user.Username, user.Attributes, user.Enabled, user.UserStatus)
+	}
+
+	return users, nil
+}
+
+func (c *AwsCognito) DisableUser(ctx context.Context, username string) error {
+	input := &cognito.AdminDisableUserInput{
+		UserPoolId: aws.String(c.appPoolId),
+		Username:   aws.String(username),
+	}
+
+	_, err := c.cognitoClient.AdminDisableUser(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		return userError(err)
+	}
+
+	return nil
+}
+
+func (c *AwsCognito) EnableUser(ctx context.Context, username string) error {
+	input := &cognito.AdminEnableUserInput{
+		UserPoolId: aws.String(c.appPoolId),
+		Username:   aws.String(username),
+	}
+
+	_, err := c.cognitoClient.AdminEnableUser(ctx, input)
+	if err != nil {
+		log.Println(err.Error())
+		return userError(err)
+	}
+
+	return nil
+}
+
+func newUser(username *string, attributes []types.AttributeType, enabled bool, status types.UserStatusType) *User {
+	user := &User{
+		Username:   aws.ToString(username),
+		Attributes: make(map[string]string, len(attributes)),
+		Enabled:    enabled,
+		Status:     string(status),
+	}
+	for _, attribute := range attributes {
+		user.Attributes[aws.ToString(attribute.Name)] = aws.ToString(attribute.Value)
+	}
+
+	return user
+}
+
+func userError(err error) error {
+	if strings.Contains(err.Error(), "UserNotFoundException") {
+		return ErrUserNotFound
+	}
+	if strings.Contains(err.Error(), "ResourceNotFoundException") {
+		return ErrGroupNotFound
+	}
+	return err
+}
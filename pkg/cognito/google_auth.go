@@ -3,98 +3,100 @@ package cognito
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
-	"log"
-	"net/http"
+	"errors"
+	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	cognito "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	"github.com/sethvargo/go-envconfig"
 	"golang.org/x/oauth2"
 	"payuoge.com/configs"
 )
 
+var ErrMissingToken = errors.New("token response without access or id token")
+
+// GoogleAuthenticator runs the authorization code flow of the Cognito hosted
+// UI with Google as identity provider. Every login uses a state and a PKCE
+// verifier, the caller keeps both until the callback.
 type GoogleAuthenticator struct {
-	CognitoClient    *cognito.Client
-	GoogleAuthConfig *oauth2.Config
+	Config           *oauth2.Config
+	identityProvider string
 }
 
-func NewGoogleAuthenticator(cfg *aws.Config) *GoogleAuthenticator {
-
-	var configs configs.AppConfiguration
-
-	if err := envconfig.Process(context.Background(), &configs); err != nil {
-		log.Fatal(err.Error())
-	}
-
-	cognitoClient := cognito.NewFromConfig(*cfg)
+// NewGoogleAuthenticator builds the OAuth client of the app client clientId
+// against the hosted UI domain, which can also be a local fake server.
+func NewGoogleAuthenticator(conf configs.GoogleAuthConfig, clientId, clientSecret string) *GoogleAuthenticator {
+	domain := strings.TrimRight(conf.Domain, "/")
 
-	googleOAuthConfig := &oauth2.Config{
-		ClientID:     configs.GoogleAuth.ClientID,
-		ClientSecret: configs.GoogleAuth.ClientSecret,
-		RedirectURL:  configs.GoogleAuth.RedirectURL,
-		Scopes:       []string{"openid", "email"},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  "https://accounts.google.com/o/oauth2/auth",
-			TokenURL: "https://accounts.google.com/o/oauth2/token",
+	return &GoogleAuthenticator{
+		Config: &oauth2.Config{
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+			RedirectURL:  conf.RedirectURL,
+			Scopes:       conf.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   domain + "/oauth2/authorize",
+				TokenURL:  domain + "/oauth2/token",
+				AuthStyle: oauth2.AuthStyleInHeader,
+			},
 		},
+		identityProvider: conf.IdentityProvider,
 	}
+}
 
-	return &GoogleAuthenticator{
-		CognitoClient:    cognitoClient,
-		GoogleAuthConfig: googleOAuthConfig,
+// NewPKCEVerifier returns a random code verifier as defined in RFC 7636.
+func NewPKCEVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-// func (ga *GoogleAuthenticator) CognitoExchange(accessToken, email string) (*cognito.InitiateAuthOutput, error) {
-// 	var configs configs.AppConfiguration
-//
-// 	if err := envconfig.Process(context.Background(), &configs); err != nil {
-// 		log.Fatal(err.Error())
-// 	}
-//
-// 	secretHash := computeSecretHash(configs.AwsConf.ClientSecret, email, configs.AwsConf.ClientId)
-// 	input := &cognito.credential{
-// 		ClientId: &configs.AwsConf.ClientId,
-// 		AuthFlow: types.AuthFlowTypeUserSrpAuth,
-// 		AuthParameters: map[string]string{
-// 			"USERNAME":    email,
-// 			"SRP_A":       accessToken,
-// 			"SECRET_HASH": secretHash,
-// 		},
-// 	}
-//
-// 	result, err := ga.CognitoClient.InitiateAuth(context.Background(), input)
-// 	if err != nil {
-// 		log.Println(err)
-// 	}
-//
-// 	return result, nil
-// }
+// NewState returns a random value for the OAuth state parameter.
+func NewState() (string, error) {
+	return NewPKCEVerifier()
+}
 
-func (ga *GoogleAuthenticator) GetUserInfoFromGoogle(accessToken string) (map[string]interface{}, error) {
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + accessToken)
-	if err != nil {
-		return nil, err
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the hosted UI URL the user is redirected to.
+func (ga *GoogleAuthenticator) AuthCodeURL(state, verifier string) string {
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", pkceChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+	if ga.identityProvider != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("identity_provider", ga.identityProvider))
 	}
-	defer resp.Body.Close()
 
-	var userInfo map[string]interface{}
+	return ga.Config.AuthCodeURL(state, opts...)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+// Exchange trades the authorization code for the user pool tokens.
+func (ga *GoogleAuthenticator) Exchange(ctx context.Context, code, verifier string) (*AuthResult, error) {
+	token, err := ga.Config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
 		return nil, err
 	}
 
-	return userInfo, nil
-}
+	idToken, _ := token.Extra("id_token").(string)
+	if token.AccessToken == "" || idToken == "" {
+		return nil, ErrMissingToken
+	}
 
-func (ga *GoogleAuthenticator) GenerateRandomKey(length int) (string, error) {
-	key := make([]byte, length)
-	_, err := rand.Read(key)
-	if err != nil {
-		return "", err
+	var expiresIn int32
+	if !token.Expiry.IsZero() {
+		expiresIn = int32(time.Until(token.Expiry).Seconds())
 	}
 
-	return base64.URLEncoding.EncodeToString(key), nil
+	return &AuthResult{
+		AccessToken:  token.AccessToken,
+		IdToken:      idToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
 }
@@ -0,0 +1,593 @@
+package cognito
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ IdentityProvider = (*MemoryProvider)(nil)
+
+type memoryUser struct {
+	username         string
+	sub              string
+	passwordHash     []byte
+	confirmed        bool
+	enabled          bool
+	attributes       map[string]string
+	groups           map[string]bool
+	confirmationCode string
+	resetCode        string
+}
+
+// MemoryProvider keeps users, confirmation codes, groups and issued tokens in
+// process. Tokens are real RS256 JWTs signed with a generated key, so they
+// pass the same TokenVerifier used for Cognito.
+type MemoryProvider struct {
+	mu       sync.Mutex
+	issuer   string
+	clientId string
+	tokenTTL time.Duration
+	kid      string
+	key      *rsa.PrivateKey
+	users    map[string]*memoryUser
+	groups   map[string]bool
+	tokens   map[string]string
+	refresh  map[string]string
+}
+
+// MemorySeed is a confirmed user created with the provider, for accounts that
+// cannot be reached by signing up, such as admins.
+type MemorySeed struct {
+	Email    string
+	Password string
+	Groups   []string
+}
+
+func NewMemoryProvider(issuer, clientId string, tokenTTL time.Duration, groups []string, seeds ...MemorySeed) (*MemoryProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &MemoryProvider{
+		issuer:   issuer,
+		clientId: clientId,
+		tokenTTL: tokenTTL,
+		kid:      randomHex(8),
+		key:      key,
+		users:    make(map[string]*memoryUser),
+		groups:   make(map[string]bool),
+		tokens:   make(map[string]string),
+		refresh:  make(map[string]string),
+	}
+	for _, group := range groups {
+		provider.groups[group] = true
+	}
+
+	for _, seed := range seeds {
+		if err := provider.seed(seed); err != nil {
+			return nil, err
+		}
+	}
+
+	return provider, nil
+}
+
+func (m *MemoryProvider) seed(seed MemorySeed) error {
+	if _, ok := m.users[seed.Email]; ok {
+		return ErrUsernameExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(seed.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user := &memoryUser{
+		username:     seed.Email,
+		sub:          randomHex(16),
+		passwordHash: hash,
+		confirmed:    true,
+		enabled:      true,
+		attributes:   map[string]string{"email": seed.Email},
+		groups:       make(map[string]bool),
+	}
+	for _, group := range seed.Groups {
+		if !m.groups[group] {
+			return ErrGroupNotFound
+		}
+		user.groups[group] = true
+	}
+	m.users[seed.Email] = user
+
+	return nil
+}
+
+// KeySet returns the public key set for NewStaticKeySource.
+func (m *MemoryProvider) KeySet() *JSONWebKeySet {
+	return &JSONWebKeySet{
+		Keys: []JSONWebKey{NewJSONWebKey(m.kid, &m.key.PublicKey)},
+	}
+}
+
+// ConfirmationCode returns the pending sign up code of a user, the in-memory
+// provider does not send emails.
+func (m *MemoryProvider) ConfirmationCode(email string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user, ok := m.users[email]; ok {
+		return user.confirmationCode
+	}
+	return ""
+}
+
+// ResetCode returns the pending forgot password code of a user.
+func (m *MemoryProvider) ResetCode(email string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if user, ok := m.users[email]; ok {
+		return user.resetCode
+	}
+	return ""
+}
+
+func (m *MemoryProvider) SignUp(ctx context.Context, email, password string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[email]; ok {
+		return "", ErrUsernameExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	user := &memoryUser{
+		username:         email,
+		sub:              randomHex(16),
+		passwordHash:     hash,
+		enabled:          true,
+		attributes:       map[string]string{"email": email},
+		groups:           make(map[string]bool),
+		confirmationCode: randomCode(),
+	}
+	m.users[email] = user
+
+	log.Printf("confirmation code for %s: %s", email, user.confirmationCode)
+
+	return email, nil
+}
+
+func (m *MemoryProvider) ConfirmSignUp(ctx context.Context, email, code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	if user.confirmationCode == "" || user.confirmationCode != code {
+		return ErrCodeMismatch
+	}
+
+	user.confirmed = true
+	user.confirmationCode = ""
+
+	return nil
+}
+
+func (m *MemoryProvider) SignIn(ctx context.Context, email, password string) (*AuthResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok || !user.enabled {
+		return nil, ErrNotAuthorized
+	}
+
+	if err := bcrypt.CompareHashAndPassword(user.passwordHash, []byte(password)); err != nil {
+		return nil, ErrNotAuthorized
+	}
+
+	if !user.confirmed {
+		return nil, ErrUserNotConfirmed
+	}
+
+	result, err := m.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	result.RefreshToken = randomHex(32)
+	m.refresh[result.RefreshToken] = user.username
+
+	return result, nil
+}
+
+func (m *MemoryProvider) RefreshToken(ctx context.Context, username, refreshToken string) (*AuthResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	owner, ok := m.refresh[refreshToken]
+	if !ok || owner != username {
+		return nil, ErrRefreshToken
+	}
+
+	user, ok := m.users[username]
+	if !ok || !user.enabled {
+		return nil, ErrRefreshToken
+	}
+
+	return m.issueTokens(user)
+}
+
+func (m *MemoryProvider) SignOut(ctx context.Context, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for token, owner := range m.tokens {
+		if owner == username {
+			delete(m.tokens, token)
+		}
+	}
+	for token, owner := range m.refresh {
+		if owner == username {
+			delete(m.refresh, token)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryProvider) ResendConfirmationCode(ctx context.Context, email string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok {
+		return "", ErrUserNotFound
+	}
+
+	user.confirmationCode = randomCode()
+	log.Printf("confirmation code for %s: %s", email, user.confirmationCode)
+
+	return email, nil
+}
+
+func (m *MemoryProvider) ForgotPassword(ctx context.Context, email string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok {
+		return "", ErrUserNotFound
+	}
+
+	user.resetCode = randomCode()
+	log.Printf("reset password code for %s: %s", email, user.resetCode)
+
+	return email, nil
+}
+
+func (m *MemoryProvider) ResetPassword(ctx context.Context, email, password, code string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	if user.resetCode == "" || user.resetCode != code {
+		return ErrResetCodeMismatch
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user.passwordHash = hash
+	user.resetCode = ""
+
+	return nil
+}
+
+func (m *MemoryProvider) GetUser(ctx context.Context, accessToken string) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	username, ok := m.tokens[accessToken]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	user, ok := m.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return user.toUser(), nil
+}
+
+func (m *MemoryProvider) UpdateUserAttributes(ctx context.Context, username, attribute, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	user.attributes[attribute] = value
+
+	return nil
+}
+
+func (m *MemoryProvider) AddUserToGroup(ctx context.Context, username, groupName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.groups[groupName] {
+		return ErrGroupNotFound
+	}
+
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	user.groups[groupName] = true
+
+	return nil
+}
+
+func (m *MemoryProvider) ListGroups(ctx context.Context) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	groupNames := make([]string, 0, len(m.groups))
+	for group := range m.groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+
+	return groupNames, nil
+}
+
+func (m *MemoryProvider) CheckUserInGroup(ctx context.Context, username string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return user.groupNames(), nil
+}
+
+func (m *MemoryProvider) RemoveUserFromGroup(ctx context.Context, username, groupName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.groups[groupName] {
+		return ErrGroupNotFound
+	}
+
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	delete(user.groups, groupName)
+
+	return nil
+}
+
+func (m *MemoryProvider) LookupUser(ctx context.Context, username string) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+
+	return user.toUser(), nil
+}
+
+func (m *MemoryProvider) ListUsers(ctx context.Context, emailPrefix string, limit int32) ([]User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usernames := make([]string, 0, len(m.users))
+	for username, user := range m.users {
+		if strings.HasPrefix(user.attributes["email"], emailPrefix) {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+
+	if limit > 0 && len(usernames) > int(limit) {
+		usernames = usernames[:limit]
+	}
+
+	users := make([]User, len(usernames))
+	for i, username := range usernames {
+		users[i] = *m.users[username].toUser()
+	}
+
+	return users, nil
+}
+
+// DisableUser blocks sign in and drops the refresh tokens of the user, as
+// cognito does.
+func (m *MemoryProvider) DisableUser(ctx context.Context, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	user.enabled = false
+	for token, owner := range m.refresh {
+		if owner == username {
+			delete(m.refresh, token)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryProvider) EnableUser(ctx context.Context, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	user.enabled = true
+
+	return nil
+}
+
+func (u *memoryUser) groupNames() []string {
+	groupNames := make([]string, 0, len(u.groups))
+	for group := range u.groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+
+	return groupNames
+}
+
+func (u *memoryUser) toUser() *User {
+	attributes := make(map[string]string, len(u.attributes)+1)
+	for name, value := range u.attributes {
+		attributes[name] = value
+	}
+	attributes["sub"] = u.sub
+
+	status := "UNCONFIRMED"
+	if u.confirmed {
+		status = "CONFIRMED"
+	}
+
+	return &User{
+		Username:   u.username,
+		Attributes: attributes,
+		Enabled:    u.enabled,
+		Status:     status,
+	}
+}
+
+// issueTokens must be called with m.mu held.
+func (m *MemoryProvider) issueTokens(user *memoryUser) (*AuthResult, error) {
+	now := time.Now()
+	expires := now.Add(m.tokenTTL)
+
+	accessToken, err := m.sign(map[string]interface{}{
+		"sub":            user.sub,
+		"iss":            m.issuer,
+		"client_id":      m.clientId,
+		"token_use":      "access",
+		"scope":          "aws.cognito.signin.user.admin",
+		"username":       user.username,
+		"cognito:groups": user.groupNames(),
+		"jti":            randomHex(16),
+		"auth_time":      now.Unix(),
+		"iat":            now.Unix(),
+		"exp":            expires.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := m.sign(map[string]interface{}{
+		"sub":              user.sub,
+		"iss":              m.issuer,
+		"aud":              m.clientId,
+		"token_use":        "id",
+		"cognito:username": user.username,
+		"cognito:groups":   user.groupNames(),
+		"email":            user.attributes["email"],
+		"jti":              randomHex(16),
+		"auth_time":        now.Unix(),
+		"iat":              now.Unix(),
+		"exp":              expires.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.tokens[accessToken] = user.username
+
+	return &AuthResult{
+		AccessToken: accessToken,
+		IdToken:     idToken,
+		ExpiresIn:   int32(m.tokenTTL.Seconds()),
+	}, nil
+}
+
+func (m *MemoryProvider) sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": m.kid, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func randomCode() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
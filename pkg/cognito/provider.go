@@ -0,0 +1,58 @@
+package cognito
+
+import (
+	"context"
+	"errors"
+)
+
+// errors shared by every identity provider, the messages are shown to users
+var (
+	ErrUsernameExists    = errors.New("akun dengan email yang ingin didaftarkan telah ada, jika lupa password silahkan klik lupa password")
+	ErrCodeExpired       = errors.New("kode telah expire, silahkan request kode konfirmasi kembali")
+	ErrCodeMismatch      = errors.New("kode verifikasi gagal, silahkan cek kembali pesan di email anda")
+	ErrNotAuthorized     = errors.New("name pengguna atau password kurang tepat")
+	ErrUserNotConfirmed  = errors.New("akun belum terkonfirmasi, silahkan cek kode konfirmasi didalam email")
+	ErrResetCodeMismatch = errors.New("kode verifikasi tidak cocok, silahkan request kode konfirmais kembali")
+	ErrGroupNotFound     = errors.New("group tidak ada")
+	ErrUserNotFound      = errors.New("nama pengguna belum terdaftar")
+	ErrRefreshToken      = errors.New("refresh token tidak valid, silahkan login kembali")
+)
+
+// AuthResult is the token set issued after a successful sign in. A refresh
+// does not rotate the refresh token, RefreshToken is empty in that case.
+type AuthResult struct {
+	AccessToken  string
+	IdToken      string
+	RefreshToken string
+	ExpiresIn    int32
+}
+
+type User struct {
+	Username   string            `json:"username"`
+	Attributes map[string]string `json:"attributes"`
+	Enabled    bool              `json:"enabled"`
+	Status     string            `json:"status"`
+}
+
+// IdentityProvider is implemented by AwsCognito and by MemoryProvider, which
+// runs the API without AWS.
+type IdentityProvider interface {
+	SignUp(ctx context.Context, email, password string) (string, error)
+	ConfirmSignUp(ctx context.Context, email, code string) error
+	SignIn(ctx context.Context, email, password string) (*AuthResult, error)
+	RefreshToken(ctx context.Context, username, refreshToken string) (*AuthResult, error)
+	SignOut(ctx context.Context, username string) error
+	ResendConfirmationCode(ctx context.Context, email string) (string, error)
+	ForgotPassword(ctx context.Context, email string) (string, error)
+	ResetPassword(ctx context.Context, email, password, code string) error
+	GetUser(ctx context.Context, accessToken string) (*User, error)
+	UpdateUserAttributes(ctx context.Context, username, attribute, value string) error
+	AddUserToGroup(ctx context.Context, username, groupName string) error
+	ListGroups(ctx context.Context) ([]string, error)
+	CheckUserInGroup(ctx context.Context, username string) ([]string, error)
+	RemoveUserFromGroup(ctx context.Context, username, groupName string) error
+	LookupUser(ctx context.Context, username string) (*User, error)
+	ListUsers(ctx context.Context, emailPrefix string, limit int32) ([]User, error)
+	DisableUser(ctx context.Context, username string) error
+	EnableUser(ctx context.Context, username string) error
+}
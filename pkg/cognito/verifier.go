@@ -0,0 +1,369 @@
+package cognito
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid token")
+	ErrTokenExpired  = errors.New("token expired")
+	ErrUnknownKey    = errors.New("token signed with unknown key")
+	ErrInvalidIssuer = errors.New("invalid token issuer")
+	ErrInvalidClient = errors.New("token issued for another client")
+	ErrInvalidUse    = errors.New("invalid token use")
+)
+
+// clockSkew is the leeway allowed when checking exp and iat.
+const clockSkew = 30 * time.Second
+
+// minKeyRefresh limits how often an unknown kid can force a JWKS refetch.
+const minKeyRefresh = time.Minute
+
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeySource returns the JSON Web Key Set used to verify token signatures.
+type KeySource interface {
+	Keys(ctx context.Context) (*JSONWebKeySet, error)
+}
+
+type remoteKeySource struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemoteKeySource fetches the key set from a JWKS endpoint such as the
+// user pool's /.well-known/jwks.json.
+func NewRemoteKeySource(url string) KeySource {
+	return &remoteKeySource{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (s *remoteKeySource) Keys(ctx context.Context) (*JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+type fileKeySource struct {
+	path string
+}
+
+// NewFileKeySource reads the key set from a local JWKS file, which lets the
+// verifier run without network access.
+func NewFileKeySource(path string) KeySource {
+	return &fileKeySource{path: path}
+}
+
+func (s *fileKeySource) Keys(ctx context.Context) (*JSONWebKeySet, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+type staticKeySource struct {
+	set *JSONWebKeySet
+}
+
+// NewStaticKeySource serves an in-process key set.
+func NewStaticKeySource(set *JSONWebKeySet) KeySource {
+	return &staticKeySource{set: set}
+}
+
+func (s *staticKeySource) Keys(ctx context.Context) (*JSONWebKeySet, error) {
+	return s.set, nil
+}
+
+// NewJSONWebKey encodes an RSA public key as a JWK usable for RS256.
+func NewJSONWebKey(kid string, key *rsa.PublicKey) JSONWebKey {
+	return JSONWebKey{
+		Kid: kid,
+		Kty: "RSA",
+		Alg: "RS256",
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (k JSONWebKey) publicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// Claims holds the Cognito claims the API relies on. Access tokens carry the
+// user in "username" and the app client in "client_id"; ID tokens use
+// "cognito:username" and "aud" instead.
+type Claims struct {
+	Subject         string   `json:"sub"`
+	Issuer          string   `json:"iss"`
+	ClientID        string   `json:"client_id"`
+	Audience        string   `json:"aud"`
+	TokenUse        string   `json:"token_use"`
+	Username        string   `json:"username"`
+	CognitoUsername string   `json:"cognito:username"`
+	Groups          []string `json:"cognito:groups"`
+	Email           string   `json:"email"`
+	Scope           string   `json:"scope"`
+	JTI             string   `json:"jti"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	AuthTime        int64    `json:"auth_time"`
+}
+
+// User returns the username regardless of the token type.
+func (c *Claims) User() string {
+	if c.Username != "" {
+		return c.Username
+	}
+	return c.CognitoUsername
+}
+
+// Expiry returns the expiration time of the token.
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+type TokenVerifier struct {
+	issuer   string
+	clientId string
+	source   KeySource
+	refresh  time.Duration
+
+	fetchMu   sync.Mutex
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// Issuer returns the issuer URL of a Cognito user pool.
+func Issuer(region, poolId string) string {
+	return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, poolId)
+}
+
+// NewTokenVerifier verifies RS256 tokens issued by issuer for clientId. The
+// key set is loaded lazily and refreshed once it is older than refresh.
+func NewTokenVerifier(issuer, clientId string, source KeySource, refresh time.Duration) *TokenVerifier {
+	return &TokenVerifier{
+		issuer:   issuer,
+		clientId: clientId,
+		source:   source,
+		refresh:  refresh,
+	}
+}
+
+func (v *TokenVerifier) loadKeys(ctx context.Context) error {
+	set, err := v.source.Keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("skip jwk %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+
+	return nil
+}
+
+func (v *TokenVerifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	fetchedAt := v.fetchedAt
+	loaded := v.keys != nil
+	v.mu.RUnlock()
+
+	age := time.Since(fetchedAt)
+	stale := !loaded || (v.refresh > 0 && age > v.refresh)
+	if ok && !stale {
+		return key, nil
+	}
+
+	// refetch when the cache is stale, or when the kid is unknown (keys
+	// were rotated) but not more often than minKeyRefresh.
+	if stale || age > minKeyRefresh {
+		v.fetchMu.Lock()
+		v.mu.RLock()
+		refreshed := v.fetchedAt.After(fetchedAt)
+		v.mu.RUnlock()
+
+		var err error
+		if !refreshed {
+			err = v.loadKeys(ctx)
+		}
+		v.fetchMu.Unlock()
+
+		if err != nil {
+			log.Println(err.Error())
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+
+		v.mu.RLock()
+		key, ok = v.keys[kid]
+		v.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Verify checks the signature, issuer, client, token use and expiry of a
+// token. tokenUse is either "access" or "id".
+func (v *TokenVerifier) Verify(ctx context.Context, token, tokenUse string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if header.Alg != "RS256" {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Issuer != v.issuer {
+		return nil, ErrInvalidIssuer
+	}
+
+	if claims.TokenUse != tokenUse {
+		return nil, ErrInvalidUse
+	}
+
+	client := claims.ClientID
+	if tokenUse == "id" {
+		client = claims.Audience
+	}
+	if client != v.clientId {
+		return nil, ErrInvalidClient
+	}
+
+	now := time.Now()
+	if now.After(claims.Expiry().Add(clockSkew)) {
+		return nil, ErrTokenExpired
+	}
+
+	if claims.IssuedAt > now.Add(clockSkew).Unix() {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,239 @@
+package cognito
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "http://localhost/test-pool"
+	testClientId = "test-client"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signToken returns an RS256 token of claims signed by key under kid.
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func accessClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"sub":       "user-sub",
+		"iss":       testIssuer,
+		"client_id": testClientId,
+		"token_use": "access",
+		"username":  "user",
+		"jti":       "jti-1",
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+	}
+}
+
+func idClaims() map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"sub":              "user-sub",
+		"iss":              testIssuer,
+		"aud":              testClientId,
+		"token_use":        "id",
+		"cognito:username": "user",
+		"email":            "user@example.com",
+		"iat":              now.Unix(),
+		"exp":              now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t)
+	other := newTestKey(t)
+	verifier := NewTokenVerifier(testIssuer, testClientId, NewStaticKeySource(&JSONWebKeySet{
+		Keys: []JSONWebKey{NewJSONWebKey("kid-1", &key.PublicKey)},
+	}), 0)
+
+	with := func(claims map[string]interface{}, field string, value interface{}) map[string]interface{} {
+		claims[field] = value
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		tokenUse string
+		err      error
+	}{
+		{"access token", signToken(t, key, "kid-1", accessClaims()), "access", nil},
+		{"id token", signToken(t, key, "kid-1", idClaims()), "id", nil},
+		{"signed by another key", signToken(t, other, "kid-1", accessClaims()), "access", ErrInvalidToken},
+		{"unknown kid", signToken(t, key, "kid-2", accessClaims()), "access", ErrUnknownKey},
+		{"malformed", "not-a-token", "access", ErrInvalidToken},
+		{"other issuer", signToken(t, key, "kid-1", with(accessClaims(), "iss", "http://localhost/other-pool")), "access", ErrInvalidIssuer},
+		{"access token of another client", signToken(t, key, "kid-1", with(accessClaims(), "client_id", "other-client")), "access", ErrInvalidClient},
+		{"id token for another audience", signToken(t, key, "kid-1", with(idClaims(), "aud", "other-client")), "id", ErrInvalidClient},
+		{"id token used as access token", signToken(t, key, "kid-1", idClaims()), "access", ErrInvalidUse},
+		{"access token used as id token", signToken(t, key, "kid-1", accessClaims()), "id", ErrInvalidUse},
+		{"expired", signToken(t, key, "kid-1", with(accessClaims(), "exp", time.Now().Add(-time.Hour).Unix())), "access", ErrTokenExpired},
+		{"expired within clock skew", signToken(t, key, "kid-1", with(accessClaims(), "exp", time.Now().Add(-clockSkew/2).Unix())), "access", nil},
+		{"issued in the future", signToken(t, key, "kid-1", with(accessClaims(), "iat", time.Now().Add(time.Hour).Unix())), "access", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token, tt.tokenUse)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.err)
+			}
+			if err == nil && claims.User() != "user" {
+				t.Fatalf("Verify() user = %q, want %q", claims.User(), "user")
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedPayload(t *testing.T) {
+	key := newTestKey(t)
+	verifier := NewTokenVerifier(testIssuer, testClientId, NewStaticKeySource(&JSONWebKeySet{
+		Keys: []JSONWebKey{NewJSONWebKey("kid-1", &key.PublicKey)},
+	}), 0)
+
+	token := signToken(t, key, "kid-1", accessClaims())
+	parts := strings.Split(token, ".")
+
+	forged := accessClaims()
+	forged["username"] = "admin"
+	payload, err := json.Marshal(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	if _, err := verifier.Verify(context.Background(), strings.Join(parts, "."), "access"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+// countingKeySource counts how often the verifier fetches the key set.
+type countingKeySource struct {
+	KeySource
+	fetches int
+}
+
+func (s *countingKeySource) Keys(ctx context.Context) (*JSONWebKeySet, error) {
+	s.fetches++
+	return s.KeySource.Keys(ctx)
+}
+
+func TestVerifyKeyRotation(t *testing.T) {
+	oldKey := newTestKey(t)
+	newKey := newTestKey(t)
+
+	set := &JSONWebKeySet{Keys: []JSONWebKey{NewJSONWebKey("kid-old", &oldKey.PublicKey)}}
+	source := &countingKeySource{KeySource: NewStaticKeySource(set)}
+	verifier := NewTokenVerifier(testIssuer, testClientId, source, 0)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, signToken(t, oldKey, "kid-old", accessClaims()), "access"); err != nil {
+		t.Fatalf("Verify() old key error = %v", err)
+	}
+
+	// the pool rotates its keys, the old one stays published for a while
+	set.Keys = append(set.Keys, NewJSONWebKey("kid-new", &newKey.PublicKey))
+	rotated := signToken(t, newKey, "kid-new", accessClaims())
+
+	// an unknown kid right after a fetch does not refetch the key set
+	if _, err := verifier.Verify(ctx, rotated, "access"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrUnknownKey)
+	}
+	if source.fetches != 1 {
+		t.Fatalf("fetches = %d, want 1", source.fetches)
+	}
+
+	// once minKeyRefresh passed the unknown kid refetches the key set
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-2 * minKeyRefresh)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(ctx, rotated, "access"); err != nil {
+		t.Fatalf("Verify() new key error = %v", err)
+	}
+	if source.fetches != 2 {
+		t.Fatalf("fetches = %d, want 2", source.fetches)
+	}
+	if _, err := verifier.Verify(ctx, signToken(t, oldKey, "kid-old", accessClaims()), "access"); err != nil {
+		t.Fatalf("Verify() old key after rotation error = %v", err)
+	}
+}
+
+func TestVerifyRefreshesStaleKeys(t *testing.T) {
+	key := newTestKey(t)
+	set := &JSONWebKeySet{Keys: []JSONWebKey{NewJSONWebKey("kid-1", &key.PublicKey)}}
+	source := &countingKeySource{KeySource: NewStaticKeySource(set)}
+	verifier := NewTokenVerifier(testIssuer, testClientId, source, time.Minute)
+	token := signToken(t, key, "kid-1", accessClaims())
+
+	for i := 0; i < 2; i++ {
+		if _, err := verifier.Verify(context.Background(), token, "access"); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+	if source.fetches != 1 {
+		t.Fatalf("fetches = %d, want 1 while the keys are fresh", source.fetches)
+	}
+
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-2 * time.Minute)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), token, "access"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if source.fetches != 2 {
+		t.Fatalf("fetches = %d, want 2 once the keys are stale", source.fetches)
+	}
+
+	// a withdrawn key is dropped by the next refresh
+	set.Keys = nil
+	verifier.mu.Lock()
+	verifier.fetchedAt = time.Now().Add(-2 * time.Minute)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), token, "access"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Verify() withdrawn key error = %v, want %v", err, ErrUnknownKey)
+	}
+}
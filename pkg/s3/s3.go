@@ -1,9 +1,12 @@
 package s3
 
 import (
+	"context"
 	"errors"
+	"io"
 	"log"
 	"mime/multipart"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -46,7 +49,7 @@ func (c *AwsS3) UploadFile(ctx *gin.Context, bucketName, fileName string, file m
 	return res.Location, err
 }
 
-func (c *AwsS3) CheckExists(ctx *gin.Context, bucketName, fileName string) (bool, error) {
+func (c *AwsS3) CheckExists(ctx context.Context, bucketName, fileName string) (bool, error) {
 	_, err := c.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(fileName),
@@ -115,7 +118,82 @@ func (c *AwsS3) BucketExists(ctx *gin.Context, bucketName string) (bool, error)
 	return exists, err
 }
 
-func (c *AwsS3) DeleteObject(ctx *gin.Context, bucketName, fileName string) error {
+// PutObject uploads body under key and returns the location of the object.
+func (c *AwsS3) PutObject(ctx context.Context, bucketName, key, contentType string, body io.Reader) (string, error) {
+	uploader := manager.NewUploader(c.client)
+
+	res, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucketName),
+		Body:        body,
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	return res.Location, nil
+}
+
+// PresignPut returns a URL valid for ttl to upload key with PUT. The content
+// type and length are signed, the upload must send the same headers. A
+// private object can only be read with PresignGet.
+func (c *AwsS3) PresignPut(ctx context.Context, bucketName, key, contentType string, size int64, private bool, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.client)
+
+	acl := types.ObjectCannedACLPublicRead
+	if private {
+		acl = types.ObjectCannedACLPrivate
+	}
+
+	req, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: size,
+		ACL:           acl,
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	return req.URL, nil
+}
+
+// PresignGet returns a URL valid for ttl to download key with GET.
+func (c *AwsS3) PresignGet(ctx context.Context, bucketName, key string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.client)
+
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		log.Println(err)
+		return "", err
+	}
+
+	return req.URL, nil
+}
+
+// GetObject returns the content of key, the caller closes it.
+func (c *AwsS3) GetObject(ctx context.Context, bucketName, key string) (io.ReadCloser, error) {
+	res, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+func (c *AwsS3) DeleteObject(ctx context.Context, bucketName, fileName string) error {
 	_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(fileName),
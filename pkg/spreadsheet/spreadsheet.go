@@ -0,0 +1,111 @@
+// Package spreadsheet reads and writes the rows of a CSV file or of the first
+// sheet of an XLSX workbook as strings.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// formats
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+var (
+	ErrFormat = errors.New("format file harus csv atau xlsx")
+	ErrEmpty  = errors.New("file tidak memiliki baris")
+)
+
+// ContentTypes are the content types of the formats, for downloads.
+var ContentTypes = map[string]string{
+	CSV:  "text/csv",
+	XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Format returns the format of a file from its name.
+func Format(filename string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(path.Ext(filename), ".")) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	}
+	return "", ErrFormat
+}
+
+// Read returns the rows of data in format, trailing empty cells are kept so
+// every row is as wide as the widest one.
+func Read(data []byte, format string) ([][]string, error) {
+	var rows [][]string
+	var err error
+	switch format {
+	case CSV:
+		rows, err = readCSV(data)
+	case XLSX:
+		rows, err = readXLSX(data)
+	default:
+		return nil, ErrFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmpty
+	}
+	return pad(rows), nil
+}
+
+// Write writes rows to w in format.
+func Write(w io.Writer, format string, rows [][]string) error {
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case XLSX:
+		return writeXLSX(w, rows)
+	}
+	return ErrFormat
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	// spreadsheets often save csv with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// exports of some locales separate with semicolons
+	if line, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+func pad(rows [][]string) [][]string {
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		rows[i] = row
+	}
+	return rows
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// maxPixels guards against small files decoding into huge images.
+const maxPixels = 40_000_000
+
+var (
+	ErrImageType       = errors.New("gambar harus berformat jpeg, png atau gif")
+	ErrImageDimensions = errors.New("ukuran gambar terlalu besar")
+)
+
+// imageTypes are the accepted content types and their file extension.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// ImageExt returns the file extension of an accepted image content type.
+func ImageExt(contentType string) (string, bool) {
+	ext, ok := imageTypes[contentType]
+	return ext, ok
+}
+
+// Image is a checked upload and its thumbnail. The thumbnail of a jpeg is a
+// jpeg, others become png to keep transparency.
+type Image struct {
+	Data          []byte
+	ContentType   string
+	Ext           string
+	Width         int
+	Height        int
+	Thumbnail     []byte
+	ThumbnailType string
+	ThumbnailExt  string
+}
+
+// ProcessImage checks data by its content, not by the name or the header
+// sent by the client, and scales it down to thumbWidth.
+func ProcessImage(data []byte, thumbWidth int) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := imageTypes[contentType]
+	if !ok {
+		return nil, ErrImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrImageDimensions
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+
+	img := &Image{
+		Data:        data,
+		ContentType: contentType,
+		Ext:         ext,
+		Width:       config.Width,
+		Height:      config.Height,
+	}
+
+	thumb := scaleDown(src, thumbWidth)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+		img.ThumbnailType, img.ThumbnailExt = "image/jpeg", ".jpg"
+	} else {
+		err = png.Encode(&buf, thumb)
+		img.ThumbnailType, img.ThumbnailExt = "image/png", ".png"
+	}
+	if err != nil {
+		return nil, err
+	}
+	img.Thumbnail = buf.Bytes()
+
+	return img, nil
+}
+
+// scaleDown resizes src to width keeping the aspect ratio, each pixel is the
+// average of the source pixels it covers. Smaller images are kept as is.
+func scaleDown(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if width <= 0 || sw <= width {
+		return src
+	}
+
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, (y+1)*sh/height
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, (x+1)*sw/width
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+
+	return dst
+}
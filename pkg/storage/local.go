@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey   = errors.New("invalid object key")
+	ErrInvalidToken = errors.New("invalid or expired storage token")
+)
+
+// LocalStore keeps objects as files under a directory, for development and
+// tests. The directory but its private keys is served by the API at
+// publicURL, presigned uploads and downloads go to uploadURL with a token
+// signed by signingKey.
+type LocalStore struct {
+	dir        string
+	publicURL  string
+	uploadURL  string
+	signingKey []byte
+}
+
+// UploadToken is what a signed upload or download token allows, Method
+// keeps a download token from being used to upload.
+type UploadToken struct {
+	Method      string `json:"m"`
+	Key         string `json:"k"`
+	ContentType string `json:"t,omitempty"`
+	Size        int64  `json:"s,omitempty"`
+	Expires     int64  `json:"e"`
+}
+
+func NewLocalStore(dir, publicURL, uploadURL, signingKey string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if publicURL == "" {
+		publicURL = "/media"
+	}
+
+	key := []byte(signingKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &LocalStore{
+		dir:        dir,
+		publicURL:  publicURL,
+		uploadURL:  uploadURL,
+		signingKey: key,
+	}, nil
+}
+
+// PublicFS is the directory to serve at the public URL, private keys and
+// directory listings are left out.
+func (s *LocalStore) PublicFS() http.FileSystem {
+	return publicDir{http.Dir(s.dir)}
+}
+
+type publicDir struct {
+	http.FileSystem
+}
+
+func (d publicDir) Open(name string) (http.File, error) {
+	if IsPrivate(path.Clean("/" + name)) {
+		return nil, os.ErrNotExist
+	}
+
+	file, err := d.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil, os.ErrNotExist
+	}
+	return file, nil
+}
+
+// path keeps keys inside the directory.
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, body io.Reader) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
+
+func (s *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// PresignPut returns the upload URL of the API with a signed token, the
+// counterpart of a presigned S3 URL.
+func (s *LocalStore) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	return s.presign(UploadToken{
+		Method:      http.MethodPut,
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		Expires:     time.Now().Add(ttl).Unix(),
+	})
+}
+
+// PresignGet returns the download URL of the API with a signed token, the
+// counterpart of a presigned S3 URL.
+func (s *LocalStore) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+
+	return s.presign(UploadToken{
+		Method:  http.MethodGet,
+		Key:     key,
+		Expires: time.Now().Add(ttl).Unix(),
+	})
+}
+
+func (s *LocalStore) presign(token UploadToken) (string, error) {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(value)
+	return joinURL(s.uploadURL, payload+"."+s.sign(payload)), nil
+}
+
+// Verify returns what a token signed by PresignPut or PresignGet allows,
+// when it was signed for method.
+func (s *LocalStore) Verify(token, method string) (*UploadToken, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil, ErrInvalidToken
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var upload UploadToken
+	if err := json.Unmarshal(value, &upload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if upload.Method != method || time.Now().Unix() > upload.Expires {
+		return nil, ErrInvalidToken
+	}
+
+	return &upload, nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"payuoge.com/pkg/s3"
+)
+
+// S3Store keeps objects in a bucket, public read unless their key is private.
+type S3Store struct {
+	client    *s3.AwsS3
+	bucket    string
+	publicURL string
+}
+
+// NewS3Store serves objects from publicURL, the virtual hosted bucket URL
+// when empty.
+func NewS3Store(client *s3.AwsS3, bucket, publicURL string) *S3Store {
+	if publicURL == "" {
+		publicURL = fmt.Sprintf("https://%s.s3.amazonaws.com", bucket)
+	}
+
+	return &S3Store{
+		client:    client,
+		bucket:    bucket,
+		publicURL: publicURL,
+	}
+}
+
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body io.Reader) (string, error) {
+	if _, err := s.client.PutObject(ctx, s.bucket, key, contentType, body); err != nil {
+		return "", err
+	}
+	return s.URL(key), nil
+}
+
+func (s *S3Store) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, error) {
+	return s.client.PresignPut(ctx, s.bucket, key, contentType, size, IsPrivate(key), ttl)
+}
+
+func (s *S3Store) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.client.PresignGet(ctx, s.bucket, key, ttl)
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, key)
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	return s.client.CheckExists(ctx, s.bucket, key)
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.DeleteObject(ctx, s.bucket, key)
+}
+
+func (s *S3Store) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"payuoge.com/configs"
+	"payuoge.com/pkg/s3"
+)
+
+// PrivatePrefix starts the keys of objects that are not served from the
+// public URL, such as payment proofs, they are read with PresignGet only.
+const PrivatePrefix = "private/"
+
+var ErrUnknownDriver = errors.New("unknown storage driver")
+
+// ObjectStore keeps uploaded files under a key, such as
+// products/12/3f2a.jpg, and serves them from a public URL.
+//
+// PresignPut lets a client upload an object of contentType and size bytes
+// straight to the store with a PUT to the returned URL until ttl passes, Get
+// reads it back to check it. PresignGet lets a client download an object,
+// private ones included, with a GET to the returned URL until ttl passes.
+type ObjectStore interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader) (string, error)
+	PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, error)
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// New returns the store selected by conf.Driver, the S3 store uses client.
+func New(conf configs.StorageConfig, client *s3.AwsS3) (ObjectStore, error) {
+	switch conf.Driver {
+	case "s3":
+		return NewS3Store(client, conf.Bucket, conf.PublicURL), nil
+	case "local":
+		return NewLocalStore(conf.LocalDir, conf.PublicURL, conf.LocalUploadURL, conf.SigningKey)
+	}
+	return nil, ErrUnknownDriver
+}
+
+// IsPrivate reports whether key, or a directory of keys, is under
+// PrivatePrefix.
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(strings.TrimLeft(key, "/")+"/", PrivatePrefix)
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
+
+// NewName returns a random object name, so keys can not be guessed and a new
+// upload never replaces a cached object.
+func NewName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}